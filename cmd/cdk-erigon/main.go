@@ -81,8 +81,13 @@ func runErigon(cliCtx *cli.Context) error {
 	err = ethNode.Serve()
 	if err != nil {
 		log.Error("error while serving an Erigon node", "err", err)
+		return err
+	}
+
+	if ethNode.Backend().PromotedToSequencer() {
+		return restartAsSequencer()
 	}
-	return err
+	return nil
 }
 
 func setFlagsFromConfigFile(ctx *cli.Context, filePath string) error {
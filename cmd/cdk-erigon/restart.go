@@ -0,0 +1,31 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/ledgerwatch/erigon/zk/sequencer"
+	"github.com/ledgerwatch/log/v3"
+)
+
+// restartAsSequencer replaces the stopped standby with the same command run as the sequencer
+func restartAsSequencer() error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	log.Info("Restarting as the sequencer")
+	env := make([]string, 0, len(os.Environ())+1)
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, sequencer.SEQUENCER_ENV_KEY+"=") {
+			env = append(env, kv)
+		}
+	}
+	env = append(env, sequencer.SEQUENCER_ENV_KEY+"=1")
+
+	return syscall.Exec(executable, os.Args, env)
+}
@@ -0,0 +1,7 @@
+package main
+
+import "errors"
+
+func restartAsSequencer() error {
+	return errors.New("the standby has to be restarted as the sequencer by hand on windows")
+}
@@ -98,6 +98,17 @@ type HttpCfg struct {
 	DataStreamWriteTimeout            time.Duration
 	DataStreamInactivityTimeout       time.Duration
 	DataStreamInactivityCheckInterval time.Duration
+	DataStreamTLSCert                 string
+	DataStreamTLSKey                  string
+	DataStreamTLSClientCA             string
+	DataStreamToken                   string
+	DataStreamInternalPort            uint
+	DataStreamBridgePort              uint
+	DataStreamSinkNDJSONDir           string
+	DataStreamSinkNDJSONMaxFileMB     uint
+	DataStreamSinkNDJSONMaxFiles      uint
+	DataStreamSinkKafkaBrokers        string
+	DataStreamSinkKafkaTopic          string
 	L2RpcUrl                          string
 
 	// For X Layer
@@ -412,7 +412,7 @@ var (
 	}
 	L2DataStreamerUrlFlag = cli.StringFlag{
 		Name:  "zkevm.l2-datastreamer-url",
-		Usage: "L2 datastreamer endpoint",
+		Usage: "L2 datastreamer endpoint, or a comma separated list of them to fail over between",
 		Value: "",
 	}
 	L2DataStreamerTimeout = cli.StringFlag{
@@ -420,6 +420,36 @@ var (
 		Usage: "The time to wait for data to arrive from the stream before reporting an error (0s doesn't check)",
 		Value: "3s",
 	}
+	L2DataStreamerDecodeWorkers = cli.IntFlag{
+		Name:  "zkevm.l2-datastreamer-decode-workers",
+		Usage: "Number of workers decoding the transactions read from the L2 datastreamer ahead of processing them, 0 decodes them while processing",
+		Value: 4,
+	}
+	L2DataStreamerTLS = cli.BoolFlag{
+		Name:  "zkevm.l2-datastreamer-tls",
+		Usage: "Connect to the L2 datastreamer over TLS",
+		Value: false,
+	}
+	L2DataStreamerTLSCA = cli.StringFlag{
+		Name:  "zkevm.l2-datastreamer-tls-ca",
+		Usage: "PEM file of the CA the L2 datastreamer certificate is verified against, the system roots if not set",
+		Value: "",
+	}
+	L2DataStreamerTLSCert = cli.StringFlag{
+		Name:  "zkevm.l2-datastreamer-tls-cert",
+		Usage: "PEM file of the client certificate presented to an L2 datastreamer requiring mutual TLS",
+		Value: "",
+	}
+	L2DataStreamerTLSKey = cli.StringFlag{
+		Name:  "zkevm.l2-datastreamer-tls-key",
+		Usage: "PEM file of the key of the client certificate",
+		Value: "",
+	}
+	L2DataStreamerToken = cli.StringFlag{
+		Name:  "zkevm.l2-datastreamer-token",
+		Usage: "Pre-shared token to authenticate with the L2 datastreamer",
+		Value: "",
+	}
 	L2ShortCircuitToVerifiedBatchFlag = cli.BoolFlag{
 		Name:  "zkevm.l2-short-circuit-to-verified-batch",
 		Usage: "Short circuit block execution up to the batch after the latest verified batch (default: true). When disabled, the sequencer will execute all downloaded batches",
@@ -455,6 +485,16 @@ var (
 		Usage: "The port used for the L1 cache",
 		Value: 6969,
 	}
+	L1CacheModeFlag = cli.StringFlag{
+		Name:  "zkevm.l1-cache-mode",
+		Usage: "The mode of the L1 cache. cache, record to export every L1 response of the run to zkevm.l1-cache-fixture on shutdown, or replay to serve only the responses in zkevm.l1-cache-fixture without reaching the L1",
+		Value: "cache",
+	}
+	L1CacheFixtureFlag = cli.StringFlag{
+		Name:  "zkevm.l1-cache-fixture",
+		Usage: "The fixture file the L1 cache records to or replays from",
+		Value: "",
+	}
 	AddressSequencerFlag = cli.StringFlag{
 		Name:  "zkevm.address-sequencer",
 		Usage: "Sequencer address",
@@ -501,6 +541,16 @@ var (
 		Usage: "The type of the highest block in the L1 chain. latest, safe, or finalized",
 		Value: "finalized",
 	}
+	L1QuorumFlag = cli.IntFlag{
+		Name:  "zkevm.l1-quorum",
+		Usage: "Number of the L1 RPC providers that have to agree on the sequence and verification logs, headers and acc input hashes read from the L1 before they are accepted. 0 or 1 trusts whichever provider answers",
+		Value: 0,
+	}
+	L1QuorumProvidersFlag = cli.IntFlag{
+		Name:  "zkevm.l1-quorum-providers",
+		Usage: "Number of the L1 RPC providers the reads checked by zkevm.l1-quorum are sent to at once, rotating through them. 0 sends them to all of them",
+		Value: 0,
+	}
 	L1MaticContractAddressFlag = cli.StringFlag{
 		Name:  "zkevm.l1-matic-contract-address",
 		Usage: "Ethereum L1 Matic contract address",
@@ -546,6 +596,51 @@ var (
 		Usage: "Block seal time. Defaults to 6s",
 		Value: "6s",
 	}
+	SequencerAdaptiveSealTime = cli.BoolFlag{
+		Name:  "zkevm.sequencer-adaptive-seal-time",
+		Usage: "When enabled, the block seal time moves between zkevm.sequencer-block-seal-time-min and zkevm.sequencer-block-seal-time-max, the more executable transactions are pending in the pool the shorter",
+		Value: false,
+	}
+	SequencerBlockSealTimeMin = cli.DurationFlag{
+		Name:  "zkevm.sequencer-block-seal-time-min",
+		Usage: "Shortest block seal time in adaptive mode, used once zkevm.sequencer-adaptive-seal-pending-target transactions are pending",
+		Value: time.Second,
+	}
+	SequencerBlockSealTimeMax = cli.DurationFlag{
+		Name:  "zkevm.sequencer-block-seal-time-max",
+		Usage: "Longest block seal time in adaptive mode, used while the pool has no pending transactions",
+		Value: 30 * time.Second,
+	}
+	SequencerAdaptiveSealPendingTarget = cli.Uint64Flag{
+		Name:  "zkevm.sequencer-adaptive-seal-pending-target",
+		Usage: "Number of pending transactions in the pool from which blocks are sealed at the shortest block seal time in adaptive mode",
+		Value: 1000,
+	}
+	SequencerPriorityReserve = cli.Float64Flag{
+		Name:  "zkevm.sequencer-priority-reserve",
+		Usage: "Share of the zk counters and gas of each batch, in interval [0; 1), only priority transactions may use. 0 reserves nothing",
+		Value: 0,
+	}
+	SequencerPriorityAddresses = cli.StringFlag{
+		Name:  "zkevm.sequencer-priority-addresses",
+		Usage: "Comma separated addresses whose transactions are priority transactions, whether they send them or are called by them",
+		Value: "",
+	}
+	SequencerPrioritySelectors = cli.StringFlag{
+		Name:  "zkevm.sequencer-priority-selectors",
+		Usage: "Comma separated 4 byte method selectors, e.g. 0xccaa2d11, of the calls that are priority transactions",
+		Value: "",
+	}
+	SequencerPriorityAcl = cli.BoolFlag{
+		Name:  "zkevm.sequencer-priority-acl",
+		Usage: "When enabled, the transactions of senders with the priority policy on the ACL allowlist are priority transactions",
+		Value: false,
+	}
+	SequencerPriorityReserveRelease = cli.DurationFlag{
+		Name:  "zkevm.sequencer-priority-reserve-release",
+		Usage: "Time into a batch from which all transactions may use the reserved share. 0 keeps it for priority transactions for the whole batch",
+		Value: 0,
+	}
 	SequencerBatchSealTime = cli.StringFlag{
 		Name:  "zkevm.sequencer-batch-seal-time",
 		Usage: "Batch seal time. Defaults to 12s",
@@ -571,6 +666,46 @@ var (
 		Usage: "Halt the sequencer on this batch number",
 		Value: 0,
 	}
+	SequencerForcedBatches = cli.BoolFlag{
+		Name:  "zkevm.sequencer-forced-batches",
+		Usage: "When enabled, the sequencer will include forced batches from the L1 once they have passed the forced batch timeout",
+		Value: false,
+	}
+	SequencerForcedBatchTimeout = cli.DurationFlag{
+		Name:  "zkevm.sequencer-forced-batch-timeout",
+		Usage: "Time to wait after a forced batch has been posted to the L1 before the sequencer includes it. Defaults to the forceBatchTimeout of the rollup contract",
+		Value: 0,
+	}
+	SequencerTxOrderingPolicy = cli.StringFlag{
+		Name:  "zkevm.sequencer-tx-ordering-policy",
+		Usage: "Order in which the sequencer takes transactions from the pool: fee-priority, fifo (by arrival) or round-robin (one transaction per sender in turn). Defaults to fee-priority",
+		Value: "fee-priority",
+	}
+	SequencerPreconfirmations = cli.BoolFlag{
+		Name:  "zkevm.sequencer-preconfirmations",
+		Usage: "When enabled, the sequencer signs a pre-confirmation for every transaction as soon as it is executed in the open block, served by zkevm_getPreconfirmation",
+		Value: false,
+	}
+	SequencerPreconfirmationKeyFile = cli.StringFlag{
+		Name:  "zkevm.sequencer-preconfirmation-key-file",
+		Usage: "File holding the hex encoded private key pre-confirmations are signed with",
+		Value: "",
+	}
+	SequencerLeaseLock = cli.StringFlag{
+		Name:  "zkevm.sequencer-lease-lock",
+		Usage: "Lock the sequencer lease is held on, file:///path/to/lease for nodes sharing a filesystem or tcp://127.0.0.1:port for nodes on the same host. Only the holder of the lease produces blocks",
+		Value: "",
+	}
+	SequencerLeaseTtl = cli.DurationFlag{
+		Name:  "zkevm.sequencer-lease-ttl",
+		Usage: "Time after its last renewal a sequencer lease expires and a standby can take over",
+		Value: 10 * time.Second,
+	}
+	SequencerStandby = cli.BoolFlag{
+		Name:  "zkevm.sequencer-standby",
+		Usage: "Run as a hot standby of the sequencer: follow its datastream like an RPC node and restart as the sequencer once its lease on zkevm.sequencer-lease-lock expires",
+		Value: false,
+	}
 	SequencerResequence = cli.BoolFlag{
 		Name:  "zkevm.sequencer-resequence",
 		Usage: "When enabled, the sequencer will automatically resequence unseen batches stored in data stream",
@@ -629,7 +764,7 @@ var (
 	}
 	DatastreamVersionFlag = cli.IntFlag{
 		Name:  "zkevm.datastream-version",
-		Usage: "Stream version indicator 1: PreBigEndian, 2: BigEndian.",
+		Usage: "Stream version indicator 1: PreBigEndian, 2: BigEndian, 3: L2 block end entries, 4: Entries hash in the batch ends, verified by the client.",
 		Value: 2,
 	}
 	DataStreamPort = cli.UintFlag{
@@ -657,6 +792,61 @@ var (
 		Usage: "Define the inactivity check interval timeout when interacting with a data stream server",
 		Value: 5 * time.Minute,
 	}
+	DataStreamTLSCert = cli.StringFlag{
+		Name:  "zkevm.data-stream-tls-cert",
+		Usage: "PEM file of the zkevm data stream certificate, serves the stream over TLS when set",
+		Value: "",
+	}
+	DataStreamTLSKey = cli.StringFlag{
+		Name:  "zkevm.data-stream-tls-key",
+		Usage: "PEM file of the key of the zkevm data stream certificate",
+		Value: "",
+	}
+	DataStreamTLSClientCA = cli.StringFlag{
+		Name:  "zkevm.data-stream-tls-client-ca",
+		Usage: "PEM file of the CA zkevm data stream clients have to present a certificate of, requires mutual TLS when set",
+		Value: "",
+	}
+	DataStreamToken = cli.StringFlag{
+		Name:  "zkevm.data-stream-token",
+		Usage: "Pre-shared token zkevm data stream clients have to authenticate with",
+		Value: "",
+	}
+	DataStreamInternalPort = cli.UintFlag{
+		Name:  "zkevm.data-stream-internal-port",
+		Usage: "Port of the plaintext zkevm data stream behind the proxy on the data stream port, which secures it with TLS/token and answers requests for pruned batches. Required with TLS or a token. Only reachable over loopback (linux only)",
+		Value: 0,
+	}
+	DataStreamBridgePort = cli.UintFlag{
+		Name:  "zkevm.data-stream-bridge-port",
+		Usage: "Port of the HTTP/WebSocket bridge serving the zkevm data stream decoded as JSON, off if 0",
+		Value: 0,
+	}
+	DataStreamSinkNDJSONDir = cli.StringFlag{
+		Name:  "zkevm.data-stream-sink-ndjson-dir",
+		Usage: "Directory the zkevm data stream is written to as newline delimited JSON as it is committed, off if empty",
+		Value: "",
+	}
+	DataStreamSinkNDJSONMaxFileMB = cli.UintFlag{
+		Name:  "zkevm.data-stream-sink-ndjson-max-file-mb",
+		Usage: "Size in MB the newline delimited JSON files of the zkevm data stream are rotated at, unlimited if 0",
+		Value: 100,
+	}
+	DataStreamSinkNDJSONMaxFiles = cli.UintFlag{
+		Name:  "zkevm.data-stream-sink-ndjson-max-files",
+		Usage: "Number of newline delimited JSON files of the zkevm data stream kept, the oldest deleted beyond it, all kept if 0",
+		Value: 10,
+	}
+	DataStreamSinkKafkaBrokers = cli.StringFlag{
+		Name:  "zkevm.data-stream-sink-kafka-brokers",
+		Usage: "Comma separated Kafka brokers the zkevm data stream is produced to as JSON as it is committed, off if empty",
+		Value: "",
+	}
+	DataStreamSinkKafkaTopic = cli.StringFlag{
+		Name:  "zkevm.data-stream-sink-kafka-topic",
+		Usage: "Kafka topic the zkevm data stream is produced to",
+		Value: "datastream",
+	}
 	Limbo = cli.BoolFlag{
 		Name:  "zkevm.limbo",
 		Usage: "Enable limbo processing on batches that failed verification",
@@ -740,7 +930,17 @@ var (
 	}
 	DAUrl = cli.StringFlag{
 		Name:  "zkevm.da-url",
-		Usage: "The URL of the data availability service",
+		Usage: "The URL of the data availability service, or a comma separated list of the URLs of the members of the data availability committee to try in turn. A file:// directory serves the data archived in it, each piece in a file named by its hash",
+		Value: "",
+	}
+	DAVerifySignatures = cli.BoolFlag{
+		Name:  "zkevm.da-verify-signatures",
+		Usage: "Check the data availability committee signatures of validium sequences against the committee members on the L1 during L1 recovery. Needs an L1 RPC serving calls at the blocks of the sequences",
+		Value: true,
+	}
+	L1BeaconUrl = cli.StringFlag{
+		Name:  "zkevm.l1-beacon-url",
+		Usage: "The URL of the beacon API of an L1 consensus node, to fetch the blobs of the sequences carrying their batches in blobs during L1 recovery",
 		Value: "",
 	}
 	VirtualCountersSmtReduction = cli.Float64Flag{
@@ -170,6 +170,46 @@ func (bcc *BatchCounterCollector) CheckForOverflow(verifyMerkleProof bool) (bool
 	return overflow, nil
 }
 
+// CheckForReservedOverflow returns true in the case that any counter has less than the reserved share of its limit
+// remaining.  With a reserve of 0 it is the same as CheckForOverflow.
+func (bcc *BatchCounterCollector) CheckForReservedOverflow(verifyMerkleProof bool, reserve float64) (bool, error) {
+	if bcc.unlimitedCounters {
+		return false, nil
+	}
+	combined, err := bcc.CombineCollectors(verifyMerkleProof)
+	if err != nil {
+		return false, err
+	}
+	for _, v := range combined {
+		if v.remaining < int(float64(v.initialAmount)*reserve) {
+			log.Debug("[VCOUNTER] Counter reached the reserved share", "counter", v.name, "remaining", v.remaining, "used", v.used, "reserve", reserve)
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// OverflowingCounters returns the names of the counters that have less than 0 remaining
+func (bcc *BatchCounterCollector) OverflowingCounters(verifyMerkleProof bool) ([]string, error) {
+	if bcc.unlimitedCounters {
+		return nil, nil
+	}
+	combined, err := bcc.CombineCollectors(verifyMerkleProof)
+	if err != nil {
+		return nil, err
+	}
+
+	var overflowing []string
+	for _, v := range combined {
+		if v.remaining < 0 {
+			overflowing = append(overflowing, v.name)
+		}
+	}
+
+	return overflowing, nil
+}
+
 // CounterStats returns a string with combined counter stats.
 func (bcc *BatchCounterCollector) CounterStats(verifyMerkleProof bool) (string, error) {
 	combined, err := bcc.CombineCollectors(verifyMerkleProof)
@@ -0,0 +1,36 @@
+package vm
+
+import (
+	"testing"
+
+	zk_consts "github.com/ledgerwatch/erigon-lib/chain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckForReservedOverflow(t *testing.T) {
+	bcc := NewBatchCounterCollector(256, uint16(zk_consts.ForkID12Banana), 0.6, false, nil)
+	limit := bcc.executionCombinedCounters[S].initialAmount
+
+	bcc.executionCombinedCounters[S].used = limit / 2
+	overflow, err := bcc.CheckForReservedOverflow(false, 0.1)
+	require.NoError(t, err)
+	require.False(t, overflow)
+
+	// into the reserved share but still within the limit
+	bcc.executionCombinedCounters[S].used = limit * 95 / 100
+	overflow, err = bcc.CheckForReservedOverflow(false, 0.1)
+	require.NoError(t, err)
+	require.True(t, overflow)
+	overflow, err = bcc.CheckForOverflow(false)
+	require.NoError(t, err)
+	require.False(t, overflow)
+	overflow, err = bcc.CheckForReservedOverflow(false, 0)
+	require.NoError(t, err)
+	require.False(t, overflow)
+
+	unlimited := NewBatchCounterCollector(256, uint16(zk_consts.ForkID12Banana), 0.6, true, nil)
+	unlimited.executionCombinedCounters[S].used = limit
+	overflow, err = unlimited.CheckForReservedOverflow(false, 0.1)
+	require.NoError(t, err)
+	require.False(t, overflow)
+}
@@ -799,12 +799,16 @@ const (
 	RecentLocalTransaction = "RecentLocalTransaction" // sequence_u64 -> tx_hash
 	PoolTransaction        = "PoolTransaction"        // txHash -> sender+tx_rlp
 	PoolInfo               = "PoolInfo"               // option_key -> option_value
+	PoolBundle             = "PoolBundle"             // bundle_hash -> tx_hashes
+	PoolDeadline           = "PoolDeadline"           // tx_hash -> block_number_u64 + timestamp_u64
 )
 
 var TxPoolTables = []string{
 	RecentLocalTransaction,
 	PoolTransaction,
 	PoolInfo,
+	PoolBundle,
+	PoolDeadline,
 }
 var SentryTables = []string{}
 var DownloaderTables = []string{
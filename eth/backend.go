@@ -49,6 +49,7 @@ import (
 	"github.com/ledgerwatch/erigon-lib/config3"
 	"github.com/ledgerwatch/erigon-lib/kv/temporal"
 	"github.com/ledgerwatch/log/v3"
+	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -83,6 +84,7 @@ import (
 	libtypes "github.com/ledgerwatch/erigon-lib/types"
 	"github.com/ledgerwatch/erigon-lib/wrap"
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/cli"
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/cli/httpcfg"
 	"github.com/ledgerwatch/erigon/common/debug"
 	"github.com/ledgerwatch/erigon/consensus"
 	"github.com/ledgerwatch/erigon/consensus/clique"
@@ -131,6 +133,7 @@ import (
 	"github.com/ledgerwatch/erigon/zk/contracts"
 	"github.com/ledgerwatch/erigon/zk/datastream/client"
 	"github.com/ledgerwatch/erigon/zk/datastream/server"
+	"github.com/ledgerwatch/erigon/zk/datastream/sink"
 	"github.com/ledgerwatch/erigon/zk/hermez_db"
 	"github.com/ledgerwatch/erigon/zk/l1_cache"
 	"github.com/ledgerwatch/erigon/zk/l1infotree"
@@ -224,9 +227,17 @@ type Ethereum struct {
 
 	// zk
 	streamServer    server.StreamServer
+	streamSinks     *server.SinkDispatcher
+	streamClients   *server.ClientTracker
 	l1Syncer        *syncer.L1Syncer
 	etherManClients []*etherman.Client
 	l1Cache         *l1_cache.L1Cache
+	// set when sequencing, lets the admin api pause, resume and drain the sequencer
+	sequencerControl *sequencer.Control
+	// set when sequencing with pre-confirmations enabled
+	preconfirmer *sequencer.Preconfirmer
+	// set when sequencing or on standby with a sequencer lease lock
+	sequencerLease *sequencer.Lease
 
 	preStartTasks *PreStartTasks
 
@@ -238,6 +249,8 @@ type Ethereum struct {
 
 	polygonSyncService polygonsync.Service
 	stopNode           func() error
+
+	promotedToSequencer atomic.Bool
 }
 
 func splitAddrIntoHostAndPort(addr string) (host string, port int, err error) {
@@ -252,6 +265,9 @@ func splitAddrIntoHostAndPort(addr string) (host string, port int, err error) {
 
 const blockBufferSize = 128
 
+// number of the most recent pre-confirmations the sequencer keeps for zkevm_getPreconfirmation
+const preconfirmationsKept = 100_000
+
 // New creates a new Ethereum object (including the
 // initialisation of the common Ethereum object)
 func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger log.Logger) (*Ethereum, error) {
@@ -982,11 +998,56 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 				Outputs:     nil,
 			}
 
+			// with an internal port the plaintext stream moves there and the auth proxy takes the public one, which is
+			// required for TLS or a token and also answers the requests for pruned batches.  The internal port is
+			// only reachable over loopback, the node does not start otherwise
+			auth := server.AuthConfig{
+				CertFile:     httpCfg.DataStreamTLSCert,
+				KeyFile:      httpCfg.DataStreamTLSKey,
+				ClientCAFile: httpCfg.DataStreamTLSClientCA,
+				Token:        httpCfg.DataStreamToken,
+			}
+			streamPort := uint16(httpCfg.DataStreamPort)
+			behindProxy := httpCfg.DataStreamInternalPort != 0
+			if (auth.Enabled() || behindProxy) && (httpCfg.DataStreamInternalPort == 0 || httpCfg.DataStreamInternalPort == uint(httpCfg.DataStreamPort)) {
+				return nil, errors.New("zkevm.data-stream-internal-port has to be set to a port other than the data stream port when securing the data stream or putting it behind the proxy")
+			}
+			if behindProxy {
+				streamPort = uint16(httpCfg.DataStreamInternalPort)
+			}
+
+			// a prune of the stream stopped halfway through swapping in the compacted stream is finished first
+			if err := server.CompleteStreamSwap(file); err != nil {
+				return nil, err
+			}
+
 			// todo [zkevm] read the stream version from config and figure out what system id is used for
-			backend.streamServer, err = dataStreamServerFactory.CreateStreamServer(uint16(httpCfg.DataStreamPort), uint8(backend.config.DatastreamVersion), 1, datastreamer.StreamType(1), file, httpCfg.DataStreamWriteTimeout, httpCfg.DataStreamInactivityTimeout, httpCfg.DataStreamInactivityCheckInterval, logConfig)
+			backend.streamServer, err = dataStreamServerFactory.CreateStreamServer(streamPort, uint8(backend.config.DatastreamVersion), 1, datastreamer.StreamType(1), file, httpCfg.DataStreamWriteTimeout, httpCfg.DataStreamInactivityTimeout, httpCfg.DataStreamInactivityCheckInterval, logConfig)
 			if err != nil {
 				return nil, err
 			}
+			sinks, err := dataStreamSinks(&httpCfg)
+			if err != nil {
+				return nil, err
+			}
+			if len(sinks) > 0 {
+				if backend.streamSinks, err = server.NewSinkDispatcher(stack.Config().Dirs.DataDir+"/data-stream-sinks", sinks...); err != nil {
+					return nil, err
+				}
+				backend.streamServer = server.NewSinkStreamServer(backend.streamServer, backend.streamSinks)
+			}
+			// the stream server keeps its clients to itself, they are only tracked behind the proxy where every
+			// client goes through code of ours: the internal port only takes the proxy's connections
+			if behindProxy {
+				backend.streamClients = server.NewClientTracker(backend.streamServer)
+				if err := prometheus.Register(backend.streamClients); err != nil {
+					return nil, err
+				}
+				backend.streamServer = server.NewAuthStreamServer(backend.streamServer, fmt.Sprintf(":%d", httpCfg.DataStreamPort), streamPort, auth, backend.streamClients)
+			}
+			if httpCfg.DataStreamBridgePort != 0 {
+				backend.streamServer = server.NewBridgeStreamServer(backend.streamServer, fmt.Sprintf(":%d", httpCfg.DataStreamBridgePort), auth, httpCfg.DataStreamWriteTimeout, backend.streamClients)
+			}
 
 			// recovery here now, if the stream got into a bad state we want to be able to delete the file and have
 			// the stream re-populated from scratch.  So we check the stream for the latest header and if it is
@@ -1010,8 +1071,11 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 		backend.chainConfig.ZkDefaultGasPrice = cfg.DefaultGasPrice
 		l1Urls := strings.Split(cfg.L1RpcUrl, ",")
 
+		if !cfg.Zk.L1CacheEnabled && cfg.Zk.L1CacheMode != "" && cfg.Zk.L1CacheMode != l1_cache.ModeCache {
+			return nil, fmt.Errorf("the l1 cache has to be enabled to %s", cfg.Zk.L1CacheMode)
+		}
 		if cfg.Zk.L1CacheEnabled {
-			l1Cache, err := l1_cache.NewL1Cache(ctx, path.Join(stack.DataDir(), "l1cache"), cfg.Zk.L1CachePort)
+			l1Cache, err := l1_cache.NewL1Cache(ctx, path.Join(stack.DataDir(), "l1cache"), cfg.Zk.L1CachePort, cfg.Zk.L1CacheMode, cfg.Zk.L1CacheFixture)
 			if err != nil {
 				return nil, err
 			}
@@ -1045,6 +1109,7 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 			contracts.SequencedBatchTopicPreEtrog,
 			contracts.SequencedBatchTopicEtrog,
 			contracts.RollbackBatchesTopic,
+			contracts.ForceBatchTopic,
 			contracts.VerificationTopicPreEtrog,
 			contracts.VerificationTopicEtrog,
 			contracts.VerificationValidiumTopicEtrog,
@@ -1093,11 +1158,27 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 			cfg.L1HighestBlockType,
 		)
 
+		// the critical reads of the L1 syncers are checked by a quorum of the L1 providers when set
+		for _, l1Syncer := range []*syncer.L1Syncer{seqVerSyncer, backend.l1Syncer} {
+			if err := l1Syncer.SetQuorum(cfg.L1QuorumProviders, cfg.L1Quorum); err != nil {
+				return nil, err
+			}
+		}
+
 		log.Info("Rollup ID", "rollupId", cfg.L1RollupId)
 
 		// Check if L1 contracts addresses should be retrieved from the L1 chain
 		l1ContractAddressProcess(ctx, cfg.Zk, backend.l1Syncer)
 
+		if isSequencer && cfg.SequencerForcedBatches && cfg.SequencerForcedBatchTimeout == 0 {
+			forceBatchTimeout, err := backend.l1Syncer.CallForceBatchTimeout(ctx, &cfg.AddressZkevm)
+			if err != nil {
+				panic(fmt.Sprintf("Failed to retrieve the force batch timeout from L1: %v", err))
+			}
+			cfg.SequencerForcedBatchTimeout = time.Duration(forceBatchTimeout) * time.Second
+			log.Info("Force batch timeout retrieved from L1", "timeout", cfg.SequencerForcedBatchTimeout)
+		}
+
 		l1InfoTreeSyncer := syncer.NewL1Syncer(
 			ctx,
 			ethermanClients,
@@ -1107,12 +1188,28 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 			cfg.L1QueryDelay,
 			cfg.L1HighestBlockType,
 		)
+		if err := l1InfoTreeSyncer.SetQuorum(cfg.L1QuorumProviders, cfg.L1Quorum); err != nil {
+			return nil, err
+		}
 
 		l1InfoTreeUpdater := l1infotree.NewUpdater(cfg.Zk, l1InfoTreeSyncer)
 
 		var dataStreamServer server.DataStreamServer
 		if backend.streamServer != nil {
-			dataStreamServer = dataStreamServerFactory.CreateDataStreamServer(backend.streamServer, backend.chainConfig.ChainID.Uint64())
+			dataStreamServer = dataStreamServerFactory.CreateDataStreamServer(backend.streamServer, backend.chainConfig.ChainID.Uint64(), uint8(cfg.DatastreamVersion))
+		}
+
+		if cfg.SequencerLeaseLock != "" && (isSequencer || cfg.SequencerStandby) {
+			leaseLock, err := sequencer.NewLeaseLock(cfg.SequencerLeaseLock)
+			if err != nil {
+				return nil, err
+			}
+			// stays the same when a standby restarts as the sequencer so that it keeps the lease it took over
+			hostname, err := os.Hostname()
+			if err != nil {
+				return nil, err
+			}
+			backend.sequencerLease = sequencer.NewLease(leaseLock, fmt.Sprintf("%s:%s", hostname, dirs.DataDir), cfg.SequencerLeaseTtl)
 		}
 
 		if isSequencer {
@@ -1159,6 +1256,17 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 			// we switch context from being an RPC node to a sequencer
 			backend.txPool2.ForceUpdateLatestBlock(executionProgress)
 
+			backend.sequencerControl = sequencer.NewControl()
+
+			if cfg.SequencerPreconfirmations {
+				preconfirmationKey, err := crypto.LoadECDSA(cfg.SequencerPreconfirmationKeyFile)
+				if err != nil {
+					return nil, fmt.Errorf("failed to load the pre-confirmation key: %w", err)
+				}
+				backend.preconfirmer = sequencer.NewPreconfirmer(preconfirmationKey, backend.chainConfig.ChainID.Uint64(), preconfirmationsKept)
+				log.Info("Sequencer pre-confirmations enabled", "signer", backend.preconfirmer.Address())
+			}
+
 			l1BlockSyncer := syncer.NewL1Syncer(
 				ctx,
 				ethermanClients,
@@ -1170,6 +1278,9 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 				cfg.L1QueryDelay,
 				cfg.L1HighestBlockType,
 			)
+			if err := l1BlockSyncer.SetQuorum(cfg.L1QuorumProviders, cfg.L1Quorum); err != nil {
+				return nil, err
+			}
 
 			backend.syncStages = stages2.NewSequencerZkStages(
 				backend.sentryCtx,
@@ -1190,6 +1301,9 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 				backend.txPool2DB,
 				verifier,
 				l1InfoTreeUpdater,
+				backend.sequencerControl,
+				backend.preconfirmer,
+				backend.sequencerLease,
 			)
 
 			backend.syncUnwindOrder = zkStages.ZkSequencerUnwindOrder
@@ -1296,7 +1410,10 @@ func newEtherMan(cfg *ethconfig.Config, l2ChainName, url string) *etherman.Clien
 
 // creates a datastream client with default parameters
 func initDataStreamClient(ctx context.Context, cfg *ethconfig.Zk, latestForkId uint16) *client.StreamClient {
-	return client.NewClient(ctx, cfg.L2DataStreamerUrl, cfg.DatastreamVersion, cfg.L2DataStreamerTimeout, latestForkId)
+	c := client.NewClient(ctx, cfg.L2DataStreamerUrl, cfg.DatastreamVersion, cfg.L2DataStreamerTimeout, latestForkId)
+	c.SetAuth(zkStages.DatastreamAuth(cfg))
+	c.SetDecodeWorkers(cfg.L2DataStreamerDecodeWorkers)
+	return c
 }
 
 func (s *Ethereum) Init(stack *node.Node, config *ethconfig.Config, chainConfig *chain.Config) error {
@@ -1347,10 +1464,10 @@ func (s *Ethereum) Init(stack *node.Node, config *ethconfig.Config, chainConfig
 
 	var dataStreamServer server.DataStreamServer
 	if s.streamServer != nil {
-		dataStreamServer = dataStreamServerFactory.CreateDataStreamServer(s.streamServer, config.Zk.L2ChainId)
+		dataStreamServer = dataStreamServerFactory.CreateDataStreamServer(s.streamServer, config.Zk.L2ChainId, uint8(config.Zk.DatastreamVersion))
 	}
 	var gpCache *jsonrpc.GasPriceCache
-	s.apiList, gpCache = jsonrpc.APIList(chainKv, ethRpcClient, txPoolRpcClient, s.txPool2, miningRpcClient, ff, stateCache, blockReader, s.agg, &httpRpcCfg, s.engine, config, s.l1Syncer, s.logger, dataStreamServer)
+	s.apiList, gpCache = jsonrpc.APIList(chainKv, ethRpcClient, txPoolRpcClient, s.txPool2, miningRpcClient, ff, stateCache, blockReader, s.agg, &httpRpcCfg, s.engine, config, s.l1Syncer, s.logger, dataStreamServer, s.preconfirmer)
 
 	// For X Layer
 	if s.txPool2 != nil && gpCache != nil {
@@ -1388,6 +1505,24 @@ func (s *Ethereum) Init(stack *node.Node, config *ethconfig.Config, chainConfig
 		}()
 	}
 
+	if s.sequencerControl != nil {
+		s.engineBackendRPC.AddAuthenticatedAPIs(rpc.API{
+			Namespace: "admin",
+			Public:    false,
+			Service:   jsonrpc.SequencerAdminAPI(jsonrpc.NewSequencerAdminAPI(s.sequencerControl)),
+			Version:   "1.0",
+		})
+	}
+
+	if s.streamClients != nil {
+		s.engineBackendRPC.AddAuthenticatedAPIs(rpc.API{
+			Namespace: "admin",
+			Public:    false,
+			Service:   jsonrpc.DatastreamAdminAPI(jsonrpc.NewDatastreamAdminAPI(s.streamClients)),
+			Version:   "1.0",
+		})
+	}
+
 	if chainConfig.Bor == nil {
 		go s.engineBackendRPC.Start(ctx, &httpRpcCfg, s.chainDB, s.blockReader, ff, stateCache, s.agg, s.engine, ethRpcClient, txPoolRpcClient, miningRpcClient)
 	}
@@ -1416,7 +1551,7 @@ func (s *Ethereum) PreStart() error {
 		// we don't know when the server has actually started as it doesn't expose a signal that is has spun up
 		// so here we loop and take a brief pause waiting for it to be ready
 		attempts := 0
-		dataStreamServer := dataStreamServerFactory.CreateDataStreamServer(s.streamServer, s.chainConfig.ChainID.Uint64())
+		dataStreamServer := dataStreamServerFactory.CreateDataStreamServer(s.streamServer, s.chainConfig.ChainID.Uint64(), uint8(s.config.DatastreamVersion))
 		for {
 			_, err = zkStages.CatchupDatastream(s.sentryCtx, "stream-catchup", tx, dataStreamServer)
 			if err != nil {
@@ -1880,6 +2015,13 @@ func (s *Ethereum) Start() error {
 		return currentTD
 	}
 
+	if s.sequencerLease != nil {
+		go s.sequencerLease.Run(s.sentryCtx)
+		if !sequencer.IsSequencer() {
+			go s.promoteOnSequencerLease()
+		}
+	}
+
 	nodeStages := s.stagedSync.StagesIdsList()
 
 	if params.IsChainPoS(s.chainConfig, currentTDProvider) {
@@ -1958,6 +2100,12 @@ func (s *Ethereum) Stop() error {
 	if s.waitForStageLoopStop != nil {
 		<-s.waitForStageLoopStop
 	}
+	// a standby holding the lease keeps it over its restart as the sequencer
+	if sequencer.IsSequencer() {
+		if err := s.sequencerLease.Release(); err != nil {
+			s.logger.Warn("Failed to release the sequencer lease", "err", err)
+		}
+	}
 	if s.config.Miner.Enabled {
 		<-s.waitForMiningStop
 	}
@@ -1971,6 +2119,16 @@ func (s *Ethereum) Stop() error {
 		s.agg.Close()
 	}
 	s.chainDB.Close()
+	if s.l1Cache != nil {
+		if err := s.l1Cache.Close(); err != nil {
+			s.logger.Warn("Failed to close the L1 cache", "err", err)
+		}
+	}
+	if s.streamSinks != nil {
+		if err := s.streamSinks.Close(); err != nil {
+			s.logger.Warn("Failed to close the data stream sinks", "err", err)
+		}
+	}
 
 	if s.silkwormRPCDaemonService != nil {
 		if err := s.silkwormRPCDaemonService.Stop(); err != nil {
@@ -1991,6 +2149,43 @@ func (s *Ethereum) Stop() error {
 	return nil
 }
 
+// promoteOnSequencerLease stops the standby once it gets the sequencer lease so that it can restart as the sequencer
+func (s *Ethereum) promoteOnSequencerLease() {
+	select {
+	case <-s.sentryCtx.Done():
+		return
+	case <-s.sequencerLease.Acquired():
+	}
+
+	s.logger.Info("Standby got the sequencer lease, stopping to restart as the sequencer")
+	s.promotedToSequencer.Store(true)
+	if err := s.stopNode(); err != nil {
+		s.logger.Error("could not stop node", "err", err)
+	}
+}
+
+// PromotedToSequencer is true once a standby stopped to take over as the sequencer
+func (s *Ethereum) PromotedToSequencer() bool {
+	return s.promotedToSequencer.Load()
+}
+
+// dataStreamSinks are the sinks configured for the data stream
+func dataStreamSinks(httpCfg *httpcfg.HttpCfg) ([]server.DatastreamSink, error) {
+	var sinks []server.DatastreamSink
+	if httpCfg.DataStreamSinkNDJSONDir != "" {
+		ndjson, err := sink.NewNDJSONSink(httpCfg.DataStreamSinkNDJSONDir, int64(httpCfg.DataStreamSinkNDJSONMaxFileMB)<<20, int(httpCfg.DataStreamSinkNDJSONMaxFiles))
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, ndjson)
+	}
+	if httpCfg.DataStreamSinkKafkaBrokers != "" {
+		producer := sink.NewKafkaProducer(strings.Split(httpCfg.DataStreamSinkKafkaBrokers, ","), httpCfg.DataStreamSinkKafkaTopic)
+		sinks = append(sinks, sink.NewProducerSink("kafka", producer))
+	}
+	return sinks, nil
+}
+
 func (s *Ethereum) ChainDB() kv.RwDB {
 	return s.chainDB
 }
@@ -12,6 +12,12 @@ type Zk struct {
 	L2RpcUrl                               string
 	L2DataStreamerUrl                      string
 	L2DataStreamerTimeout                  time.Duration
+	L2DataStreamerDecodeWorkers            int
+	L2DataStreamerTLS                      bool
+	L2DataStreamerTLSCA                    string
+	L2DataStreamerTLSCert                  string
+	L2DataStreamerTLSKey                   string
+	L2DataStreamerToken                    string
 	L2ShortCircuitToVerifiedBatch          bool
 	L1SyncStartBlock                       uint64
 	L1SyncStopBatch                        uint64
@@ -28,20 +34,41 @@ type Zk struct {
 	L1BlockRange                           uint64
 	L1QueryDelay                           uint64
 	L1HighestBlockType                     string
+	L1Quorum                               int
+	L1QuorumProviders                      int
 	L1MaticContractAddress                 common.Address
 	L1FirstBlock                           uint64
 	L1FinalizedBlockRequirement            uint64
 	L1CacheEnabled                         bool
 	L1CachePort                            uint
+	L1CacheMode                            string
+	L1CacheFixture                         string
 	RpcRateLimits                          int
 	RpcGetBatchWitnessConcurrencyLimit     int
 	DatastreamVersion                      int
 	SequencerBlockSealTime                 time.Duration
 	SequencerBatchSealTime                 time.Duration
+	SequencerAdaptiveSealTime              bool
+	SequencerBlockSealTimeMin              time.Duration
+	SequencerBlockSealTimeMax              time.Duration
+	SequencerAdaptiveSealPendingTarget     uint64
+	SequencerPriorityReserve               float64
+	SequencerPriorityAddresses             []common.Address
+	SequencerPrioritySelectors             [][4]byte
+	SequencerPriorityAcl                   bool
+	SequencerPriorityReserveRelease        time.Duration
 	SequencerBatchVerificationTimeout      time.Duration
 	SequencerBatchVerificationRetries      int
 	SequencerTimeoutOnEmptyTxPool          time.Duration
 	SequencerHaltOnBatchNumber             uint64
+	SequencerForcedBatches                 bool
+	SequencerForcedBatchTimeout            time.Duration
+	SequencerTxOrderingPolicy              string
+	SequencerPreconfirmations              bool
+	SequencerPreconfirmationKeyFile        string
+	SequencerLeaseLock                     string
+	SequencerLeaseTtl                      time.Duration
+	SequencerStandby                       bool
 	SequencerResequence                    bool
 	SequencerResequenceStrict              bool
 	SequencerResequenceReuseL1InfoIndex    bool
@@ -62,6 +89,8 @@ type Zk struct {
 	MaxGasPrice                            uint64
 	GasPriceFactor                         float64
 	DAUrl                                  string
+	DAVerifySignatures                     bool
+	L1BeaconUrl                            string
 	DataStreamHost                         string
 	DataStreamPort                         uint
 	DataStreamWriteTimeout                 time.Duration
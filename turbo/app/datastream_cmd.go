@@ -0,0 +1,276 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-data-streamer/datastreamer"
+	dslog "github.com/0xPolygonHermez/zkevm-data-streamer/log"
+	"github.com/gofrs/flock"
+	"github.com/ledgerwatch/erigon-lib/common/datadir"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/log/v3"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ledgerwatch/erigon/cmd/hack/tool/fromdb"
+	"github.com/ledgerwatch/erigon/cmd/utils"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/turbo/debug"
+	"github.com/ledgerwatch/erigon/zk/datastream/archive"
+	"github.com/ledgerwatch/erigon/zk/datastream/server"
+	"github.com/ledgerwatch/erigon/zk/hermez_db"
+	zkStages "github.com/ledgerwatch/erigon/zk/stages"
+)
+
+var (
+	DatastreamArchiveFlag = cli.StringFlag{
+		Name:     "archive",
+		Usage:    "Path of the datastream archive file",
+		Required: true,
+	}
+	DatastreamFromBatchFlag = cli.Uint64Flag{
+		Name:  "from-batch",
+		Usage: "First batch to export",
+		Value: 0,
+	}
+	DatastreamToBatchFlag = cli.Uint64Flag{
+		Name:     "to-batch",
+		Usage:    "Last batch to export, it has to be closed in the datastream",
+		Required: true,
+	}
+	DatastreamRetainBatchesFlag = cli.Uint64Flag{
+		Name:  "retain-batches",
+		Usage: "Keep the last N closed batches",
+		Value: 0,
+	}
+	DatastreamKeepUnverifiedFlag = cli.BoolFlag{
+		Name:  "keep-unverified",
+		Usage: "Keep the batches after the last one verified on L1.  With --retain-batches too, the rule keeping more batches wins",
+	}
+)
+
+var datastreamCommand = cli.Command{
+	Name:  "datastream",
+	Usage: "Export the datastream to archive files, seed nodes from them and prune the datastream",
+	Before: func(context *cli.Context) error {
+		_, _, _, err := debug.Setup(context, true /* rootLogger */)
+		return err
+	},
+	Subcommands: []*cli.Command{
+		{
+			Name:   "export",
+			Action: MigrateFlags(exportDatastream),
+			Usage:  "Write the entries of a range of batches of the node's datastream to an archive",
+			Flags: joinFlags([]cli.Flag{
+				&utils.DataDirFlag,
+				&utils.DatastreamVersionFlag,
+				&DatastreamArchiveFlag,
+				&DatastreamFromBatchFlag,
+				&DatastreamToBatchFlag,
+			}),
+		},
+		{
+			Name:   "import",
+			Action: MigrateFlags(importDatastream),
+			Usage:  "Process the blocks of an archive as if they came from the datastream of the network, the node has to be stopped",
+			Flags: joinFlags([]cli.Flag{
+				&utils.DataDirFlag,
+				&utils.MinerGasLimitFlag,
+				&DatastreamArchiveFlag,
+			}),
+		},
+		{
+			Name:   "prune",
+			Action: MigrateFlags(pruneDatastream),
+			Usage:  "Rewrite the node's datastream without the batches before those kept, the node has to be stopped",
+			Flags: joinFlags([]cli.Flag{
+				&utils.DataDirFlag,
+				&utils.DatastreamVersionFlag,
+				&DatastreamRetainBatchesFlag,
+				&DatastreamKeepUnverifiedFlag,
+			}),
+		},
+	},
+}
+
+// openDatastream opens the stream of the datadir without serving it
+func openDatastream(cliCtx *cli.Context, name string) (server.StreamServer, error) {
+	logConfig := &dslog.Config{
+		Environment: "production",
+		Level:       "warn",
+		Outputs:     nil,
+	}
+	return server.NewZkEVMDataStreamServerFactory().CreateStreamServer(0, uint8(cliCtx.Int(utils.DatastreamVersionFlag.Name)), 1, datastreamer.StreamType(1), name, time.Second, time.Second, time.Second, logConfig)
+}
+
+func exportDatastream(cliCtx *cli.Context) error {
+	dirs := datadir.New(cliCtx.String(utils.DataDirFlag.Name))
+	name := filepath.Join(dirs.DataDir, "data-stream")
+	streamFile, _ := server.StreamFiles(name)
+	if _, err := os.Stat(streamFile); err != nil {
+		return fmt.Errorf("no datastream in the datadir: %w", err)
+	}
+
+	// never started, only the file is read
+	stream, err := openDatastream(cliCtx, name)
+	if err != nil {
+		return err
+	}
+
+	w, err := archive.Create(cliCtx.String(DatastreamArchiveFlag.Name))
+	if err != nil {
+		return err
+	}
+	fromBatch, toBatch := cliCtx.Uint64(DatastreamFromBatchFlag.Name), cliCtx.Uint64(DatastreamToBatchFlag.Name)
+	if err := archive.Export(stream, w, fromBatch, toBatch); err != nil {
+		return errors.Join(err, w.Close(), os.Remove(cliCtx.String(DatastreamArchiveFlag.Name)))
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	log.Info("Exported the datastream", "archive", cliCtx.String(DatastreamArchiveFlag.Name), "fromBatch", fromBatch, "toBatch", toBatch, "batches", len(w.Batches()))
+	return nil
+}
+
+func importDatastream(cliCtx *cli.Context) error {
+	reader, err := archive.Open(cliCtx.String(DatastreamArchiveFlag.Name))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	dirs := datadir.New(cliCtx.String(utils.DataDirFlag.Name))
+	db := dbCfg(kv.ChainDB, dirs.Chaindata).MustOpen()
+	defer db.Close()
+
+	chainConfig := fromdb.ChainConfig(db)
+	miningConfig := ethconfig.Defaults.Miner
+	miningConfig.GasLimit = cliCtx.Uint64(utils.MinerGasLimitFlag.Name)
+
+	return zkStages.ImportDatastreamArchive(cliCtx.Context, db, reader, chainConfig, &miningConfig)
+}
+
+func pruneDatastream(cliCtx *cli.Context) error {
+	policy := server.RetentionPolicy{
+		RetainBatches:  cliCtx.Uint64(DatastreamRetainBatchesFlag.Name),
+		KeepUnverified: cliCtx.Bool(DatastreamKeepUnverifiedFlag.Name),
+	}
+	if policy == (server.RetentionPolicy{}) {
+		return fmt.Errorf("set --%s or --%s to decide the batches kept", DatastreamRetainBatchesFlag.Name, DatastreamKeepUnverifiedFlag.Name)
+	}
+
+	dirs := datadir.New(cliCtx.String(utils.DataDirFlag.Name))
+	name := filepath.Join(dirs.DataDir, "data-stream")
+	streamFile, bookmarksDb := server.StreamFiles(name)
+	if _, err := os.Stat(streamFile); err != nil {
+		return fmt.Errorf("no datastream in the datadir: %w", err)
+	}
+
+	// the lock of the datadir is held for the whole command, a node started meanwhile would open the stream halfway
+	// through the swap
+	dirLock, locked, err := datadir.TryFlock(dirs)
+	if err != nil {
+		return err
+	}
+	if !locked {
+		return fmt.Errorf("%w: stop the node before pruning the datastream", datadir.ErrDataDirLocked)
+	}
+	defer dirLock.Unlock()
+	if err := checkDatastreamUnlocked(bookmarksDb); err != nil {
+		return err
+	}
+	// a swap left halfway by a previous run is finished first
+	if err := server.CompleteStreamSwap(name); err != nil {
+		return err
+	}
+
+	stream, err := openDatastream(cliCtx, name)
+	if err != nil {
+		return err
+	}
+	firstBatch, _, err := server.StreamStart(stream)
+	if err != nil {
+		return err
+	}
+	highestClosedBatch, err := server.NewZkEVMDataStreamServerFactory().CreateDataStreamServer(stream, 0, uint8(cliCtx.Int(utils.DatastreamVersionFlag.Name))).GetHighestClosedBatchNoCache()
+	if err != nil {
+		return err
+	}
+
+	var lastVerifiedBatch uint64
+	if policy.KeepUnverified {
+		db := dbCfg(kv.ChainDB, dirs.Chaindata).MustOpen()
+		err := db.View(cliCtx.Context, func(tx kv.Tx) error {
+			verification, err := hermez_db.NewHermezDbReader(tx).GetLatestVerification()
+			if err != nil {
+				return err
+			}
+			if verification != nil {
+				lastVerifiedBatch = verification.BatchNo
+			}
+			return nil
+		})
+		db.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	fromBatch := policy.PruneFromBatch(highestClosedBatch, lastVerifiedBatch)
+	if fromBatch <= firstBatch {
+		log.Info("Nothing to prune from the datastream", "firstBatch", firstBatch, "keptFromBatch", fromBatch)
+		return nil
+	}
+
+	// the stream is written next to the current one and only swapped in once complete
+	compactedName := server.CompactedStreamName(name)
+	compactedFile, compactedBookmarksDb := server.StreamFiles(compactedName)
+	if err := errors.Join(os.RemoveAll(compactedFile), os.RemoveAll(compactedBookmarksDb)); err != nil {
+		return err
+	}
+	compacted, err := openDatastream(cliCtx, compactedName)
+	if err != nil {
+		return err
+	}
+	// the stream server only writes once started, it listens on a random port until the command exits
+	if err := compacted.Start(); err != nil {
+		return err
+	}
+
+	log.Info("Pruning the datastream", "firstBatch", firstBatch, "keptFromBatch", fromBatch, "highestClosedBatch", highestClosedBatch)
+	if err := server.CompactStream(stream, compacted, fromBatch); err != nil {
+		return err
+	}
+
+	if err := server.SwapCompactedStream(name); err != nil {
+		return err
+	}
+
+	log.Info("Pruned the datastream", "firstBatch", fromBatch, "entries", compacted.GetHeader().TotalEntries)
+	return nil
+}
+
+// checkDatastreamUnlocked fails if a stream server holds the bookmarks db of the stream open, it locks it while open
+func checkDatastreamUnlocked(bookmarksDb string) error {
+	lockFile := filepath.Join(bookmarksDb, "LOCK")
+	if _, err := os.Stat(lockFile); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	lock := flock.New(lockFile)
+	locked, err := lock.TryLock()
+	if err != nil {
+		return err
+	}
+	if !locked {
+		return fmt.Errorf("the datastream is open in another process, stop it first: %s", lockFile)
+	}
+	return lock.Unlock()
+}
@@ -60,6 +60,7 @@ func MakeApp_zkEvm(name string, action cli.ActionFunc, cliFlags []cli.Flag) *cli
 		&importCommand,
 		&snapshotCommand,
 		&supportCommand,
+		&datastreamCommand,
 		//&backupCommand,
 	}
 	return app
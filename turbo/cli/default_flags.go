@@ -177,6 +177,12 @@ var DefaultFlags = []cli.Flag{
 	&utils.L2RpcUrlFlag,
 	&utils.L2DataStreamerUrlFlag,
 	&utils.L2DataStreamerTimeout,
+	&utils.L2DataStreamerDecodeWorkers,
+	&utils.L2DataStreamerTLS,
+	&utils.L2DataStreamerTLSCA,
+	&utils.L2DataStreamerTLSCert,
+	&utils.L2DataStreamerTLSKey,
+	&utils.L2DataStreamerToken,
 	&utils.L2ShortCircuitToVerifiedBatchFlag,
 	&utils.L1SyncStartBlock,
 	&utils.L1SyncStopBatch,
@@ -184,6 +190,8 @@ var DefaultFlags = []cli.Flag{
 	&utils.L1RpcUrlFlag,
 	&utils.L1CacheEnabledFlag,
 	&utils.L1CachePortFlag,
+	&utils.L1CacheModeFlag,
+	&utils.L1CacheFixtureFlag,
 	&utils.AddressSequencerFlag,
 	&utils.AddressAdminFlag,
 	&utils.AddressRollupFlag,
@@ -193,6 +201,8 @@ var DefaultFlags = []cli.Flag{
 	&utils.L1BlockRangeFlag,
 	&utils.L1QueryDelayFlag,
 	&utils.L1HighestBlockTypeFlag,
+	&utils.L1QuorumFlag,
+	&utils.L1QuorumProvidersFlag,
 	&utils.L1MaticContractAddressFlag,
 	&utils.L1FirstBlockFlag,
 	&utils.L1FinalizedBlockRequirementFlag,
@@ -206,10 +216,27 @@ var DefaultFlags = []cli.Flag{
 	&utils.SmtRegenerateInMemory,
 	&utils.SequencerBlockSealTime,
 	&utils.SequencerBatchSealTime,
+	&utils.SequencerAdaptiveSealTime,
+	&utils.SequencerBlockSealTimeMin,
+	&utils.SequencerBlockSealTimeMax,
+	&utils.SequencerAdaptiveSealPendingTarget,
+	&utils.SequencerPriorityReserve,
+	&utils.SequencerPriorityAddresses,
+	&utils.SequencerPrioritySelectors,
+	&utils.SequencerPriorityAcl,
+	&utils.SequencerPriorityReserveRelease,
 	&utils.SequencerBatchVerificationTimeout,
 	&utils.SequencerBatchVerificationRetries,
 	&utils.SequencerTimeoutOnEmptyTxPool,
 	&utils.SequencerHaltOnBatchNumber,
+	&utils.SequencerForcedBatches,
+	&utils.SequencerForcedBatchTimeout,
+	&utils.SequencerTxOrderingPolicy,
+	&utils.SequencerPreconfirmations,
+	&utils.SequencerPreconfirmationKeyFile,
+	&utils.SequencerLeaseLock,
+	&utils.SequencerLeaseTtl,
+	&utils.SequencerStandby,
 	&utils.SequencerResequence,
 	&utils.SequencerResequenceStrict,
 	&utils.SequencerResequenceReuseL1InfoIndex,
@@ -234,6 +261,17 @@ var DefaultFlags = []cli.Flag{
 	&utils.DataStreamWriteTimeout,
 	&utils.DataStreamInactivityTimeout,
 	&utils.DataStreamInactivityCheckInterval,
+	&utils.DataStreamTLSCert,
+	&utils.DataStreamTLSKey,
+	&utils.DataStreamTLSClientCA,
+	&utils.DataStreamToken,
+	&utils.DataStreamInternalPort,
+	&utils.DataStreamBridgePort,
+	&utils.DataStreamSinkNDJSONDir,
+	&utils.DataStreamSinkNDJSONMaxFileMB,
+	&utils.DataStreamSinkNDJSONMaxFiles,
+	&utils.DataStreamSinkKafkaBrokers,
+	&utils.DataStreamSinkKafkaTopic,
 	&utils.WitnessFullFlag,
 	&utils.SyncLimit,
 	&utils.ExecutorPayloadOutput,
@@ -283,6 +321,8 @@ var DefaultFlags = []cli.Flag{
 	&utils.TxPoolRejectSmartContractDeployments,
 	&utils.DisableVirtualCounters,
 	&utils.DAUrl,
+	&utils.DAVerifySignatures,
+	&utils.L1BeaconUrl,
 	&utils.VirtualCountersSmtReduction,
 	&utils.BadBatches,
 	&utils.InitialBatchCfgFile,
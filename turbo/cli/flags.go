@@ -519,6 +519,17 @@ func setEmbeddedRpcDaemon(ctx *cli.Context, cfg *nodecfg.Config, logger log.Logg
 		DataStreamWriteTimeout:            ctx.Duration(utils.DataStreamWriteTimeout.Name),
 		DataStreamInactivityTimeout:       ctx.Duration(utils.DataStreamInactivityTimeout.Name),
 		DataStreamInactivityCheckInterval: ctx.Duration(utils.DataStreamInactivityCheckInterval.Name),
+		DataStreamTLSCert:                 ctx.String(utils.DataStreamTLSCert.Name),
+		DataStreamTLSKey:                  ctx.String(utils.DataStreamTLSKey.Name),
+		DataStreamTLSClientCA:             ctx.String(utils.DataStreamTLSClientCA.Name),
+		DataStreamToken:                   ctx.String(utils.DataStreamToken.Name),
+		DataStreamInternalPort:            ctx.Uint(utils.DataStreamInternalPort.Name),
+		DataStreamBridgePort:              ctx.Uint(utils.DataStreamBridgePort.Name),
+		DataStreamSinkNDJSONDir:           ctx.String(utils.DataStreamSinkNDJSONDir.Name),
+		DataStreamSinkNDJSONMaxFileMB:     ctx.Uint(utils.DataStreamSinkNDJSONMaxFileMB.Name),
+		DataStreamSinkNDJSONMaxFiles:      ctx.Uint(utils.DataStreamSinkNDJSONMaxFiles.Name),
+		DataStreamSinkKafkaBrokers:        ctx.String(utils.DataStreamSinkKafkaBrokers.Name),
+		DataStreamSinkKafkaTopic:          ctx.String(utils.DataStreamSinkKafkaTopic.Name),
 		L2RpcUrl:                          ctx.String(utils.L2RpcUrlFlag.Name),
 	}
 
@@ -11,6 +11,7 @@ import (
 	"strconv"
 
 	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
 	"github.com/ledgerwatch/erigon/cmd/utils"
 	"github.com/ledgerwatch/erigon/eth/ethconfig"
 	"github.com/ledgerwatch/erigon/zk/sequencer"
@@ -147,11 +148,36 @@ func ApplyFlagsForZkConfig(ctx *cli.Context, cfg *ethconfig.Config) {
 		witnessInclusion = append(witnessInclusion, libcommon.HexToAddress(s))
 	}
 
+	var priorityAddresses []libcommon.Address
+	for _, s := range strings.Split(ctx.String(utils.SequencerPriorityAddresses.Name), ",") {
+		if s == "" {
+			continue
+		}
+		priorityAddresses = append(priorityAddresses, libcommon.HexToAddress(s))
+	}
+	var prioritySelectors [][4]byte
+	for _, s := range strings.Split(ctx.String(utils.SequencerPrioritySelectors.Name), ",") {
+		if s == "" {
+			continue
+		}
+		selector, err := hexutil.Decode(s)
+		if err != nil || len(selector) != 4 {
+			panic(fmt.Sprintf("could not parse priority method selector %s", s))
+		}
+		prioritySelectors = append(prioritySelectors, [4]byte(selector))
+	}
+
 	cfg.Zk = &ethconfig.Zk{
 		L2ChainId:                              ctx.Uint64(utils.L2ChainIdFlag.Name),
 		L2RpcUrl:                               ctx.String(utils.L2RpcUrlFlag.Name),
 		L2DataStreamerUrl:                      ctx.String(utils.L2DataStreamerUrlFlag.Name),
 		L2DataStreamerTimeout:                  l2DataStreamTimeout,
+		L2DataStreamerDecodeWorkers:            ctx.Int(utils.L2DataStreamerDecodeWorkers.Name),
+		L2DataStreamerTLS:                      ctx.Bool(utils.L2DataStreamerTLS.Name),
+		L2DataStreamerTLSCA:                    ctx.String(utils.L2DataStreamerTLSCA.Name),
+		L2DataStreamerTLSCert:                  ctx.String(utils.L2DataStreamerTLSCert.Name),
+		L2DataStreamerTLSKey:                   ctx.String(utils.L2DataStreamerTLSKey.Name),
+		L2DataStreamerToken:                    ctx.String(utils.L2DataStreamerToken.Name),
 		L2ShortCircuitToVerifiedBatch:          l2ShortCircuitToVerifiedBatchVal,
 		L1SyncStartBlock:                       ctx.Uint64(utils.L1SyncStartBlock.Name),
 		L1SyncStopBatch:                        ctx.Uint64(utils.L1SyncStopBatch.Name),
@@ -159,6 +185,8 @@ func ApplyFlagsForZkConfig(ctx *cli.Context, cfg *ethconfig.Config) {
 		L1RpcUrl:                               ctx.String(utils.L1RpcUrlFlag.Name),
 		L1CacheEnabled:                         ctx.Bool(utils.L1CacheEnabledFlag.Name),
 		L1CachePort:                            ctx.Uint(utils.L1CachePortFlag.Name),
+		L1CacheMode:                            ctx.String(utils.L1CacheModeFlag.Name),
+		L1CacheFixture:                         ctx.String(utils.L1CacheFixtureFlag.Name),
 		AddressSequencer:                       libcommon.HexToAddress(ctx.String(utils.AddressSequencerFlag.Name)),
 		AddressAdmin:                           libcommon.HexToAddress(ctx.String(utils.AddressAdminFlag.Name)),
 		AddressRollup:                          libcommon.HexToAddress(ctx.String(utils.AddressRollupFlag.Name)),
@@ -168,6 +196,8 @@ func ApplyFlagsForZkConfig(ctx *cli.Context, cfg *ethconfig.Config) {
 		L1BlockRange:                           ctx.Uint64(utils.L1BlockRangeFlag.Name),
 		L1QueryDelay:                           ctx.Uint64(utils.L1QueryDelayFlag.Name),
 		L1HighestBlockType:                     ctx.String(utils.L1HighestBlockTypeFlag.Name),
+		L1Quorum:                               ctx.Int(utils.L1QuorumFlag.Name),
+		L1QuorumProviders:                      ctx.Int(utils.L1QuorumProvidersFlag.Name),
 		L1MaticContractAddress:                 libcommon.HexToAddress(ctx.String(utils.L1MaticContractAddressFlag.Name)),
 		L1FirstBlock:                           ctx.Uint64(utils.L1FirstBlockFlag.Name),
 		RpcRateLimits:                          ctx.Int(utils.RpcRateLimitsFlag.Name),
@@ -181,10 +211,27 @@ func ApplyFlagsForZkConfig(ctx *cli.Context, cfg *ethconfig.Config) {
 		SmtRegenerateInMemory:                  ctx.Bool(utils.SmtRegenerateInMemory.Name),
 		SequencerBlockSealTime:                 sequencerBlockSealTime,
 		SequencerBatchSealTime:                 sequencerBatchSealTime,
+		SequencerAdaptiveSealTime:              ctx.Bool(utils.SequencerAdaptiveSealTime.Name),
+		SequencerBlockSealTimeMin:              ctx.Duration(utils.SequencerBlockSealTimeMin.Name),
+		SequencerBlockSealTimeMax:              ctx.Duration(utils.SequencerBlockSealTimeMax.Name),
+		SequencerAdaptiveSealPendingTarget:     ctx.Uint64(utils.SequencerAdaptiveSealPendingTarget.Name),
+		SequencerPriorityReserve:               ctx.Float64(utils.SequencerPriorityReserve.Name),
+		SequencerPriorityAddresses:             priorityAddresses,
+		SequencerPrioritySelectors:             prioritySelectors,
+		SequencerPriorityAcl:                   ctx.Bool(utils.SequencerPriorityAcl.Name),
+		SequencerPriorityReserveRelease:        ctx.Duration(utils.SequencerPriorityReserveRelease.Name),
 		SequencerBatchVerificationTimeout:      sequencerBatchVerificationTimeout,
 		SequencerBatchVerificationRetries:      ctx.Int(utils.SequencerBatchVerificationRetries.Name),
 		SequencerTimeoutOnEmptyTxPool:          sequencerTimeoutOnEmptyTxPool,
 		SequencerHaltOnBatchNumber:             ctx.Uint64(utils.SequencerHaltOnBatchNumber.Name),
+		SequencerForcedBatches:                 ctx.Bool(utils.SequencerForcedBatches.Name),
+		SequencerForcedBatchTimeout:            ctx.Duration(utils.SequencerForcedBatchTimeout.Name),
+		SequencerTxOrderingPolicy:              ctx.String(utils.SequencerTxOrderingPolicy.Name),
+		SequencerPreconfirmations:              ctx.Bool(utils.SequencerPreconfirmations.Name),
+		SequencerPreconfirmationKeyFile:        ctx.String(utils.SequencerPreconfirmationKeyFile.Name),
+		SequencerLeaseLock:                     ctx.String(utils.SequencerLeaseLock.Name),
+		SequencerLeaseTtl:                      ctx.Duration(utils.SequencerLeaseTtl.Name),
+		SequencerStandby:                       ctx.Bool(utils.SequencerStandby.Name),
 		SequencerResequence:                    ctx.Bool(utils.SequencerResequence.Name),
 		SequencerResequenceStrict:              ctx.Bool(utils.SequencerResequenceStrict.Name),
 		SequencerResequenceReuseL1InfoIndex:    ctx.Bool(utils.SequencerResequenceReuseL1InfoIndex.Name),
@@ -216,6 +263,8 @@ func ApplyFlagsForZkConfig(ctx *cli.Context, cfg *ethconfig.Config) {
 		DisableVirtualCounters:                 ctx.Bool(utils.DisableVirtualCounters.Name),
 		ExecutorPayloadOutput:                  ctx.String(utils.ExecutorPayloadOutput.Name),
 		DAUrl:                                  ctx.String(utils.DAUrl.Name),
+		DAVerifySignatures:                     ctx.Bool(utils.DAVerifySignatures.Name),
+		L1BeaconUrl:                            ctx.String(utils.L1BeaconUrl.Name),
 		DataStreamHost:                         ctx.String(utils.DataStreamHost.Name),
 		DataStreamPort:                         ctx.Uint(utils.DataStreamPort.Name),
 		DataStreamWriteTimeout:                 ctx.Duration(utils.DataStreamWriteTimeout.Name),
@@ -239,6 +288,11 @@ func ApplyFlagsForZkConfig(ctx *cli.Context, cfg *ethconfig.Config) {
 	if !sequencer.IsSequencer() {
 		checkFlag(utils.L2RpcUrlFlag.Name, cfg.Zk.L2RpcUrl)
 		checkFlag(utils.L2DataStreamerUrlFlag.Name, cfg.L2DataStreamerUrl)
+
+		if cfg.SequencerStandby {
+			checkFlag(utils.SequencerLeaseLock.Name, cfg.SequencerLeaseLock)
+			checkFlag(utils.SequencerLeaseTtl.Name, cfg.SequencerLeaseTtl)
+		}
 	} else {
 		checkFlag(utils.ExecutorUrls.Name, cfg.ExecutorUrls)
 		checkFlag(utils.ExecutorStrictMode.Name, cfg.ExecutorStrictMode)
@@ -246,6 +300,26 @@ func ApplyFlagsForZkConfig(ctx *cli.Context, cfg *ethconfig.Config) {
 		checkFlag(utils.DataStreamPort.Name, cfg.DataStreamPort)
 		checkFlag(utils.DataStreamWriteTimeout.Name, cfg.DataStreamWriteTimeout)
 
+		if cfg.SequencerAdaptiveSealTime {
+			checkFlag(utils.SequencerBlockSealTimeMin.Name, cfg.SequencerBlockSealTimeMin)
+			checkFlag(utils.SequencerAdaptiveSealPendingTarget.Name, cfg.SequencerAdaptiveSealPendingTarget)
+			if cfg.SequencerBlockSealTimeMax < cfg.SequencerBlockSealTimeMin {
+				panic(fmt.Sprintf("%s must not be lower than %s", utils.SequencerBlockSealTimeMax.Name, utils.SequencerBlockSealTimeMin.Name))
+			}
+		}
+
+		if cfg.SequencerPriorityReserve < 0 || cfg.SequencerPriorityReserve >= 1 {
+			panic(fmt.Sprintf("%s must be in interval [0; 1)", utils.SequencerPriorityReserve.Name))
+		}
+
+		if cfg.SequencerLeaseLock != "" {
+			checkFlag(utils.SequencerLeaseTtl.Name, cfg.SequencerLeaseTtl)
+		}
+
+		if cfg.SequencerPreconfirmations {
+			checkFlag(utils.SequencerPreconfirmationKeyFile.Name, cfg.SequencerPreconfirmationKeyFile)
+		}
+
 		if cfg.DeprecatedTxPool.Disable {
 			panic("You need tx-pool in order to run a sequencer. Enable it using txpool.disable: false")
 		}
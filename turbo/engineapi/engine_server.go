@@ -57,6 +57,9 @@ type EngineServer struct {
 	chainRW eth1_chain_reader.ChainReaderWriterEth1
 	lock    sync.Mutex
 	logger  log.Logger
+
+	// zk - further apis served next to the engine api behind the jwt authentication
+	authenticatedApis []rpc.API
 }
 
 const fcuTimeout = 1000 // according to mathematics: 1000 millisecods = 1 second
@@ -76,6 +79,11 @@ func NewEngineServer(logger log.Logger, config *chain.Config, executionService e
 	}
 }
 
+// AddAuthenticatedAPIs adds apis to be served on the jwt authenticated endpoint, it has to be called before Start
+func (e *EngineServer) AddAuthenticatedAPIs(apis ...rpc.API) {
+	e.authenticatedApis = append(e.authenticatedApis, apis...)
+}
+
 func (e *EngineServer) Start(
 	ctx context.Context,
 	httpConfig *httpcfg.HttpCfg,
@@ -107,6 +115,7 @@ func (e *EngineServer) Start(
 			Service:   EngineAPI(e),
 			Version:   "1.0",
 		}}
+	apiList = append(apiList, e.authenticatedApis...)
 
 	if err := cli.StartRpcServerWithJwtAuthentication(ctx, httpConfig, apiList, e.logger); err != nil {
 		e.logger.Error(err.Error())
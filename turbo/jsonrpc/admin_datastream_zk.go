@@ -0,0 +1,30 @@
+package jsonrpc
+
+import (
+	"context"
+
+	"github.com/ledgerwatch/erigon/zk/datastream/server"
+)
+
+// DatastreamAdminAPI the interface for the admin_datastream* RPC commands.  It is only served on the JWT authenticated
+// endpoint of a node serving the datastream behind the auth proxy.
+type DatastreamAdminAPI interface {
+	// DatastreamClients returns the clients of the datastream with how far behind the stream each one is.
+	DatastreamClients(ctx context.Context) ([]server.DatastreamClient, error)
+}
+
+// DatastreamAdminAPIImpl data structure to store things needed for admin_datastream* commands.
+type DatastreamAdminAPIImpl struct {
+	clients *server.ClientTracker
+}
+
+// NewDatastreamAdminAPI returns DatastreamAdminAPIImpl instance.
+func NewDatastreamAdminAPI(clients *server.ClientTracker) *DatastreamAdminAPIImpl {
+	return &DatastreamAdminAPIImpl{
+		clients: clients,
+	}
+}
+
+func (api *DatastreamAdminAPIImpl) DatastreamClients(ctx context.Context) ([]server.DatastreamClient, error) {
+	return api.clients.Clients()
+}
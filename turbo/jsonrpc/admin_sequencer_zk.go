@@ -0,0 +1,60 @@
+package jsonrpc
+
+import (
+	"context"
+
+	"github.com/ledgerwatch/erigon/zk/sequencer"
+)
+
+// SequencerAdminAPI the interface for the admin_sequencer* RPC commands.  It is only served on the JWT authenticated
+// endpoint of a sequencer.
+type SequencerAdminAPI interface {
+	// SequencerPause stops the sequencer after the current block, the open batch is kept open.
+	SequencerPause(ctx context.Context) (sequencer.ControlStatus, error)
+
+	// SequencerResume lets a paused or drained sequencer carry on producing blocks.
+	SequencerResume(ctx context.Context) (sequencer.ControlStatus, error)
+
+	// SequencerDrain closes the current batch cleanly, seals it in the datastream and stops the sequencer.
+	SequencerDrain(ctx context.Context) (sequencer.ControlStatus, error)
+
+	// SequencerStatus returns the halt state of the sequencer.
+	SequencerStatus(ctx context.Context) (sequencer.ControlStatus, error)
+}
+
+// SequencerAdminAPIImpl data structure to store things needed for admin_sequencer* commands.
+type SequencerAdminAPIImpl struct {
+	control *sequencer.Control
+}
+
+// NewSequencerAdminAPI returns SequencerAdminAPIImpl instance.
+func NewSequencerAdminAPI(control *sequencer.Control) *SequencerAdminAPIImpl {
+	return &SequencerAdminAPIImpl{
+		control: control,
+	}
+}
+
+func (api *SequencerAdminAPIImpl) SequencerPause(ctx context.Context) (sequencer.ControlStatus, error) {
+	if err := api.control.Pause(); err != nil {
+		return sequencer.ControlStatus{}, err
+	}
+	return api.control.Status(), nil
+}
+
+func (api *SequencerAdminAPIImpl) SequencerResume(ctx context.Context) (sequencer.ControlStatus, error) {
+	if err := api.control.Resume(); err != nil {
+		return sequencer.ControlStatus{}, err
+	}
+	return api.control.Status(), nil
+}
+
+func (api *SequencerAdminAPIImpl) SequencerDrain(ctx context.Context) (sequencer.ControlStatus, error) {
+	if err := api.control.Drain(); err != nil {
+		return sequencer.ControlStatus{}, err
+	}
+	return api.control.Status(), nil
+}
+
+func (api *SequencerAdminAPIImpl) SequencerStatus(ctx context.Context) (sequencer.ControlStatus, error) {
+	return api.control.Status(), nil
+}
@@ -41,6 +41,7 @@ import (
 	"github.com/ledgerwatch/erigon/turbo/rpchelper"
 	"github.com/ledgerwatch/erigon/turbo/services"
 	"github.com/ledgerwatch/erigon/zk/hermez_db"
+	zktxpool "github.com/ledgerwatch/erigon/zk/txpool"
 	"github.com/ledgerwatch/erigon/zk/utils"
 )
 
@@ -97,6 +98,8 @@ type EthAPI interface {
 	Call(ctx context.Context, args ethapi2.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *ethapi2.StateOverrides) (hexutility.Bytes, error)
 	EstimateGas(ctx context.Context, argsOrNil *ethapi2.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash) (hexutil.Uint64, error)
 	SendRawTransaction(ctx context.Context, encodedTx hexutility.Bytes) (common.Hash, error)
+	SendBundle(ctx context.Context, args SendBundleArgs) (*SendBundleResult, error)
+	SendRawTransactionConditional(ctx context.Context, encodedTx hexutility.Bytes, conditions TransactionConditions) (common.Hash, error)
 	SendTransaction(_ context.Context, txObject interface{}) (common.Hash, error)
 	Sign(ctx context.Context, _ common.Address, _ hexutility.Bytes) (hexutility.Bytes, error)
 	SignTransaction(_ context.Context, txObject interface{}) (common.Hash, error)
@@ -377,6 +380,7 @@ type APIImpl struct {
 	SubscribeLogsChannelSize    int
 	logger                      log.Logger
 	VirtualCountersSmtReduction float64
+	rawPool                     *zktxpool.TxPool
 
 	// For X Layer
 	L2GasPricer   gasprice.L2GasPricer
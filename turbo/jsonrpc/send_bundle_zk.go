@@ -0,0 +1,131 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon-lib/chain"
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/hexutility"
+
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/zk/txpool"
+	"github.com/ledgerwatch/erigon/zk/utils"
+	"github.com/ledgerwatch/erigon/zkevm/jsonrpc/client"
+)
+
+type SendBundleArgs struct {
+	Txs []hexutility.Bytes `json:"txs"`
+}
+
+type SendBundleResult struct {
+	BundleHash common.Hash `json:"bundleHash"`
+}
+
+func (api *APIImpl) SetRawPool(rawPool *txpool.TxPool) {
+	api.rawPool = rawPool
+}
+
+// SendBundle implements eth_sendBundle. Adds previously-signed transactions to the pool as a bundle which the sequencer
+// includes in the same block and in the given order, or not at all.
+func (api *APIImpl) SendBundle(ctx context.Context, args SendBundleArgs) (*SendBundleResult, error) {
+	t := utils.StartTimer("rpc", "sendbundle")
+	defer t.LogTimer()
+
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	cc, err := api.chainConfig(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	chainId := cc.ChainID
+
+	// [zkevm] - bundles are only ever held by the sequencer's pool
+	if api.isZkNonSequencer(chainId) {
+		return api.sendBundleZk(api.l2RpcUrl, args)
+	}
+
+	if api.rawPool == nil {
+		return nil, errors.New("bundles are not supported without a local txpool")
+	}
+	if len(args.Txs) == 0 {
+		return nil, txpool.ErrBundleEmpty
+	}
+
+	latestBlock, err := api.blockByNumber(ctx, rpc.LatestBlockNumber, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	rlpTxs := make([][]byte, len(args.Txs))
+	for i, encodedTx := range args.Txs {
+		txn, err := types.DecodeWrappedTransaction(encodedTx)
+		if err != nil {
+			return nil, fmt.Errorf("bundle transaction %d: %w", i, err)
+		}
+
+		if err := api.checkRawPoolTransaction(cc, latestBlock.NumberU64(), txn); err != nil {
+			return nil, fmt.Errorf("bundle transaction %d: %w", i, err)
+		}
+
+		rlpTxs[i] = encodedTx
+	}
+
+	bundleHash, err := api.rawPool.AddLocalBundle(ctx, rlpTxs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SendBundleResult{BundleHash: bundleHash}, nil
+}
+
+// checkRawPoolTransaction runs the checks of eth_sendRawTransaction on a transaction added straight to the local pool
+func (api *APIImpl) checkRawPoolTransaction(cc *chain.Config, latestBlock uint64, txn types.Transaction) error {
+	if txn.Type() != types.LegacyTxType {
+		if !cc.IsLondon(latestBlock) {
+			return errors.New("only legacy transactions are supported")
+		}
+		if txn.Type() == types.BlobTxType {
+			return errors.New("blob transactions are not supported")
+		}
+	}
+
+	if err := checkTxFee(txn.GetPrice().ToBig(), txn.GetGas(), api.FeeCap); err != nil {
+		return err
+	}
+	if !api.AllowPreEIP155Transactions && !txn.Protected() && !api.AllowUnprotectedTxs {
+		return errors.New("only replay-protected (EIP-155) transactions allowed over RPC")
+	}
+	if txn.Protected() {
+		txnChainId := txn.GetChainID()
+		if cc.ChainID.Cmp(txnChainId.ToBig()) != 0 {
+			return fmt.Errorf("invalid chain id, expected: %d got: %d", cc.ChainID, *txnChainId)
+		}
+	}
+
+	return nil
+}
+
+func (api *APIImpl) sendBundleZk(rpcUrl string, args SendBundleArgs) (*SendBundleResult, error) {
+	res, err := client.JSONRPCCall(rpcUrl, "eth_sendBundle", args)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Error != nil {
+		return nil, fmt.Errorf("RPC error response: %s", res.Error.Message)
+	}
+
+	var result SendBundleResult
+	if err := json.Unmarshal(res.Result, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,123 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
+	"github.com/ledgerwatch/erigon-lib/common/hexutility"
+
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/zk/txpool"
+	"github.com/ledgerwatch/erigon/zk/utils"
+	"github.com/ledgerwatch/erigon/zkevm/jsonrpc/client"
+)
+
+// TransactionConditions are the conditions of eth_sendRawTransactionConditional.  Only the upper bounds are supported,
+// the transaction is never included after either of them.
+type TransactionConditions struct {
+	BlockNumberMax *hexutil.Uint64 `json:"blockNumberMax,omitempty"`
+	TimestampMax   *hexutil.Uint64 `json:"timestampMax,omitempty"`
+
+	// rejected rather than ignored, a caller relying on them must not have the transaction included regardless
+	BlockNumberMin json.RawMessage `json:"blockNumberMin,omitempty"`
+	TimestampMin   json.RawMessage `json:"timestampMin,omitempty"`
+	KnownAccounts  json.RawMessage `json:"knownAccounts,omitempty"`
+}
+
+func (c TransactionConditions) deadline() (txpool.Deadline, error) {
+	if conditionSet(c.BlockNumberMin) || conditionSet(c.TimestampMin) || conditionSet(c.KnownAccounts) {
+		return txpool.Deadline{}, errors.New("only the blockNumberMax and timestampMax conditions are supported")
+	}
+	if c.BlockNumberMax == nil && c.TimestampMax == nil {
+		return txpool.Deadline{}, errors.New("either blockNumberMax or timestampMax has to be set")
+	}
+
+	// a zero deadline means none to the pool, it would never expire
+	var deadline txpool.Deadline
+	if c.BlockNumberMax != nil {
+		if *c.BlockNumberMax == 0 {
+			return txpool.Deadline{}, errors.New("blockNumberMax has to be greater than 0")
+		}
+		deadline.BlockNumber = uint64(*c.BlockNumberMax)
+	}
+	if c.TimestampMax != nil {
+		if *c.TimestampMax == 0 {
+			return txpool.Deadline{}, errors.New("timestampMax has to be greater than 0")
+		}
+		deadline.Timestamp = uint64(*c.TimestampMax)
+	}
+	return deadline, nil
+}
+
+func conditionSet(raw json.RawMessage) bool {
+	return len(raw) > 0 && string(raw) != "null"
+}
+
+// SendRawTransactionConditional implements eth_sendRawTransactionConditional. Adds a previously-signed transaction to
+// the pool that the sequencer only includes up to the given block number and timestamp, it is discarded after that.
+func (api *APIImpl) SendRawTransactionConditional(ctx context.Context, encodedTx hexutility.Bytes, conditions TransactionConditions) (common.Hash, error) {
+	t := utils.StartTimer("rpc", "sendrawtransactionconditional")
+	defer t.LogTimer()
+
+	deadline, err := conditions.deadline()
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	defer tx.Rollback()
+	cc, err := api.chainConfig(ctx, tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	// [zkevm] - deadlines are only ever held by the sequencer's pool
+	if api.isZkNonSequencer(cc.ChainID) {
+		return api.sendRawTransactionConditionalZk(api.l2RpcUrl, encodedTx, conditions)
+	}
+
+	if api.rawPool == nil {
+		return common.Hash{}, errors.New("conditional transactions are not supported without a local txpool")
+	}
+
+	txn, err := types.DecodeWrappedTransaction(encodedTx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	latestBlock, err := api.blockByNumber(ctx, rpc.LatestBlockNumber, tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := api.checkRawPoolTransaction(cc, latestBlock.NumberU64(), txn); err != nil {
+		return common.Hash{}, err
+	}
+
+	return api.rawPool.AddLocalTxWithDeadline(ctx, encodedTx, deadline)
+}
+
+func (api *APIImpl) sendRawTransactionConditionalZk(rpcUrl string, encodedTx hexutility.Bytes, conditions TransactionConditions) (common.Hash, error) {
+	res, err := client.JSONRPCCall(rpcUrl, "eth_sendRawTransactionConditional", encodedTx, conditions)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	if res.Error != nil {
+		return common.Hash{}, fmt.Errorf("RPC error response: %s", res.Error.Message)
+	}
+
+	var result common.Hash
+	if err := json.Unmarshal(res.Result, &result); err != nil {
+		return common.Hash{}, err
+	}
+
+	return result, nil
+}
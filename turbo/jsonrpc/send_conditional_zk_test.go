@@ -0,0 +1,37 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/zk/txpool"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionConditionsDeadline(t *testing.T) {
+	parse := func(s string) (txpool.Deadline, error) {
+		var conditions TransactionConditions
+		require.NoError(t, json.Unmarshal([]byte(s), &conditions))
+		return conditions.deadline()
+	}
+
+	deadline, err := parse(`{"blockNumberMax":"0x10","timestampMax":"0x6553f100"}`)
+	require.NoError(t, err)
+	require.Equal(t, txpool.Deadline{BlockNumber: 16, Timestamp: 1700000000}, deadline)
+
+	deadline, err = parse(`{"blockNumberMax":"0x10","knownAccounts":null}`)
+	require.NoError(t, err)
+	require.Equal(t, txpool.Deadline{BlockNumber: 16}, deadline)
+
+	_, err = parse(`{}`)
+	require.Error(t, err)
+
+	_, err = parse(`{"blockNumberMax":"0x0"}`)
+	require.Error(t, err)
+
+	_, err = parse(`{"blockNumberMax":"0x10","timestampMax":"0x0"}`)
+	require.Error(t, err)
+
+	_, err = parse(`{"timestampMax":"0x6553f100","knownAccounts":{"0x5fbdb2315678afecb367f032d93f642f64180aa3":{}}}`)
+	require.Error(t, err)
+}
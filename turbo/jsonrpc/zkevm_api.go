@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/ledgerwatch/erigon-lib/chain"
 	"github.com/ledgerwatch/erigon-lib/common"
@@ -81,6 +82,9 @@ type ZkEvmAPI interface {
 	GetRollupAddress(ctx context.Context) (res json.RawMessage, err error)
 	GetRollupManagerAddress(ctx context.Context) (res json.RawMessage, err error)
 	GetLatestDataStreamBlock(ctx context.Context) (hexutil.Uint64, error)
+	GetForcedBatch(ctx context.Context, forcedBatchNumber hexutil.Uint64) (json.RawMessage, error)
+	GetBatchCloseInfo(ctx context.Context, batchNumber rpc.BlockNumber) (json.RawMessage, error)
+	GetPreconfirmation(ctx context.Context, txHash common.Hash) (json.RawMessage, error)
 }
 
 const getBatchWitness = "getBatchWitness"
@@ -96,6 +100,8 @@ type ZkEvmAPIImpl struct {
 	l2SequencerUrl   string
 	semaphores       map[string]chan struct{}
 	datastreamServer server.DataStreamServer
+	// only set on a sequencer with pre-confirmations enabled
+	preconfirmer *sequencer.Preconfirmer
 }
 
 func (api *ZkEvmAPIImpl) initializeSemaphores(functionLimits map[string]int) {
@@ -619,6 +625,24 @@ func (api *ZkEvmAPIImpl) GetBatchByNumber(ctx context.Context, rpcBatchNumber rp
 	}
 	batch.LocalExitRoot = localExitRoot
 
+	forcedBatchNo, isForced, err := hermezDb.GetBatchForcedBatch(batchNo)
+	if err != nil {
+		return nil, err
+	}
+	if isForced {
+		forced := types.ArgUint64(forcedBatchNo)
+		batch.ForcedBatchNumber = &forced
+	}
+
+	// only known to the sequencer that closed the batch
+	closeInfo, err := hermezDb.GetBatchCloseInfo(batchNo)
+	if err != nil {
+		return nil, err
+	}
+	if closeInfo != nil {
+		batch.CloseInfo = toRpcBatchCloseInfo(batchNo, closeInfo)
+	}
+
 	batchL2Data, err := api.getOrCalcBatchData(ctx, tx, hermezDb, batchNo)
 	if err != nil {
 		return nil, err
@@ -1235,12 +1259,28 @@ func (api *ZkEvmAPIImpl) GetProverInput(ctx context.Context, batchNumber uint64,
 
 	timestampLimit := lastBlock.Time()
 
+	// forced batches are bound to the L1 block hash they were forced with
+	forcedBlockHashL1 := ""
+	forcedBatchNo, isForced, err := hDb.GetBatchForcedBatch(batchNumber)
+	if err != nil {
+		return nil, err
+	}
+	if isForced {
+		forcedBatch, err := hDb.GetL1ForcedBatch(forcedBatchNo)
+		if err != nil {
+			return nil, err
+		}
+		if forcedBatch != nil {
+			forcedBlockHashL1 = forcedBatch.ForcedBlockHashL1.String()
+		}
+	}
+
 	return &legacy_executor_verifier.RpcPayload{
 		Witness:           hex.EncodeToHex(rangeWitness),
 		Coinbase:          api.config.AddressSequencer.String(),
 		OldAccInputHash:   oldAccInputHash.String(),
 		TimestampLimit:    timestampLimit,
-		ForcedBlockhashL1: "",
+		ForcedBlockhashL1: forcedBlockHashL1,
 	}, nil
 }
 
@@ -1593,6 +1633,9 @@ func populateBatchDetails(batch *types.Batch) (json.RawMessage, error) {
 	if batch.ForcedBatchNumber != nil {
 		jBatch["forcedBatchNumber"] = batch.ForcedBatchNumber
 	}
+	if batch.CloseInfo != nil {
+		jBatch["closeInfo"] = batch.CloseInfo
+	}
 	jBatch["closed"] = batch.Closed
 	jBatch["batchL2Data"] = batch.BatchL2Data
 
@@ -1961,3 +2004,100 @@ func (api *ZkEvmAPIImpl) GetLatestDataStreamBlock(ctx context.Context) (hexutil.
 
 	return hexutil.Uint64(latestBlock), nil
 }
+
+// GetForcedBatch returns a forced batch posted to the L1 and the batch it was included in, if any
+func (api *ZkEvmAPIImpl) GetForcedBatch(ctx context.Context, forcedBatchNumber hexutil.Uint64) (json.RawMessage, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	hermezDb := hermez_db.NewHermezDbReader(tx)
+
+	forcedBatch, err := hermezDb.GetL1ForcedBatch(uint64(forcedBatchNumber))
+	if err != nil {
+		return nil, err
+	}
+	if forcedBatch == nil {
+		return nil, nil
+	}
+
+	result := types.ForcedBatch{
+		ForcedBatchNumber: types.ArgUint64(forcedBatch.ForcedBatchNumber),
+		L1BlockNumber:     types.ArgUint64(forcedBatch.L1BlockNumber),
+		L1TxHash:          forcedBatch.L1TxHash,
+		ForcedAt:          types.ArgUint64(forcedBatch.ForcedAt),
+		GlobalExitRoot:    forcedBatch.LastGlobalExitRoot,
+		ForcedBlockHashL1: forcedBatch.ForcedBlockHashL1,
+		Sequencer:         forcedBatch.Sequencer,
+		BatchL2Data:       forcedBatch.Transactions,
+	}
+
+	batchNo, found, err := hermezDb.GetBatchByForcedBatch(forcedBatch.ForcedBatchNumber)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		included := types.ArgUint64(batchNo)
+		result.BatchNumber = &included
+	}
+
+	return json.Marshal(result)
+}
+
+// GetBatchCloseInfo returns why and how full the sequencer closed a batch.  Nodes other than the sequencer forward the
+// request to it as the information isn't part of the datastream.
+func (api *ZkEvmAPIImpl) GetBatchCloseInfo(ctx context.Context, batchNumber rpc.BlockNumber) (json.RawMessage, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	batchNo, _, err := rpchelper.GetBatchNumber(batchNumber, tx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	hermezDb := hermez_db.NewHermezDbReader(tx)
+	closeInfo, err := hermezDb.GetBatchCloseInfo(batchNo)
+	if err != nil {
+		return nil, err
+	}
+
+	if closeInfo == nil {
+		if !sequencer.IsSequencer() && api.l2SequencerUrl != "" {
+			return api.sendGetBatchCloseInfo(api.l2SequencerUrl, batchNo)
+		}
+		return nil, nil
+	}
+
+	return json.Marshal(toRpcBatchCloseInfo(batchNo, closeInfo))
+}
+
+func (api *ZkEvmAPIImpl) sendGetBatchCloseInfo(rpcUrl string, batchNumber uint64) (json.RawMessage, error) {
+	res, err := client.JSONRPCCall(rpcUrl, "zkevm_getBatchCloseInfo", hexutil.Uint64(batchNumber))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Error != nil {
+		return nil, fmt.Errorf("RPC error response: %s", res.Error.Message)
+	}
+
+	return res.Result, nil
+}
+
+func toRpcBatchCloseInfo(batchNo uint64, closeInfo *zktypes.BatchCloseInfo) *types.BatchCloseInfo {
+	result := &types.BatchCloseInfo{
+		BatchNumber:      types.ArgUint64(batchNo),
+		Reason:           closeInfo.Reason,
+		BlockCount:       types.ArgUint64(closeInfo.BlockCount),
+		TransactionCount: types.ArgUint64(closeInfo.TransactionCount),
+	}
+	if closeInfo.OverflowCounters != "" {
+		result.OverflowCounters = strings.Split(closeInfo.OverflowCounters, ",")
+	}
+	return result
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/ledgerwatch/erigon/zk/erigon_db"
 	"github.com/ledgerwatch/erigon/zk/hermez_db"
 	rpctypes "github.com/ledgerwatch/erigon/zk/rpcdaemon"
+	"github.com/ledgerwatch/erigon/zk/sequencer"
 	"github.com/ledgerwatch/erigon/zk/syncer"
 	"github.com/ledgerwatch/erigon/zk/syncer/mocks"
 	zktypes "github.com/ledgerwatch/erigon/zk/types"
@@ -1522,3 +1523,33 @@ func TestGetRollupManagerAddress(t *testing.T) {
 	assert.NoError(err)
 	assert.Equal(result, common.HexToAddress("0x1"))
 }
+
+func TestGetPreconfirmation(t *testing.T) {
+	t.Setenv(sequencer.SEQUENCER_ENV_KEY, "1")
+	ctx := context.Background()
+	assert := assert.New(t)
+
+	txHash := common.HexToHash("0x1")
+
+	// pre-confirmations are disabled without a preconfirmer
+	zkEvmImpl := &ZkEvmAPIImpl{}
+	_, err := zkEvmImpl.GetPreconfirmation(ctx, txHash)
+	assert.ErrorIs(err, errPreconfirmationsDisabled)
+
+	zkEvmImpl.preconfirmer = sequencer.NewPreconfirmer(key, 1, 10)
+	res, err := zkEvmImpl.GetPreconfirmation(ctx, txHash)
+	assert.NoError(err)
+	assert.Nil(res)
+
+	assert.NoError(zkEvmImpl.preconfirmer.Preconfirm(txHash, 2, 5, 0, 1, 21000))
+	res, err = zkEvmImpl.GetPreconfirmation(ctx, txHash)
+	assert.NoError(err)
+
+	var preconf sequencer.Preconfirmation
+	assert.NoError(json.Unmarshal(res, &preconf))
+	assert.Equal(txHash, preconf.TxHash)
+	assert.Equal(hexutil.Uint64(5), preconf.BlockNumber)
+	signer, err := preconf.Signer()
+	assert.NoError(err)
+	assert.Equal(address, signer)
+}
@@ -0,0 +1,150 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon/common/debug"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/zk/sequencer"
+	"github.com/ledgerwatch/erigon/zkevm/jsonrpc/client"
+)
+
+var errPreconfirmationsDisabled = errors.New("pre-confirmations are not enabled on the sequencer")
+
+// GetPreconfirmation returns the signed pre-confirmation of a transaction the sequencer executed in its open block, null
+// once it has been forgotten or if there never was one.  Nodes other than the sequencer forward the request to it.
+func (api *ZkEvmAPIImpl) GetPreconfirmation(ctx context.Context, txHash common.Hash) (json.RawMessage, error) {
+	if !sequencer.IsSequencer() {
+		if api.l2SequencerUrl == "" {
+			return nil, errPreconfirmationsDisabled
+		}
+		return api.sendGetPreconfirmation(api.l2SequencerUrl, txHash)
+	}
+
+	if api.preconfirmer == nil {
+		return nil, errPreconfirmationsDisabled
+	}
+
+	preconf := api.preconfirmer.Get(txHash)
+	if preconf == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(preconf)
+}
+
+func (api *ZkEvmAPIImpl) sendGetPreconfirmation(rpcUrl string, txHash common.Hash) (json.RawMessage, error) {
+	res, err := client.JSONRPCCall(rpcUrl, "zkevm_getPreconfirmation", txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Error != nil {
+		return nil, fmt.Errorf("RPC error response: %s", res.Error.Message)
+	}
+
+	return res.Result, nil
+}
+
+// Preconfirmations sends a notification for every pre-confirmation of the sequencer, websocket only.  Nodes other
+// than the sequencer relay the subscription of the sequencer which needs their sequencer url to be a websocket one.
+func (api *ZkEvmAPIImpl) Preconfirmations(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	var preconfs <-chan *sequencer.Preconfirmation
+	var unsubscribe func()
+	if sequencer.IsSequencer() {
+		if api.preconfirmer == nil {
+			return &rpc.Subscription{}, errPreconfirmationsDisabled
+		}
+		preconfs, unsubscribe = api.preconfirmer.Subscribe(128)
+	} else {
+		var err error
+		if preconfs, unsubscribe, err = api.subscribeSequencerPreconfirmations(); err != nil {
+			return &rpc.Subscription{}, err
+		}
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer debug.LogPanic()
+		defer unsubscribe()
+		for {
+			select {
+			case preconf, ok := <-preconfs:
+				if !ok {
+					log.Warn("[rpc] pre-confirmations channel was closed")
+					return
+				}
+				if err := notifier.Notify(rpcSub.ID, preconf); err != nil {
+					log.Warn("[rpc] error while notifying subscription", "err", err)
+				}
+			case <-rpcSub.Err():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// subscribeSequencerPreconfirmations relays the pre-confirmations subscription of the sequencer
+func (api *ZkEvmAPIImpl) subscribeSequencerPreconfirmations() (<-chan *sequencer.Preconfirmation, func(), error) {
+	if api.l2SequencerUrl == "" {
+		return nil, nil, errPreconfirmationsDisabled
+	}
+
+	// the relay lives as long as the subscription, not the request that created it
+	relayCtx, cancel := context.WithCancel(context.Background())
+	c, err := rpc.DialContext(relayCtx, api.l2SequencerUrl, log.Root())
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	preconfs := make(chan *sequencer.Preconfirmation, 128)
+	sub, err := c.Subscribe(relayCtx, "zkevm", preconfs, "preconfirmations")
+	if err != nil {
+		c.Close()
+		cancel()
+		return nil, nil, fmt.Errorf("failed to subscribe to the sequencer pre-confirmations: %w", err)
+	}
+
+	relayed := make(chan *sequencer.Preconfirmation, 128)
+	go func() {
+		defer close(relayed)
+		for {
+			select {
+			case preconf := <-preconfs:
+				select {
+				case relayed <- preconf:
+				case <-relayCtx.Done():
+					return
+				}
+			case err := <-sub.Err():
+				if err != nil {
+					log.Warn("[rpc] sequencer pre-confirmations subscription failed", "err", err)
+				}
+				return
+			case <-relayCtx.Done():
+				return
+			}
+		}
+	}()
+
+	return relayed, func() {
+		cancel()
+		sub.Unsubscribe()
+		c.Close()
+	}, nil
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/ledgerwatch/erigon/zk/datastream/server"
 	"github.com/ledgerwatch/erigon/zk/l1infotree"
 	"github.com/ledgerwatch/erigon/zk/legacy_executor_verifier"
+	"github.com/ledgerwatch/erigon/zk/sequencer"
 	zkStages "github.com/ledgerwatch/erigon/zk/stages"
 	"github.com/ledgerwatch/erigon/zk/syncer"
 	"github.com/ledgerwatch/erigon/zk/txpool"
@@ -108,6 +109,9 @@ func NewSequencerZkStages(ctx context.Context,
 	txPoolDb kv.RwDB,
 	verifier *legacy_executor_verifier.LegacyExecutorVerifier,
 	infoTreeUpdater *l1infotree.Updater,
+	sequencerControl *sequencer.Control,
+	preconfirmer *sequencer.Preconfirmer,
+	sequencerLease *sequencer.Lease,
 ) []*stagedsync.Stage {
 	dirs := cfg.Dirs
 	blockReader := freezeblocks.NewBlockReader(snapshots, nil)
@@ -147,6 +151,9 @@ func NewSequencerZkStages(ctx context.Context,
 			verifier,
 			uint16(cfg.YieldSize),
 			infoTreeUpdater,
+			sequencerControl,
+			preconfirmer,
+			sequencerLease,
 		),
 		stagedsync.StageHashStateCfg(db, dirs, cfg.HistoryV3, agg),
 		zkStages.StageZkInterHashesCfg(db, true, true, false, dirs.Tmp, blockReader, controlServer.Hd, cfg.HistoryV3, agg, cfg.Zk),
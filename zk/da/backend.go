@@ -0,0 +1,95 @@
+package da
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/log/v3"
+)
+
+const fileScheme = "file://"
+
+// ErrOffChainDataMismatch is returned by a backend serving data that doesn't hash to the hash it was asked for
+var ErrOffChainDataMismatch = errors.New("off chain data doesn't match its hash")
+
+// DABackend serves the batch data of validium sequences by the hash of it the sequences commit to on the L1.  Every
+// backend checks the data it serves against the hash
+type DABackend interface {
+	GetOffChainData(ctx context.Context, hash common.Hash) ([]byte, error)
+}
+
+func checkOffChainData(hash common.Hash, data []byte) error {
+	if actual := crypto.Keccak256Hash(data); actual != hash {
+		return fmt.Errorf("%w %s, got data hashing to %s", ErrOffChainDataMismatch, hash, actual)
+	}
+	return nil
+}
+
+// NewBackend builds the backend of a data availability URL.  That is a comma separated list of the members of the data
+// availability committee, tried in turn, each the URL of a DAC node or a file:// directory of a FileBackend
+func NewBackend(url string) DABackend {
+	var members []DABackend
+	for _, member := range strings.Split(url, ",") {
+		member = strings.TrimSpace(member)
+		switch {
+		case member == "":
+		case strings.HasPrefix(member, fileScheme):
+			members = append(members, NewFileBackend(strings.TrimPrefix(member, fileScheme)))
+		default:
+			members = append(members, NewDACClient(member))
+		}
+	}
+
+	if len(members) == 1 {
+		return members[0]
+	}
+	return NewMultiBackend(members...)
+}
+
+// MultiBackend asks the members of a data availability committee for the data in turn, until one of them serves it.
+// It starts from the member that last served data, so that a member that is down isn't asked first every time
+type MultiBackend struct {
+	members []DABackend
+
+	mu   sync.Mutex
+	next int
+}
+
+func NewMultiBackend(members ...DABackend) *MultiBackend {
+	return &MultiBackend{
+		members: members,
+	}
+}
+
+func (m *MultiBackend) GetOffChainData(ctx context.Context, hash common.Hash) ([]byte, error) {
+	m.mu.Lock()
+	start := m.next
+	m.mu.Unlock()
+
+	if len(m.members) == 0 {
+		return nil, errors.New("no data availability committee members")
+	}
+
+	var errs []error
+	for i := range m.members {
+		member := (start + i) % len(m.members)
+		data, err := m.members[member].GetOffChainData(ctx, hash)
+		if err != nil {
+			log.Debug("Data availability committee member failed to serve off chain data", "member", member, "hash", hash, "err", err)
+			errs = append(errs, fmt.Errorf("member %d: %w", member, err))
+			continue
+		}
+
+		m.mu.Lock()
+		m.next = member
+		m.mu.Unlock()
+		return data, nil
+	}
+
+	return nil, fmt.Errorf("no data availability committee member served off chain data %s: %w", hash, errors.Join(errs...))
+}
@@ -0,0 +1,54 @@
+package da
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileBackend(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "archive")
+	backend := NewFileBackend(dir)
+
+	hash, err := backend.Put([]byte("offchaindata"))
+	require.NoError(t, err)
+	require.Equal(t, crypto.Keccak256Hash([]byte("offchaindata")), hash)
+	data, err := backend.GetOffChainData(context.Background(), hash)
+	require.NoError(t, err)
+	require.Equal(t, []byte("offchaindata"), data)
+
+	_, err = backend.GetOffChainData(context.Background(), common.Hash{1})
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, hash.Hex()), []byte("tampered"), 0644))
+	_, err = backend.GetOffChainData(context.Background(), hash)
+	require.ErrorIs(t, err, ErrOffChainDataMismatch)
+}
+
+func TestMultiBackend(t *testing.T) {
+	dirs := []string{t.TempDir(), t.TempDir(), t.TempDir()}
+	good, err := NewFileBackend(dirs[2]).Put([]byte("offchaindata"))
+	require.NoError(t, err)
+	// the first member is missing the data, the second serves something else for it
+	require.NoError(t, os.WriteFile(filepath.Join(dirs[1], good.Hex()), []byte("tampered"), 0644))
+
+	backend := NewBackend("file://" + dirs[0] + ", file://" + dirs[1] + ",file://" + dirs[2])
+	require.IsType(t, &MultiBackend{}, backend)
+	multi := backend.(*MultiBackend)
+	data, err := multi.GetOffChainData(context.Background(), good)
+	require.NoError(t, err)
+	require.Equal(t, []byte("offchaindata"), data)
+	require.Equal(t, 2, multi.next)
+
+	_, err = multi.GetOffChainData(context.Background(), common.Hash{1})
+	require.ErrorContains(t, err, "no data availability committee member served off chain data")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	require.IsType(t, &FileBackend{}, NewBackend("file://"+dirs[0]))
+	require.IsType(t, &DACClient{}, NewBackend("http://localhost:8444"))
+}
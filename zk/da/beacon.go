@@ -0,0 +1,155 @@
+package da
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
+	libkzg "github.com/ledgerwatch/erigon-lib/crypto/kzg"
+)
+
+const beaconRequestTimeout = 30 * time.Second
+
+// BlobSidecar is a blob along with its KZG commitment and proof, as served by the beacon API
+type BlobSidecar struct {
+	Index         string        `json:"index"`
+	Blob          hexutil.Bytes `json:"blob"`
+	KZGCommitment hexutil.Bytes `json:"kzg_commitment"`
+	KZGProof      hexutil.Bytes `json:"kzg_proof"`
+}
+
+// BeaconClient fetches the blobs carried by the transactions of an L1 block from the blob sidecars served by the
+// beacon API of a consensus node.  The slot of a block is worked out from its timestamp, with the genesis time and slot
+// time of the beacon chain fetched on first use
+type BeaconClient struct {
+	url    string
+	client *http.Client
+
+	mu             sync.Mutex
+	genesisTime    uint64
+	secondsPerSlot uint64
+}
+
+func NewBeaconClient(url string) *BeaconClient {
+	return &BeaconClient{
+		url:    strings.TrimSuffix(url, "/"),
+		client: &http.Client{Timeout: beaconRequestTimeout},
+	}
+}
+
+// GetBlobs returns the blobs with the given versioned hashes, in the same order, from the L1 block with the given
+// timestamp.  Each blob is checked against its KZG commitment and the commitment against its versioned hash
+func (c *BeaconClient) GetBlobs(ctx context.Context, l1BlockTime uint64, versionedHashes []common.Hash) ([][]byte, error) {
+	slot, err := c.slotAt(ctx, l1BlockTime)
+	if err != nil {
+		return nil, err
+	}
+
+	var sidecars struct {
+		Data []BlobSidecar `json:"data"`
+	}
+	if err = c.get(ctx, fmt.Sprintf("/eth/v1/beacon/blob_sidecars/%d", slot), &sidecars); err != nil {
+		return nil, err
+	}
+
+	// the block may carry the blobs of other transactions too
+	byHash := make(map[common.Hash]BlobSidecar, len(sidecars.Data))
+	for _, sidecar := range sidecars.Data {
+		if len(sidecar.KZGCommitment) != len(gokzg4844.KZGCommitment{}) {
+			return nil, fmt.Errorf("blob sidecar %s of slot %d has a commitment of %d bytes", sidecar.Index, slot, len(sidecar.KZGCommitment))
+		}
+		byHash[common.Hash(libkzg.KZGToVersionedHash(gokzg4844.KZGCommitment(sidecar.KZGCommitment)))] = sidecar
+	}
+
+	kzgCtx := libkzg.Ctx()
+	blobs := make([][]byte, len(versionedHashes))
+	for i, hash := range versionedHashes {
+		sidecar, ok := byHash[hash]
+		if !ok {
+			return nil, fmt.Errorf("no blob with versioned hash %s in slot %d", hash, slot)
+		}
+		if len(sidecar.Blob) != len(gokzg4844.Blob{}) || len(sidecar.KZGProof) != len(gokzg4844.KZGProof{}) {
+			return nil, fmt.Errorf("blob sidecar %s of slot %d is malformed", sidecar.Index, slot)
+		}
+		if err = kzgCtx.VerifyBlobKZGProof(gokzg4844.Blob(sidecar.Blob), gokzg4844.KZGCommitment(sidecar.KZGCommitment), gokzg4844.KZGProof(sidecar.KZGProof)); err != nil {
+			return nil, fmt.Errorf("blob with versioned hash %s in slot %d doesn't match its commitment: %w", hash, slot, err)
+		}
+		blobs[i] = sidecar.Blob
+	}
+
+	return blobs, nil
+}
+
+// slotAt is the slot of the beacon chain the L1 block with the given timestamp was proposed in
+func (c *BeaconClient) slotAt(ctx context.Context, l1BlockTime uint64) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.secondsPerSlot == 0 {
+		var genesis struct {
+			Data struct {
+				GenesisTime string `json:"genesis_time"`
+			} `json:"data"`
+		}
+		if err := c.get(ctx, "/eth/v1/beacon/genesis", &genesis); err != nil {
+			return 0, err
+		}
+		var spec struct {
+			Data struct {
+				SecondsPerSlot string `json:"SECONDS_PER_SLOT"`
+			} `json:"data"`
+		}
+		if err := c.get(ctx, "/eth/v1/config/spec", &spec); err != nil {
+			return 0, err
+		}
+
+		genesisTime, err := strconv.ParseUint(genesis.Data.GenesisTime, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid beacon genesis time %q: %w", genesis.Data.GenesisTime, err)
+		}
+		secondsPerSlot, err := strconv.ParseUint(spec.Data.SecondsPerSlot, 10, 64)
+		if err != nil || secondsPerSlot == 0 {
+			return 0, fmt.Errorf("invalid beacon seconds per slot %q", spec.Data.SecondsPerSlot)
+		}
+		c.genesisTime, c.secondsPerSlot = genesisTime, secondsPerSlot
+	}
+
+	if l1BlockTime < c.genesisTime {
+		return 0, fmt.Errorf("l1 block time %d is before the beacon genesis time %d", l1BlockTime, c.genesisTime)
+	}
+	return (l1BlockTime - c.genesisTime) / c.secondsPerSlot, nil
+}
+
+func (c *BeaconClient) get(ctx context.Context, path string, result interface{}) error {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+path, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode == http.StatusTooManyRequests && attempt+1 < maxAttempts {
+			resp.Body.Close()
+			time.Sleep(retryDelay)
+			continue
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("beacon api %s returned status %d", path, resp.StatusCode)
+		}
+		return json.NewDecoder(resp.Body).Decode(result)
+	}
+}
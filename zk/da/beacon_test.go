@@ -0,0 +1,87 @@
+package da
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
+	libkzg "github.com/ledgerwatch/erigon-lib/crypto/kzg"
+	"github.com/stretchr/testify/require"
+)
+
+// testBlobSidecar builds the sidecar of a blob filled with the given byte, returning it with its versioned hash
+func testBlobSidecar(t *testing.T, index int, fill byte) (BlobSidecar, common.Hash) {
+	t.Helper()
+
+	var blob gokzg4844.Blob
+	for i := 1; i < len(blob); i += 32 {
+		blob[i] = fill
+	}
+	commitment, err := libkzg.Ctx().BlobToKZGCommitment(blob, 1)
+	require.NoError(t, err)
+	proof, err := libkzg.Ctx().ComputeBlobKZGProof(blob, commitment, 1)
+	require.NoError(t, err)
+
+	return BlobSidecar{
+		Index:         fmt.Sprint(index),
+		Blob:          blob[:],
+		KZGCommitment: commitment[:],
+		KZGProof:      proof[:],
+	}, common.Hash(libkzg.KZGToVersionedHash(commitment))
+}
+
+// newBeaconStub serves the genesis, the spec and the blob sidecars of the slots of a beacon API, with 12 second slots
+// from genesis at 1000
+func newBeaconStub(t *testing.T, sidecars map[uint64][]BlobSidecar) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/eth/v1/beacon/genesis", func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"data":{"genesis_time":"1000"}}`))
+		require.NoError(t, err)
+	})
+	mux.HandleFunc("/eth/v1/config/spec", func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"data":{"SECONDS_PER_SLOT":"12"}}`))
+		require.NoError(t, err)
+	})
+	mux.HandleFunc("/eth/v1/beacon/blob_sidecars/", func(w http.ResponseWriter, r *http.Request) {
+		var slot uint64
+		_, err := fmt.Sscanf(r.URL.Path, "/eth/v1/beacon/blob_sidecars/%d", &slot)
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{"data": sidecars[slot]}))
+	})
+	svr := httptest.NewServer(mux)
+	t.Cleanup(svr.Close)
+
+	return svr
+}
+
+func TestBeaconClient_GetBlobs(t *testing.T) {
+	sidecar0, hash0 := testBlobSidecar(t, 0, 1)
+	sidecar1, hash1 := testBlobSidecar(t, 1, 2)
+	tampered, tamperedHash := testBlobSidecar(t, 2, 3)
+	tampered.Blob = hexutil.Bytes(sidecar0.Blob)
+
+	// slot 2 is the block at 1024 to 1035
+	svr := newBeaconStub(t, map[uint64][]BlobSidecar{2: {sidecar0, sidecar1, tampered}})
+	client := NewBeaconClient(svr.URL + "/")
+
+	blobs, err := client.GetBlobs(context.Background(), 1030, []common.Hash{hash1, hash0})
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{sidecar1.Blob, sidecar0.Blob}, blobs)
+
+	_, err = client.GetBlobs(context.Background(), 1040, []common.Hash{hash0})
+	require.ErrorContains(t, err, "no blob with versioned hash")
+
+	_, err = client.GetBlobs(context.Background(), 1030, []common.Hash{tamperedHash})
+	require.ErrorContains(t, err, "doesn't match its commitment")
+
+	_, err = client.GetBlobs(context.Background(), 999, []common.Hash{hash0})
+	require.ErrorContains(t, err, "before the beacon genesis time")
+}
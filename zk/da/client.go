@@ -15,11 +15,26 @@ import (
 const maxAttempts = 10
 const retryDelay = 500 * time.Millisecond
 
+// DACClient fetches off chain data from a node of the data availability committee over its JSON-RPC API
+type DACClient struct {
+	url string
+}
+
+func NewDACClient(url string) *DACClient {
+	return &DACClient{
+		url: url,
+	}
+}
+
 func GetOffChainData(ctx context.Context, url string, hash common.Hash) ([]byte, error) {
+	return NewDACClient(url).GetOffChainData(ctx, hash)
+}
+
+func (c *DACClient) GetOffChainData(ctx context.Context, hash common.Hash) ([]byte, error) {
 	attemp := 0
 
 	for attemp < maxAttempts {
-		response, err := client.JSONRPCCall(url, "sync_getOffChainData", hash)
+		response, err := client.JSONRPCCall(c.url, "sync_getOffChainData", hash)
 
 		if httpErr, ok := err.(*client.HTTPError); ok && httpErr.StatusCode == http.StatusTooManyRequests {
 			time.Sleep(retryDelay)
@@ -35,8 +50,16 @@ func GetOffChainData(ctx context.Context, url string, hash common.Hash) ([]byte,
 			return nil, fmt.Errorf("%v %v", response.Error.Code, response.Error.Message)
 		}
 
-		return hexutil.Decode(strings.Trim(string(response.Result), "\""))
+		data, err := hexutil.Decode(strings.Trim(string(response.Result), "\""))
+		if err != nil {
+			return nil, err
+		}
+		if err := checkOffChainData(hash, data); err != nil {
+			return nil, err
+		}
+
+		return data, nil
 	}
 
-	return nil, fmt.Errorf("max attempts of data fetching reached, attempts: %v, DA url: %s", maxAttempts, url)
+	return nil, fmt.Errorf("max attempts of data fetching reached, attempts: %v, DA url: %s", maxAttempts, c.url)
 }
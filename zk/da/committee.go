@@ -0,0 +1,117 @@
+package da
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/length"
+	"github.com/ledgerwatch/erigon/crypto"
+)
+
+const signatureSize = 65
+
+// MaxCommitteeMembers bounds the members of a data committee.  The committee is read from the L1, and what is allocated
+// and checked for it grows with its members and the signatures it requires
+const MaxCommitteeMembers = 256
+
+type CommitteeMember struct {
+	Url  string
+	Addr common.Address
+}
+
+// Committee is the data availability committee of a validium, as set in its data committee contract on the L1
+type Committee struct {
+	Members            []CommitteeMember
+	RequiredSignatures uint64
+}
+
+// SignedHash is the hash the committee signs for a validium sequence, accumulated over the transactions hashes of its
+// batches that are not forced
+func SignedHash(transactionsHashes []common.Hash) common.Hash {
+	var signedHash common.Hash
+	for _, hash := range transactionsHashes {
+		signedHash = crypto.Keccak256Hash(signedHash[:], hash[:])
+	}
+	return signedHash
+}
+
+// Validate checks the committee has no more than MaxCommitteeMembers members and requires no more signatures than it
+// has members
+func (c *Committee) Validate() error {
+	if len(c.Members) > MaxCommitteeMembers {
+		return fmt.Errorf("data committee has %d members, more than the %d supported", len(c.Members), MaxCommitteeMembers)
+	}
+	if c.RequiredSignatures > uint64(len(c.Members)) {
+		return fmt.Errorf("data committee requires %d signatures of its %d members", c.RequiredSignatures, len(c.Members))
+	}
+	return nil
+}
+
+// VerifySignatures checks the data availability message of a validium sequence as its data committee contract does.
+// The message is the required number of signatures of the signed hash followed by the addresses of all the members.
+// The addresses have to be the committee's and the signers members of it, in the order of the members
+func (c *Committee) VerifySignatures(signedHash common.Hash, message []byte) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	splitByte := signatureSize * int(c.RequiredSignatures)
+	if len(message) < splitByte || (len(message)-splitByte)%length.Addr != 0 {
+		return fmt.Errorf("data availability message of %d bytes for %d signatures", len(message), c.RequiredSignatures)
+	}
+
+	addrs := message[splitByte:]
+	if len(addrs) != len(c.Members)*length.Addr {
+		return fmt.Errorf("data availability message has %d committee addresses, the committee has %d members", len(addrs)/length.Addr, len(c.Members))
+	}
+	for i, member := range c.Members {
+		if !bytes.Equal(addrs[i*length.Addr:(i+1)*length.Addr], member.Addr[:]) {
+			return fmt.Errorf("data availability message committee address %d is not member %s", i, member.Addr)
+		}
+	}
+
+	lastAddrIndexUsed := 0
+	for i := 0; i < int(c.RequiredSignatures); i++ {
+		signer, err := recoverSigner(signedHash, message[i*signatureSize:(i+1)*signatureSize])
+		if err != nil {
+			return fmt.Errorf("data availability signature %d: %w", i, err)
+		}
+
+		isMember := false
+		for j := lastAddrIndexUsed; j < len(c.Members); j++ {
+			if c.Members[j].Addr == signer {
+				lastAddrIndexUsed = j + 1
+				isMember = true
+				break
+			}
+		}
+		if !isMember {
+			return fmt.Errorf("data availability signature %d is by %s, not a committee member after the previous signer", i, signer)
+		}
+	}
+
+	return nil
+}
+
+// recoverSigner recovers the signer of the hash from a signature with a v of 27 or 28, rejecting malleable ones
+func recoverSigner(hash common.Hash, signature []byte) (common.Address, error) {
+	v := signature[64]
+	if v != 27 && v != 28 {
+		return common.Address{}, fmt.Errorf("invalid signature v %d", v)
+	}
+	r := new(uint256.Int).SetBytes(signature[:32])
+	s := new(uint256.Int).SetBytes(signature[32:64])
+	if !crypto.ValidateSignatureValues(v-27, r, s, true) {
+		return common.Address{}, fmt.Errorf("invalid signature values")
+	}
+
+	sig := common.CopyBytes(signature)
+	sig[64] = v - 27
+	pub, err := crypto.SigToPub(hash[:], sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
@@ -0,0 +1,62 @@
+package da
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommittee_VerifySignatures(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 4)
+	for i := range keys {
+		var err error
+		keys[i], err = crypto.GenerateKey()
+		require.NoError(t, err)
+	}
+	// the last key is not a member
+	committee := &Committee{RequiredSignatures: 2}
+	var addrs []byte
+	for _, key := range keys[:3] {
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		committee.Members = append(committee.Members, CommitteeMember{Url: "http://dac", Addr: addr})
+		addrs = append(addrs, addr[:]...)
+	}
+	signedHash := SignedHash([]common.Hash{{1}, {2}})
+	require.Equal(t, crypto.Keccak256Hash(crypto.Keccak256(make([]byte, 32), common.Hash{1}.Bytes()), common.Hash{2}.Bytes()), signedHash)
+
+	message := func(signers ...int) []byte {
+		var message []byte
+		for _, signer := range signers {
+			signature, err := crypto.Sign(signedHash[:], keys[signer])
+			require.NoError(t, err)
+			signature[64] += 27
+			message = append(message, signature...)
+		}
+		return append(message, addrs...)
+	}
+
+	require.NoError(t, committee.VerifySignatures(signedHash, message(0, 2)))
+	require.NoError(t, committee.VerifySignatures(signedHash, message(1, 2)))
+
+	require.ErrorContains(t, committee.VerifySignatures(signedHash, message(2, 0)), "data availability signature 1 is by")
+	require.ErrorContains(t, committee.VerifySignatures(signedHash, message(0, 0)), "data availability signature 1 is by")
+	require.ErrorContains(t, committee.VerifySignatures(signedHash, message(0, 3)), "not a committee member")
+	require.ErrorContains(t, committee.VerifySignatures(common.Hash{3}, message(0, 1)), "not a committee member")
+	require.ErrorContains(t, committee.VerifySignatures(signedHash, append(message(0, 1), addrs[:20]...)), "has 4 committee addresses, the committee has 3 members")
+	require.ErrorContains(t, committee.VerifySignatures(signedHash, message(0, 1)[:100]), "data availability message of 100 bytes")
+
+	// a committee requiring more signatures than it has members, or with too many, is not checked against
+	require.ErrorContains(t, (&Committee{Members: committee.Members, RequiredSignatures: 4}).VerifySignatures(signedHash, message(0, 1)), "requires 4 signatures of its 3 members")
+	require.ErrorContains(t, (&Committee{Members: make([]CommitteeMember, MaxCommitteeMembers+1)}).VerifySignatures(signedHash, nil), "more than the 256 supported")
+
+	tampered := message(0, 1)
+	copy(tampered[130:], common.Address{1}.Bytes())
+	require.ErrorContains(t, committee.VerifySignatures(signedHash, tampered), "committee address 0 is not member")
+
+	tampered = message(0, 1)
+	tampered[64] = 1
+	require.ErrorContains(t, committee.VerifySignatures(signedHash, tampered), "invalid signature v 1")
+}
@@ -0,0 +1,58 @@
+package da
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon/crypto"
+)
+
+// FileBackend serves off chain data from a directory holding each piece of it in a file named by its hash, as an
+// archive of the data of a validium or for tests
+type FileBackend struct {
+	dir string
+}
+
+func NewFileBackend(dir string) *FileBackend {
+	return &FileBackend{
+		dir: dir,
+	}
+}
+
+func (f *FileBackend) GetOffChainData(_ context.Context, hash common.Hash) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(f.dir, hash.Hex()))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOffChainData(hash, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// Put adds the data to the directory, returning the hash it is served by
+func (f *FileBackend) Put(data []byte) (common.Hash, error) {
+	hash := crypto.Keccak256Hash(data)
+	if err := os.MkdirAll(f.dir, 0755); err != nil {
+		return common.Hash{}, err
+	}
+
+	// written next to the file and moved over it so that a reader never sees it partly written
+	tmp, err := os.CreateTemp(f.dir, hash.Hex()+".tmp")
+	if err != nil {
+		return common.Hash{}, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	if _, err := tmp.Write(data); err != nil {
+		return common.Hash{}, err
+	}
+	if err := tmp.Close(); err != nil {
+		return common.Hash{}, err
+	}
+
+	return hash, os.Rename(tmp.Name(), filepath.Join(f.dir, hash.Hex()))
+}
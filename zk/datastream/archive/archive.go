@@ -0,0 +1,344 @@
+package archive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ledgerwatch/erigon/zk/datastream/client"
+	"github.com/ledgerwatch/erigon/zk/datastream/types"
+)
+
+/*
+a datastream archive holds the entries of a range of closed batches, so that a node can be seeded from a file rather
+than by streaming the whole history from a sequencer.  The layout is
+
+	magic | batch section ... | index | footer
+
+every batch section is a gzip member with the entries from the bookmark of the batch up to and including its batch
+end, each one as entry type (uint32), entry number (uint64), data length (uint32) and data.  The index has an
+indexEntrySize record per batch and the footer the offset of the index, the number of batches, the entry number after
+the last one and the magic again.
+*/
+
+const (
+	magic          = "CDKDSAR1"
+	indexEntrySize = 32
+	footerSize     = 32
+)
+
+var ErrNotArchive = errors.New("not a datastream archive")
+
+// BatchIndex locates a batch in the archive
+type BatchIndex struct {
+	Batch      uint64
+	FirstBlock uint64 // 0 if the batch has no blocks, or only the genesis one
+	LastBlock  uint64 // 0 if the batch has no blocks, or only the genesis one
+	Offset     uint64 // of the gzip member of the batch
+}
+
+func (b BatchIndex) HasBlocks() bool {
+	return b.FirstBlock != 0
+}
+
+type countingWriter struct {
+	w     io.Writer
+	count uint64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.count += uint64(n)
+	return n, err
+}
+
+type Writer struct {
+	file    *os.File
+	buf     *bufio.Writer
+	out     *countingWriter
+	section *gzip.Writer
+
+	index     []BatchIndex
+	nextEntry uint64
+}
+
+// Create creates the archive at path, truncating any file already there
+func Create(path string) (*Writer, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	buf := bufio.NewWriter(file)
+	w := &Writer{file: file, buf: buf, out: &countingWriter{w: buf}}
+	if _, err := w.out.Write([]byte(magic)); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// StartBatch closes the section of the previous batch, the entries written from now on belong to the given batch
+func (w *Writer) StartBatch(batch uint64) error {
+	if err := w.closeSection(); err != nil {
+		return err
+	}
+	if len(w.index) > 0 && batch <= w.index[len(w.index)-1].Batch {
+		return fmt.Errorf("batch %d written after batch %d", batch, w.index[len(w.index)-1].Batch)
+	}
+
+	w.index = append(w.index, BatchIndex{Batch: batch, Offset: w.out.count})
+	w.section = gzip.NewWriter(w.out)
+	return nil
+}
+
+// WriteEntry appends the entry to the section of the current batch
+func (w *Writer) WriteEntry(entry *types.FileEntry) error {
+	if w.section == nil {
+		return errors.New("entry written before the start of a batch")
+	}
+	current := &w.index[len(w.index)-1]
+	if entry.EntryType == types.EntryTypeL2Block {
+		l2Block, err := types.UnmarshalL2Block(entry.Data)
+		if err != nil {
+			return fmt.Errorf("UnmarshalL2Block: %w", err)
+		}
+		if !current.HasBlocks() {
+			current.FirstBlock = l2Block.L2BlockNumber
+		}
+		current.LastBlock = l2Block.L2BlockNumber
+	}
+
+	header := make([]byte, 16)
+	binary.BigEndian.PutUint32(header[0:4], uint32(entry.EntryType))
+	binary.BigEndian.PutUint64(header[4:12], entry.EntryNum)
+	binary.BigEndian.PutUint32(header[12:16], uint32(len(entry.Data)))
+	if _, err := w.section.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.section.Write(entry.Data); err != nil {
+		return err
+	}
+
+	w.nextEntry = entry.EntryNum + 1
+	return nil
+}
+
+// Batches is the index of the batches written so far
+func (w *Writer) Batches() []BatchIndex {
+	return w.index
+}
+
+func (w *Writer) closeSection() error {
+	if w.section == nil {
+		return nil
+	}
+	err := w.section.Close()
+	w.section = nil
+	return err
+}
+
+// Close writes the index and the footer and closes the file
+func (w *Writer) Close() error {
+	defer w.file.Close()
+
+	if err := w.closeSection(); err != nil {
+		return err
+	}
+
+	indexOffset := w.out.count
+	record := make([]byte, indexEntrySize)
+	for _, batch := range w.index {
+		binary.BigEndian.PutUint64(record[0:8], batch.Batch)
+		binary.BigEndian.PutUint64(record[8:16], batch.FirstBlock)
+		binary.BigEndian.PutUint64(record[16:24], batch.LastBlock)
+		binary.BigEndian.PutUint64(record[24:32], batch.Offset)
+		if _, err := w.out.Write(record); err != nil {
+			return err
+		}
+	}
+
+	footer := make([]byte, footerSize)
+	binary.BigEndian.PutUint64(footer[0:8], indexOffset)
+	binary.BigEndian.PutUint64(footer[8:16], uint64(len(w.index)))
+	binary.BigEndian.PutUint64(footer[16:24], w.nextEntry)
+	copy(footer[24:], magic)
+	if _, err := w.out.Write(footer); err != nil {
+		return err
+	}
+
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	return w.file.Sync()
+}
+
+type Reader struct {
+	file        *os.File
+	index       []BatchIndex
+	indexOffset uint64
+	entryLimit  uint64
+}
+
+// Open reads the index of the archive at path
+func Open(path string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Reader{file: file}
+	if err := r.readIndex(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Reader) readIndex() error {
+	stat, err := r.file.Stat()
+	if err != nil {
+		return err
+	}
+	if stat.Size() < int64(len(magic)+footerSize) {
+		return ErrNotArchive
+	}
+
+	head := make([]byte, len(magic))
+	if _, err := r.file.ReadAt(head, 0); err != nil {
+		return err
+	}
+	footer := make([]byte, footerSize)
+	if _, err := r.file.ReadAt(footer, stat.Size()-footerSize); err != nil {
+		return err
+	}
+	if string(head) != magic || string(footer[24:]) != magic {
+		return ErrNotArchive
+	}
+
+	r.indexOffset = binary.BigEndian.Uint64(footer[0:8])
+	count := binary.BigEndian.Uint64(footer[8:16])
+	r.entryLimit = binary.BigEndian.Uint64(footer[16:24])
+	if r.indexOffset+count*indexEntrySize != uint64(stat.Size())-footerSize {
+		return fmt.Errorf("%w: corrupt index", ErrNotArchive)
+	}
+
+	records := make([]byte, count*indexEntrySize)
+	if _, err := r.file.ReadAt(records, int64(r.indexOffset)); err != nil {
+		return err
+	}
+	r.index = make([]BatchIndex, count)
+	for i := range r.index {
+		record := records[i*indexEntrySize:]
+		r.index[i] = BatchIndex{
+			Batch:      binary.BigEndian.Uint64(record[0:8]),
+			FirstBlock: binary.BigEndian.Uint64(record[8:16]),
+			LastBlock:  binary.BigEndian.Uint64(record[16:24]),
+			Offset:     binary.BigEndian.Uint64(record[24:32]),
+		}
+	}
+
+	return nil
+}
+
+// Batches is the index of the archive, in batch order
+func (r *Reader) Batches() []BatchIndex {
+	return r.index
+}
+
+func (r *Reader) Close() error {
+	return r.file.Close()
+}
+
+// Iterator returns the entries of the archive from the section at position i of the index on
+func (r *Reader) Iterator(i int) *Iterator {
+	return &Iterator{r: r, next: i, end: len(r.index)}
+}
+
+// GetL2BlockByNumber looks up the block in the archive
+func (r *Reader) GetL2BlockByNumber(blockNum uint64) (*types.FullL2Block, error) {
+	for i, batch := range r.index {
+		if !batch.HasBlocks() || blockNum < batch.FirstBlock || blockNum > batch.LastBlock {
+			continue
+		}
+
+		it := &Iterator{r: r, next: i, end: i + 1}
+		var forkId uint64
+		for {
+			entry, _, err := client.ReadParsedProto(it)
+			if err != nil {
+				return nil, err
+			}
+			switch entry := entry.(type) {
+			case nil:
+				return nil, fmt.Errorf("block %d missing from the section of batch %d", blockNum, batch.Batch)
+			case *types.BatchStart:
+				forkId = entry.ForkId
+			case *types.FullL2Block:
+				if entry.L2BlockNumber == blockNum {
+					entry.ForkId = forkId
+					return entry, nil
+				}
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("block %d not in the archive", blockNum)
+}
+
+// Iterator reads the entries of consecutive batch sections, it implements client.FileEntryIterator
+type Iterator struct {
+	r       *Reader
+	next    int
+	end     int // the position after the last section read
+	section *gzip.Reader
+}
+
+// NextFileEntry returns the next entry, nil at the end of the archive
+func (it *Iterator) NextFileEntry() (*types.FileEntry, error) {
+	for {
+		if it.section == nil {
+			if it.next >= it.end {
+				return nil, nil
+			}
+			end := it.r.indexOffset
+			if it.next+1 < len(it.r.index) {
+				end = it.r.index[it.next+1].Offset
+			}
+			start := it.r.index[it.next].Offset
+			section, err := gzip.NewReader(io.NewSectionReader(it.r.file, int64(start), int64(end-start)))
+			if err != nil {
+				return nil, fmt.Errorf("section of batch %d: %w", it.r.index[it.next].Batch, err)
+			}
+			it.section = section
+			it.next++
+		}
+
+		header := make([]byte, 16)
+		if _, err := io.ReadFull(it.section, header); err != nil {
+			if errors.Is(err, io.EOF) {
+				it.section = nil
+				continue
+			}
+			return nil, err
+		}
+		data := make([]byte, binary.BigEndian.Uint32(header[12:16]))
+		if _, err := io.ReadFull(it.section, data); err != nil {
+			return nil, err
+		}
+
+		return &types.FileEntry{
+			PacketType: client.PtData,
+			Length:     types.FileEntryMinSize + uint32(len(data)),
+			EntryType:  types.EntryType(binary.BigEndian.Uint32(header[0:4])),
+			EntryNum:   binary.BigEndian.Uint64(header[4:12]),
+			Data:       data,
+		}, nil
+	}
+}
+
+func (it *Iterator) GetEntryNumberLimit() uint64 {
+	return it.r.entryLimit
+}
@@ -0,0 +1,132 @@
+package archive
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/0xPolygonHermez/zkevm-data-streamer/datastreamer"
+	"github.com/ledgerwatch/erigon/zk/datastream/client"
+	"github.com/ledgerwatch/erigon/zk/datastream/proto/github.com/0xPolygonHermez/zkevm-node/state/datastream"
+	"github.com/ledgerwatch/erigon/zk/datastream/server"
+	"github.com/ledgerwatch/erigon/zk/datastream/types"
+	"github.com/stretchr/testify/require"
+)
+
+type testEntry interface {
+	Marshal() ([]byte, error)
+	Type() types.EntryType
+}
+
+// testStream is a stream of batches 1 to 3 with two blocks each, the last batch left open
+func testStream(t *testing.T) []datastreamer.FileEntry {
+	t.Helper()
+
+	var protos []testEntry
+	block := uint64(1)
+	for batch := uint64(1); batch <= 3; batch++ {
+		protos = append(protos,
+			types.NewBookmarkProto(batch, datastream.BookmarkType_BOOKMARK_TYPE_BATCH),
+			&types.BatchStartProto{BatchStart: &datastream.BatchStart{Number: batch, ForkId: 9}},
+		)
+		for i := 0; i < 2; i++ {
+			protos = append(protos,
+				types.NewBookmarkProto(block, datastream.BookmarkType_BOOKMARK_TYPE_L2_BLOCK),
+				&types.L2BlockProto{L2Block: &datastream.L2Block{Number: block, BatchNumber: batch, Timestamp: block * 10}},
+				&types.L2BlockEndProto{Number: block},
+			)
+			block++
+		}
+		if batch < 3 {
+			protos = append(protos, &types.BatchEndProto{BatchEnd: &datastream.BatchEnd{Number: batch}})
+		}
+	}
+
+	entries := make([]datastreamer.FileEntry, len(protos))
+	for i, proto := range protos {
+		data, err := proto.Marshal()
+		require.NoError(t, err)
+		entries[i] = datastreamer.FileEntry{
+			Type:   datastreamer.EntryType(proto.Type()),
+			Length: types.FileEntryMinSize + uint32(len(data)),
+			Number: uint64(i),
+			Data:   data,
+		}
+	}
+	return entries
+}
+
+type testStreamServer struct {
+	server.StreamServer
+	entries []datastreamer.FileEntry
+}
+
+func (s *testStreamServer) GetHeader() datastreamer.HeaderEntry {
+	return datastreamer.HeaderEntry{TotalEntries: uint64(len(s.entries))}
+}
+
+func (s *testStreamServer) GetEntry(entryNum uint64) (datastreamer.FileEntry, error) {
+	return s.entries[entryNum], nil
+}
+
+func (s *testStreamServer) GetBookmark(bookmark []byte) (uint64, error) {
+	for _, entry := range s.entries {
+		if entry.Type == datastreamer.EntryType(types.BookmarkEntryType) && string(entry.Data) == string(bookmark) {
+			return entry.Number, nil
+		}
+	}
+	return 0, errors.New("bookmark not found")
+}
+
+func TestExportAndRead(t *testing.T) {
+	stream := &testStreamServer{entries: testStream(t)}
+	path := filepath.Join(t.TempDir(), "batches.dsar")
+
+	w, err := Create(path)
+	require.NoError(t, err)
+	require.NoError(t, Export(stream, w, 1, 2))
+	require.NoError(t, w.Close())
+
+	r, err := Open(path)
+	require.NoError(t, err)
+	defer r.Close()
+	require.Equal(t, []BatchIndex{
+		{Batch: 1, FirstBlock: 1, LastBlock: 2, Offset: r.Batches()[0].Offset},
+		{Batch: 2, FirstBlock: 3, LastBlock: 4, Offset: r.Batches()[1].Offset},
+	}, r.Batches())
+
+	// the entries come back as they were in the stream, from any batch on
+	it := r.Iterator(1)
+	var blocks []uint64
+	for {
+		entry, _, err := client.ReadParsedProto(it)
+		require.NoError(t, err)
+		if entry == nil {
+			break
+		}
+		if l2Block, ok := entry.(*types.FullL2Block); ok {
+			blocks = append(blocks, l2Block.L2BlockNumber)
+		}
+	}
+	require.Equal(t, []uint64{3, 4}, blocks)
+
+	l2Block, err := r.GetL2BlockByNumber(2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), l2Block.BatchNumber)
+	require.Equal(t, uint64(9), l2Block.ForkId)
+	_, err = r.GetL2BlockByNumber(5)
+	require.Error(t, err)
+}
+
+func TestExportOpenBatch(t *testing.T) {
+	stream := &testStreamServer{entries: testStream(t)}
+	path := filepath.Join(t.TempDir(), "batches.dsar")
+
+	w, err := Create(path)
+	require.NoError(t, err)
+	require.ErrorContains(t, Export(stream, w, 2, 3), "batch 3 is not closed")
+	require.NoError(t, w.Close())
+
+	_, err = Open(filepath.Join(t.TempDir(), "missing.dsar"))
+	require.Error(t, err)
+}
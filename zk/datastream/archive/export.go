@@ -0,0 +1,68 @@
+package archive
+
+import (
+	"fmt"
+
+	"github.com/ledgerwatch/erigon/zk/datastream/proto/github.com/0xPolygonHermez/zkevm-node/state/datastream"
+	"github.com/ledgerwatch/erigon/zk/datastream/server"
+	"github.com/ledgerwatch/erigon/zk/datastream/types"
+)
+
+// Export writes the batches fromBatch to toBatch of the stream to the archive, all of them have to be closed
+func Export(stream server.StreamServer, w *Writer, fromBatch, toBatch uint64) error {
+	if fromBatch > toBatch {
+		return fmt.Errorf("from batch %d is after to batch %d", fromBatch, toBatch)
+	}
+
+	bookmark, err := types.NewBookmarkProto(fromBatch, datastream.BookmarkType_BOOKMARK_TYPE_BATCH).Marshal()
+	if err != nil {
+		return err
+	}
+	entryNum, err := stream.GetBookmark(bookmark)
+	if err != nil {
+		return fmt.Errorf("batch %d not in the datastream: %w", fromBatch, err)
+	}
+
+	totalEntries := stream.GetHeader().TotalEntries
+	for ; entryNum < totalEntries; entryNum++ {
+		fileEntry, err := stream.GetEntry(entryNum)
+		if err != nil {
+			return fmt.Errorf("GetEntry %d: %w", entryNum, err)
+		}
+		entry := &types.FileEntry{
+			PacketType: uint8(fileEntry.Type),
+			Length:     fileEntry.Length,
+			EntryType:  types.EntryType(fileEntry.Type),
+			EntryNum:   fileEntry.Number,
+			Data:       fileEntry.Data,
+		}
+
+		if entry.IsBookmark() {
+			bookmark, err := types.UnmarshalBookmark(entry.Data)
+			if err != nil {
+				return fmt.Errorf("UnmarshalBookmark: %w", err)
+			}
+			if bookmark.BookmarkType() == datastream.BookmarkType_BOOKMARK_TYPE_BATCH {
+				if err := w.StartBatch(bookmark.Value); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := w.WriteEntry(entry); err != nil {
+			return err
+		}
+
+		if entry.EntryType == types.EntryTypeBatchEnd {
+			batchEnd, err := types.UnmarshalBatchEnd(entry.Data)
+			if err != nil {
+				return fmt.Errorf("UnmarshalBatchEnd: %w", err)
+			}
+			if batchEnd.Number >= toBatch {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("batch %d is not closed in the datastream yet", toBatch)
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// AuthConfig secures the connection to a stream server, the zero value is a plaintext connection without a token
+type AuthConfig struct {
+	// connect over TLS
+	TLS bool
+	// the CA the server certificate is verified against, the system roots if empty
+	CAFile string
+	// the client certificate and key, for servers requiring mutual TLS
+	CertFile string
+	KeyFile  string
+	// the pre-shared token sent with CmdAuth right after connecting, none if empty
+	Token string
+}
+
+// ClientTLSConfig loads the certificates of the config
+func (a AuthConfig) ClientTLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if a.CAFile != "" {
+		pool, err := LoadCertPool(a.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if a.CertFile != "" || a.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(a.CertFile, a.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading the client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// LoadCertPool reads the PEM encoded certificates in the file
+func LoadCertPool(file string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading the CA file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("no certificates found in the CA file")
+	}
+	return pool, nil
+}
+
+// SetAuth sets how the client connects to the server, it applies from the next connection on
+func (c *StreamClient) SetAuth(auth AuthConfig) {
+	c.auth = auth
+}
+
+// authenticate sends the token of the client over the fresh connection and waits for the server to accept it
+func (c *StreamClient) authenticate() error {
+	if err := c.sendAuthCmd(c.auth.Token); err != nil {
+		return fmt.Errorf("sendAuthCmd: %w", err)
+	}
+
+	if _, err := c.readPacketAndDecodeResultEntry(); err != nil {
+		return fmt.Errorf("readPacketAndDecodeResultEntry: %w", err)
+	}
+
+	return nil
+}
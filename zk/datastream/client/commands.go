@@ -1,5 +1,7 @@
 package client
 
+import "github.com/ledgerwatch/erigon/zk/datastream/types"
+
 const (
 	// Commands
 	CmdUnknown Command = iota
@@ -9,6 +11,12 @@ const (
 	CmdStartBookmark // CmdStartBookmark for the start from bookmark TCP client command
 	CmdEntry         // CmdEntry for the get entry TCP client command
 	CmdBookmark      // CmdBookmark for the get bookmark TCP client command
+
+	// CmdAuth authenticates the client with a pre-shared token.  It is not part of the upstream protocol, the auth
+	// proxy in front of the stream server answers it
+	CmdAuth Command = 100
+	// CmdStartFiltered streams the entries matching a filter from a bookmark on, served by the proxy as well
+	CmdStartFiltered Command = 101
 )
 
 // sendHeaderCmd sends the header command to the server.
@@ -61,6 +69,43 @@ func (c *StreamClient) sendEntryCmd(entryNum uint64) error {
 	return c.writeToConn(entryNum)
 }
 
+// sendAuthCmd sends the auth command with the token to the server.
+func (c *StreamClient) sendAuthCmd(token string) error {
+	if err := c.sendCommand(CmdAuth); err != nil {
+		return err
+	}
+
+	// Send token length
+	if err := c.writeToConn(uint32(len(token))); err != nil {
+		return err
+	}
+
+	// Send the token
+	return c.writeToConn([]byte(token))
+}
+
+// sendStartFilteredCmd sends the filtered start command with the bookmark to start from and the filter.
+func (c *StreamClient) sendStartFilteredCmd(bookmark []byte, filter *types.StreamFilter) error {
+	if err := c.sendCommand(CmdStartFiltered); err != nil {
+		return err
+	}
+
+	// Send bookmark length and bookmark
+	if err := c.writeToConn(uint32(len(bookmark))); err != nil {
+		return err
+	}
+	if err := c.writeToConn(bookmark); err != nil {
+		return err
+	}
+
+	// Send filter length and filter
+	encoded := filter.Encode()
+	if err := c.writeToConn(uint32(len(encoded))); err != nil {
+		return err
+	}
+	return c.writeToConn(encoded)
+}
+
 // sendHeaderCmd sends the header command to the server.
 func (c *StreamClient) sendStopCmd() error {
 	return c.sendCommand(CmdStop)
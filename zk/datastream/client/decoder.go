@@ -0,0 +1,143 @@
+package client
+
+import (
+	"errors"
+	"sync"
+
+	eritypes "github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/zk/datastream/types"
+	txtype "github.com/ledgerwatch/erigon/zk/tx"
+)
+
+const (
+	// the entries read ahead of the consumer of the entry channel at most
+	decodeReadAhead = 1024
+)
+
+var errDecoderStopped = errors.New("block decoder stopped")
+
+// blockDecoder reads the entries ahead on its own goroutine, strictly in order, and decodes the transactions of the
+// blocks on a pool of workers.  The entries are handed out in the order they were read, each once its block is decoded
+type blockDecoder struct {
+	iterator     FileEntryIterator
+	totalEntries uint64
+	// the fork of the blocks read, taken from the batch starts
+	fork uint64
+	// resets the read deadline of the connection before each entry
+	resetReadTimeout func() error
+
+	jobs    chan *decodeJob
+	pending chan *decodeJob
+	quit    chan struct{}
+	wg      sync.WaitGroup
+}
+
+type decodeJob struct {
+	parsed   interface{}
+	entryNum uint64
+	err      error
+	done     chan struct{}
+}
+
+// newBlockDecoder starts reading from the iterator up to and including the last of the total entries
+func newBlockDecoder(iterator FileEntryIterator, totalEntries, fork uint64, workers int, resetReadTimeout func() error) *blockDecoder {
+	d := &blockDecoder{
+		iterator:         iterator,
+		totalEntries:     totalEntries,
+		fork:             fork,
+		resetReadTimeout: resetReadTimeout,
+		jobs:             make(chan *decodeJob, decodeReadAhead),
+		pending:          make(chan *decodeJob, decodeReadAhead),
+		quit:             make(chan struct{}),
+	}
+
+	for i := 0; i < workers; i++ {
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			for job := range d.jobs {
+				decodeBlockTxs(job.parsed.(*types.FullL2Block))
+				close(job.done)
+			}
+		}()
+	}
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer close(d.jobs)
+		d.read()
+	}()
+
+	return d
+}
+
+func (d *blockDecoder) read() {
+	for {
+		job := &decodeJob{done: make(chan struct{})}
+		if job.err = d.resetReadTimeout(); job.err == nil {
+			job.parsed, job.entryNum, job.err = ReadParsedProto(d.iterator)
+		}
+
+		l2Block, isBlock := job.parsed.(*types.FullL2Block)
+		switch parsed := job.parsed.(type) {
+		case *types.BatchStart:
+			d.fork = parsed.ForkId
+		case *types.FullL2Block:
+			parsed.ForkId = d.fork
+		}
+
+		select {
+		case d.pending <- job:
+		case <-d.quit:
+			return
+		}
+		if job.err == nil && isBlock && len(l2Block.L2Txs) > 0 {
+			d.jobs <- job
+		} else {
+			close(job.done)
+		}
+
+		// nothing is read past the end of the stream or an error, the connection is the client's again
+		if job.err != nil || job.parsed == nil || job.entryNum+1 >= d.totalEntries {
+			return
+		}
+	}
+}
+
+// next is the next entry read, along with its number, once decoded
+func (d *blockDecoder) next() (interface{}, uint64, error) {
+	var job *decodeJob
+	select {
+	case job = <-d.pending:
+	case <-d.quit:
+		return nil, 0, errDecoderStopped
+	}
+	select {
+	case <-job.done:
+	case <-d.quit:
+		return nil, 0, errDecoderStopped
+	}
+	return job.parsed, job.entryNum, job.err
+}
+
+// stop waits for the reader, which may be in the middle of reading an entry until the read deadline, and the workers
+func (d *blockDecoder) stop() {
+	close(d.quit)
+	d.wg.Wait()
+}
+
+// decodeBlockTxs decodes and hashes the transactions of the block.  A transaction that doesn't decode leaves them all
+// to be decoded by the consumer, which reports the error.  The senders are not recovered here, nor the signing hashes
+// computed for it: the senders stage recovers them from the bodies written to the db, on workers of its own
+func decodeBlockTxs(l2Block *types.FullL2Block) {
+	txs := make([]eritypes.Transaction, 0, len(l2Block.L2Txs))
+	for _, l2Tx := range l2Block.L2Txs {
+		tx, _, err := txtype.DecodeTx(l2Tx.Encoded, l2Tx.EffectiveGasPricePercentage, l2Block.ForkId)
+		if err != nil {
+			return
+		}
+		tx.Hash()
+		txs = append(txs, tx)
+	}
+	l2Block.DecodedTxs = txs
+}
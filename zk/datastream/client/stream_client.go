@@ -1,12 +1,15 @@
 package client
 
 import (
+	"bufio"
 	"context"
+	"crypto/tls"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"net"
 	"reflect"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -26,9 +29,12 @@ type EntityDefinition struct {
 }
 
 const (
-	versionProto         = 2 // converted to proto
-	versionAddedBlockEnd = 3 // Added block end
-	entryChannelSize     = 100000
+	versionProto            = 2                        // converted to proto
+	versionAddedBlockEnd    = 3                        // Added block end
+	versionAddedEntriesHash = types.EntriesHashVersion // Added the entries hash to the batch end
+	entryChannelSize        = 100000
+	readBufferSize          = 64 * 1024
+	dialTimeout             = 5 * time.Second
 )
 
 var (
@@ -40,12 +46,18 @@ var (
 
 type StreamClient struct {
 	ctx          context.Context
-	server       string // Server address to connect IP:port
+	server       string   // Server address to connect IP:port
+	servers      []string // all the endpoints of the stream, the client fails over between them
+	auth         AuthConfig
 	version      int
 	streamType   StreamType
 	conn         net.Conn
 	checkTimeout time.Duration // time to wait for data before reporting an error
 
+	// buffers the reads from conn, reading the entries with a few syscalls rather than several for each
+	reader     *bufio.Reader
+	readerConn net.Conn
+
 	header *types.HeaderEntry
 
 	// atomic
@@ -68,6 +80,19 @@ type StreamClient struct {
 
 	lastError error
 	started   bool
+
+	// the last block written to the entry channel, after failing over to another endpoint the stream resumes at it
+	lastSentBlock    uint64
+	resumeAfterBlock uint64
+	// the entries below this number were written to the entry channel, whatever their type.  The endpoints serve the
+	// same stream, so after failing over these entries are not written again
+	sentEntries uint64
+
+	// verifies the batch ends read to the channel, kept between reads so batches spanning them are verified too
+	entriesHash types.EntriesHash
+
+	// decode the transactions of the blocks read to the channel, none reads and hands out the blocks undecoded
+	decodeWorkers int
 }
 
 const (
@@ -83,12 +108,14 @@ const (
 )
 
 // Creates a new client fo datastream
-// server must be in format "url:port"
+// server must be in format "url:port", or several of them comma separated to fail over between
 func NewClient(ctx context.Context, server string, version int, checkTimeout time.Duration, latestDownloadedForkId uint16) *StreamClient {
+	servers := strings.Split(strings.ReplaceAll(server, " ", ""), ",")
 	c := &StreamClient{
 		ctx:          ctx,
 		checkTimeout: checkTimeout,
-		server:       server,
+		server:       servers[0],
+		servers:      servers,
 		version:      version,
 		streamType:   StSequencer,
 		entryChan:    make(chan interface{}, 100000),
@@ -99,6 +126,12 @@ func NewClient(ctx context.Context, server string, version int, checkTimeout tim
 	return c
 }
 
+// SetDecodeWorkers sets the number of workers decoding the transactions of the blocks read to the channel ahead of
+// its consumer
+func (c *StreamClient) SetDecodeWorkers(workers int) {
+	c.decodeWorkers = workers
+}
+
 func (c *StreamClient) IsVersion3() bool {
 	return c.version >= versionAddedBlockEnd
 }
@@ -226,7 +259,7 @@ func (c *StreamClient) stopStreamingIfStarted() error {
 	// empty the socket buffer
 	for {
 		c.conn.SetReadDeadline(time.Now().Add(1 * time.Millisecond))
-		if _, err := readBuffer(c.conn, 1000 /* arbitrary number*/); err != nil {
+		if _, err := readBuffer(c.connReader(), 1000 /* arbitrary number*/); err != nil {
 			break
 		}
 	}
@@ -280,15 +313,39 @@ func (c *StreamClient) GetProgressAtomic() *atomic.Uint64 {
 	return &c.progress
 }
 
-// Opens a TCP connection to the server
+// Opens a TCP connection to the server, the one furthest ahead if there are several
 func (c *StreamClient) Start() error {
+	if len(c.servers) > 1 {
+		c.server = c.bestServer("")
+	}
+
+	return c.connect()
+}
+
+func (c *StreamClient) connect() error {
 	// Connect to server
 	var err error
-	c.conn, err = net.Dial("tcp", c.server)
+	if c.auth.TLS {
+		var tlsConfig *tls.Config
+		if tlsConfig, err = c.auth.ClientTLSConfig(); err != nil {
+			return fmt.Errorf("loading the TLS config: %w", err)
+		}
+		c.conn, err = tls.DialWithDialer(&net.Dialer{Timeout: dialTimeout}, "tcp", c.server, tlsConfig)
+	} else {
+		c.conn, err = net.DialTimeout("tcp", c.server, dialTimeout)
+	}
 	if err != nil {
 		return fmt.Errorf("connecting to server %s: %w", c.server, err)
 	}
 
+	if c.auth.Token != "" {
+		if err = c.authenticate(); err != nil {
+			c.conn.Close()
+			c.conn = nil
+			return fmt.Errorf("authenticating with server %s: %w", c.server, err)
+		}
+	}
+
 	return nil
 }
 
@@ -396,6 +453,46 @@ func (c *StreamClient) ExecutePerFile(bookmark *types.BookmarkProto, function fu
 	return nil
 }
 
+// ExecutePerFilteredFile streams the bookmarks and the entries matching the filter from the bookmark on, following the
+// stream until the function returns an error or the context is done.  The filtered stream is served by the proxy in
+// front of the stream server, and the bookmarks keep coming while nothing matches
+func (c *StreamClient) ExecutePerFilteredFile(bookmark *types.BookmarkProto, filter *types.StreamFilter, function func(file *types.FileEntry) error) error {
+	protoBookmark, err := bookmark.Marshal()
+	if err != nil {
+		return fmt.Errorf("bookmark.Marshal: %w", err)
+	}
+
+	if err := c.sendStartFilteredCmd(protoBookmark, filter); err != nil {
+		return fmt.Errorf("sendStartFilteredCmd: %w", err)
+	}
+	c.setStreaming(true)
+	defer c.stopStreamingIfStarted()
+	if _, err := c.afterStartCommand(); err != nil {
+		c.setStreaming(false)
+		return fmt.Errorf("afterStartCommand: %w", err)
+	}
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return c.ctx.Err()
+		default:
+		}
+
+		file, err := c.NextFileEntry()
+		if err != nil {
+			return fmt.Errorf("NextFileEntry: %w", err)
+		}
+		if file == nil {
+			// a result rather than an entry
+			continue
+		}
+		if err := function(file); err != nil {
+			return fmt.Errorf("execute function: %w", err)
+		}
+	}
+}
+
 func (c *StreamClient) clearEntryCHannel() {
 	defer func() {
 		for range c.entryChan {
@@ -431,17 +528,33 @@ func (c *StreamClient) ReadAllEntriesToChannel() (err error) {
 		return fmt.Errorf("stopStreamingIfStarted: %w", err)
 	}
 
-	// first load up the header of the stream
-	if _, err := c.GetHeader(); err != nil {
-		return fmt.Errorf("GetHeader: %w", err)
-	}
+	c.lastSentBlock = 0
+	c.resumeAfterBlock = 0
+	c.sentEntries = 0
+	for failovers := 0; ; failovers++ {
+		// first load up the header of the stream
+		if _, err = c.GetHeader(); err != nil {
+			err = fmt.Errorf("GetHeader: %w", err)
+		} else if err = c.readAllEntriesToChannel(); err == nil {
+			return nil
+		}
 
-	if err = c.readAllEntriesToChannel(); err != nil {
-		c.lastError = err
-		return err
-	}
+		// the consumer of the entry channel never notices the switch to another endpoint, the stream picks up
+		// at the last block written to the channel and skips every entry written already.  An endpoint that pruned the blocks needed may not
+		// be the only one
+		if !(errors.Is(err, ErrSocket) || errors.Is(err, types.ErrPruned)) || len(c.servers) < 2 || failovers >= len(c.servers) {
+			c.lastError = err
+			return err
+		}
+		log.Warn("[Datastream client] Failing over to another datastream endpoint", "server", c.server, "err", err)
 
-	return nil
+		c.setStreaming(false)
+		if err = c.tryReConnect(); err != nil {
+			c.lastError = err
+			return err
+		}
+		c.resumeAfterBlock = c.lastSentBlock
+	}
 }
 
 func (c *StreamClient) handleSocketError(socketErr error) bool {
@@ -473,7 +586,9 @@ func (c *StreamClient) readAllEntriesToChannel() (err error) {
 
 	var bookmark *types.BookmarkProto
 	progress := c.progress.Load()
-	if progress == 0 {
+	if c.resumeAfterBlock > 0 {
+		bookmark = types.NewBookmarkProto(c.resumeAfterBlock, datastream.BookmarkType_BOOKMARK_TYPE_L2_BLOCK)
+	} else if progress == 0 {
 		bookmark = types.NewBookmarkProto(0, datastream.BookmarkType_BOOKMARK_TYPE_BATCH)
 	} else {
 		bookmark = types.NewBookmarkProto(progress+1, datastream.BookmarkType_BOOKMARK_TYPE_L2_BLOCK)
@@ -520,6 +635,23 @@ func (c *StreamClient) afterStartCommand() (*types.ResultEntry, error) {
 // reads all entries from the server and sends them to a channel
 // sends the parsed FullL2Blocks with transactions to a channel
 func (c *StreamClient) readAllFullL2BlocksToChannel() (err error) {
+	var iterator FileEntryIterator = c
+	if c.version >= versionAddedEntriesHash {
+		iterator = &entriesHashIterator{FileEntryIterator: c, entriesHash: &c.entriesHash}
+	}
+
+	readParsedProto := func() (interface{}, uint64, error) {
+		if err := c.resetReadTimeout(); err != nil {
+			return nil, 0, err
+		}
+		return ReadParsedProto(iterator)
+	}
+	if c.decodeWorkers > 0 {
+		decoder := newBlockDecoder(iterator, c.header.TotalEntries, c.currentFork, c.decodeWorkers, c.resetReadTimeout)
+		defer decoder.stop()
+		readParsedProto = decoder.next
+	}
+
 	readNewProto := true
 	entryNum := uint64(0)
 	parsedProto := interface{}(nil)
@@ -535,25 +667,16 @@ LOOP:
 			break LOOP
 		}
 
-		var timeout time.Time
-		if c.checkTimeout < minimumCheckTimeout {
-			timeout = time.Now().Add(minimumCheckTimeout)
-		} else {
-			timeout = time.Now().Add(c.checkTimeout)
-		}
-
-		if err = c.conn.SetReadDeadline(timeout); err != nil {
-			return err
-		}
-
 		if readNewProto {
-			if parsedProto, entryNum, err = ReadParsedProto(c); err != nil {
+			if parsedProto, entryNum, err = readParsedProto(); err != nil {
 				return err
 			}
 			readNewProto = false
 		}
 		c.lastWrittenTime.Store(time.Now().UnixNano())
 
+		// only after failing over are entries read again
+		alreadySent := entryNum < c.sentEntries
 		switch parsedProto := parsedProto.(type) {
 		case *types.BookmarkProto:
 			readNewProto = true
@@ -568,11 +691,19 @@ LOOP:
 		default:
 			return fmt.Errorf("unexpected entry type: %v", parsedProto)
 		}
-		select {
-		case c.entryChan <- parsedProto:
+		if alreadySent {
 			readNewProto = true
-		default:
-			time.Sleep(10 * time.Microsecond)
+		} else {
+			select {
+			case c.entryChan <- parsedProto:
+				readNewProto = true
+				c.sentEntries = entryNum + 1
+				if l2Block, ok := parsedProto.(*types.FullL2Block); ok {
+					c.lastSentBlock = l2Block.L2BlockNumber
+				}
+			default:
+				time.Sleep(10 * time.Microsecond)
+			}
 		}
 
 		if c.header.TotalEntries == entryNum+1 {
@@ -630,13 +761,68 @@ func (c *StreamClient) tryReConnect() (err error) {
 		}
 		c.conn = nil
 	}
-	if err = c.Start(); err != nil {
+	if len(c.servers) > 1 {
+		previous := c.server
+		c.server = c.bestServer(previous)
+		log.Info("[Datastream client] Reconnecting to datastream endpoint", "previous", previous, "server", c.server)
+	}
+	if err = c.connect(); err != nil {
 		log.Warn(fmt.Sprintf("start DS connection: %v", err))
 	}
 
 	return err
 }
 
+// bestServer picks the healthy endpoint with the most entries, other than the excluded one.  Falls back to the excluded
+// one, or the first one, if no other endpoint answers
+func (c *StreamClient) bestServer(exclude string) string {
+	best, bestEntries, found := "", uint64(0), false
+	for _, server := range c.servers {
+		if server == exclude {
+			continue
+		}
+		entries, err := c.probe(server)
+		if err != nil {
+			log.Warn("[Datastream client] Datastream endpoint is unhealthy", "server", server, "err", err)
+			continue
+		}
+		if !found || entries > bestEntries {
+			best, bestEntries, found = server, entries, true
+		}
+	}
+
+	if found {
+		return best
+	}
+	if exclude != "" {
+		return exclude
+	}
+	return c.servers[0]
+}
+
+// probe returns the total entries of the stream served by the endpoint, over a connection that only reads the header
+func (c *StreamClient) probe(server string) (uint64, error) {
+	probe := &StreamClient{
+		ctx:          c.ctx,
+		server:       server,
+		auth:         c.auth,
+		version:      c.version,
+		streamType:   c.streamType,
+		checkTimeout: c.checkTimeout,
+		mtxStreaming: &sync.Mutex{},
+	}
+	if err := probe.connect(); err != nil {
+		return 0, err
+	}
+	defer probe.conn.Close()
+
+	header, err := probe.GetHeader()
+	if err != nil {
+		return 0, err
+	}
+	return header.TotalEntries, nil
+}
+
 func (c *StreamClient) StopReadingToChannel() {
 	c.stopReadingToChannel.Store(true)
 }
@@ -646,6 +832,23 @@ type FileEntryIterator interface {
 	GetEntryNumberLimit() uint64
 }
 
+// entriesHashIterator verifies the entries hash over the entries read through it
+type entriesHashIterator struct {
+	FileEntryIterator
+	entriesHash *types.EntriesHash
+}
+
+func (it *entriesHashIterator) NextFileEntry() (*types.FileEntry, error) {
+	file, err := it.FileEntryIterator.NextFileEntry()
+	if err != nil || file == nil {
+		return file, err
+	}
+	if err := it.entriesHash.Verify(file); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
 func ReadParsedProto(iterator FileEntryIterator) (
 	parsedEntry interface{},
 	entryNum uint64,
@@ -885,6 +1088,10 @@ func (c *StreamClient) readResultEntry(packet []byte) (re *types.ResultEntry, er
 			return re, fmt.Errorf("%w: %s", types.ErrBadFromBookmark, re.ErrorStr)
 		case types.CmdErrInvalidCommand:
 			return re, fmt.Errorf("%w: %s", types.ErrInvalidCommand, re.ErrorStr)
+		case types.CmdErrUnauthorized:
+			return re, fmt.Errorf("%w: %s", types.ErrUnauthorized, re.ErrorStr)
+		case types.CmdErrPruned:
+			return re, fmt.Errorf("%w: %s", types.ErrPruned, re.ErrorStr)
 		default:
 			return re, fmt.Errorf("unknown error code: %s", re.ErrorStr)
 		}
@@ -911,10 +1118,26 @@ func (c *StreamClient) readPacketAndDecodeResultEntry() (*types.ResultEntry, err
 }
 
 func (c *StreamClient) readBuffer(amount uint32) ([]byte, error) {
-	if err := c.resetReadTimeout(); err != nil {
-		return nil, fmt.Errorf("resetReadTimeout: %w", err)
+	reader := c.connReader()
+	// nothing to wait for when buffered already
+	if buffered := reader.Buffered(); buffered < int(amount) {
+		if err := c.resetReadTimeout(); err != nil {
+			// the rest of what the closed connection buffered is still read, the read reports the close
+			if buffered == 0 {
+				return nil, fmt.Errorf("resetReadTimeout: %w", err)
+			}
+		}
+	}
+	return readBuffer(reader, amount)
+}
+
+// connReader is the buffered reader of the connection, a new one for a new connection
+func (c *StreamClient) connReader() *bufio.Reader {
+	if c.reader == nil || c.readerConn != c.conn {
+		c.reader = bufio.NewReaderSize(c.conn, readBufferSize)
+		c.readerConn = c.conn
 	}
-	return readBuffer(c.conn, amount)
+	return c.reader
 }
 
 func (c *StreamClient) writeToConn(data interface{}) error {
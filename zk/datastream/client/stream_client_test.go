@@ -5,14 +5,20 @@ import (
 	"context"
 	"encoding/binary"
 	"fmt"
+	"io"
+	"math/big"
 	"net"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/holiman/uint256"
 	"github.com/ledgerwatch/erigon-lib/common"
+	eritypes "github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
 	"github.com/ledgerwatch/erigon/zk/datastream/proto/github.com/0xPolygonHermez/zkevm-node/state/datastream"
 	"github.com/ledgerwatch/erigon/zk/datastream/types"
+	txtype "github.com/ledgerwatch/erigon/zk/tx"
 	"github.com/stretchr/testify/require"
 	"gotest.tools/v3/assert"
 )
@@ -512,6 +518,180 @@ func TestStreamClientGetL2BlockByNumber(t *testing.T) {
 	require.Equal(t, expectedFullL2Block, l2Block)
 }
 
+func TestStreamClientBestServer(t *testing.T) {
+	// serveHeader starts an endpoint answering header commands with the given total entries
+	serveHeader := func(totalEntries uint64) string {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { listener.Close() })
+
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				go func() {
+					defer conn.Close()
+					if err := readAndValidateUint(t, conn, uint64(CmdHeader), "command"); err != nil {
+						return
+					}
+					if err := readAndValidateUint(t, conn, uint64(StSequencer), streamTypeFieldName); err != nil {
+						return
+					}
+					he := &types.HeaderEntry{
+						PacketType:   uint8(CmdHeader),
+						HeadLength:   types.HeaderSize,
+						Version:      2,
+						SystemId:     1,
+						StreamType:   types.StreamType(StSequencer),
+						TotalEntries: totalEntries,
+					}
+					conn.Write(append(createResultEntry(t).Encode(), he.Encode()...))
+				}()
+			}
+		}()
+
+		return listener.Addr().String()
+	}
+
+	behind := serveHeader(10)
+	ahead := serveHeader(20)
+
+	// nothing is listening on a closed listener's address
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	down := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	c := NewClient(context.Background(), fmt.Sprintf("%s, %s,%s", down, behind, ahead), 0, 500*time.Millisecond, 0)
+	require.Equal(t, []string{down, behind, ahead}, c.servers)
+
+	require.Equal(t, ahead, c.bestServer(""))
+	require.Equal(t, behind, c.bestServer(ahead))
+
+	// the excluded endpoint is kept when no other one answers
+	c = NewClient(context.Background(), fmt.Sprintf("%s,%s", down, ahead), 0, 500*time.Millisecond, 0)
+	require.Equal(t, ahead, c.bestServer(ahead))
+
+	require.NoError(t, c.Start())
+	defer c.Stop()
+	require.Equal(t, ahead, c.server)
+}
+
+func TestStreamClientFailoverSkipsSentEntries(t *testing.T) {
+	var entries [][]byte
+	bookmarks := make(map[string]int)
+	add := func(entryType types.EntryType, entry interface{ Marshal() ([]byte, error) }) {
+		data, err := entry.Marshal()
+		require.NoError(t, err)
+		if entryType == types.BookmarkEntryType {
+			bookmarks[string(data)] = len(entries)
+		}
+		entries = append(entries, createFileEntry(t, entryType, uint64(len(entries)), data).Encode())
+	}
+	for batch := uint64(1); batch <= 2; batch++ {
+		add(types.BookmarkEntryType, types.NewBookmarkProto(batch, datastream.BookmarkType_BOOKMARK_TYPE_BATCH))
+		add(types.EntryTypeBatchStart, &types.BatchStartProto{BatchStart: &datastream.BatchStart{Number: batch, ForkId: 9}})
+		add(types.EntryTypeGerUpdate, &types.GerUpdateProto{UpdateGER: &datastream.UpdateGER{BatchNumber: batch}})
+		add(types.BookmarkEntryType, types.NewBookmarkProto(batch, datastream.BookmarkType_BOOKMARK_TYPE_L2_BLOCK))
+		add(types.EntryTypeL2Block, &types.L2BlockProto{L2Block: &datastream.L2Block{Number: batch, BatchNumber: batch}})
+		add(types.EntryTypeL2BlockEnd, &types.L2BlockEndProto{Number: batch})
+		add(types.EntryTypeBatchEnd, &types.BatchEndProto{BatchEnd: &datastream.BatchEnd{Number: batch}})
+	}
+
+	// serveStream starts an endpoint streaming the entries from the bookmark asked for, closing the connection
+	// before the entry at cut
+	serveStream := func(cut int) string {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { listener.Close() })
+
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				go func() {
+					defer conn.Close()
+					for {
+						var command, streamType uint64
+						if binary.Read(conn, binary.BigEndian, &command) != nil || binary.Read(conn, binary.BigEndian, &streamType) != nil {
+							return
+						}
+						switch Command(command) {
+						case CmdHeader:
+							he := &types.HeaderEntry{
+								PacketType:   uint8(CmdHeader),
+								HeadLength:   types.HeaderSize,
+								Version:      2,
+								SystemId:     1,
+								StreamType:   types.StreamType(StSequencer),
+								TotalEntries: uint64(len(entries)),
+							}
+							conn.Write(append(createResultEntry(t).Encode(), he.Encode()...))
+						case CmdStartBookmark:
+							var length uint32
+							if binary.Read(conn, binary.BigEndian, &length) != nil {
+								return
+							}
+							bookmark := make([]byte, length)
+							if _, err := io.ReadFull(conn, bookmark); err != nil {
+								return
+							}
+							conn.Write(createResultEntry(t).Encode())
+							for i := bookmarks[string(bookmark)]; i < len(entries); i++ {
+								if i == cut {
+									return
+								}
+								conn.Write(entries[i])
+							}
+						default:
+							return
+						}
+					}
+				}()
+			}
+		}()
+
+		return listener.Addr().String()
+	}
+
+	// the first endpoint goes away right after the start of the second batch, past the first block
+	cut := bookmarks[string(mustMarshal(t, types.NewBookmarkProto(2, datastream.BookmarkType_BOOKMARK_TYPE_L2_BLOCK)))]
+	c := NewClient(context.Background(), fmt.Sprintf("%s,%s", serveStream(cut), serveStream(-1)), 0, 500*time.Millisecond, 0)
+	require.NoError(t, c.Start())
+	require.NoError(t, c.ReadAllEntriesToChannel())
+
+	var read []string
+	for entry := range c.entryChan {
+		switch entry := entry.(type) {
+		case *types.BatchStart:
+			read = append(read, fmt.Sprintf("batch start %d", entry.Number))
+		case *types.GerUpdate:
+			read = append(read, fmt.Sprintf("ger update %d", entry.BatchNumber))
+		case *types.FullL2Block:
+			read = append(read, fmt.Sprintf("block %d", entry.L2BlockNumber))
+		case *types.BatchEnd:
+			read = append(read, fmt.Sprintf("batch end %d", entry.Number))
+		case nil:
+			require.Equal(t, []string{
+				"batch start 1", "ger update 1", "block 1", "batch end 1",
+				"batch start 2", "ger update 2", "block 2", "batch end 2",
+			}, read)
+			return
+		}
+	}
+}
+
+func mustMarshal(t *testing.T, entry interface{ Marshal() ([]byte, error) }) []byte {
+	t.Helper()
+	data, err := entry.Marshal()
+	require.NoError(t, err)
+	return data
+}
+
 // readAndValidateUint reads the uint value and validates it against expected value from the connection in order to unblock future write operations
 func readAndValidateUint(t *testing.T, conn net.Conn, expected interface{}, paramName string) error {
 	t.Helper()
@@ -548,7 +728,7 @@ func readAndValidateUint(t *testing.T, conn net.Conn, expected interface{}, para
 }
 
 // createFileEntry is a helper function that creates FileEntry
-func createFileEntry(t *testing.T, entryType types.EntryType, num uint64, data []byte) *types.FileEntry {
+func createFileEntry(t testing.TB, entryType types.EntryType, num uint64, data []byte) *types.FileEntry {
 	t.Helper()
 	return &types.FileEntry{
 		PacketType: PtData,
@@ -593,3 +773,138 @@ func createL2BlockAndTransactions(t *testing.T, blockNum uint64, txnCount int) (
 
 	return l2Block, txns
 }
+
+// createBlockEntries creates the encoded entries of a batch start and the blocks with their signed transactions, with
+// the hashes of the transactions of each block
+func createBlockEntries(tb testing.TB, blocks, txsPerBlock int) ([][]byte, [][]common.Hash) {
+	tb.Helper()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(tb, err)
+	signer := eritypes.LatestSignerForChainID(big.NewInt(1))
+
+	var entries [][]byte
+	add := func(entryType types.EntryType, entry interface{ Marshal() ([]byte, error) }) {
+		data, err := entry.Marshal()
+		require.NoError(tb, err)
+		entries = append(entries, createFileEntry(tb, entryType, uint64(len(entries)), data).Encode())
+	}
+	add(types.EntryTypeBatchStart, &types.BatchStartProto{BatchStart: &datastream.BatchStart{Number: 1, ForkId: 9, ChainId: 1}})
+
+	hashes := make([][]common.Hash, 0, blocks)
+	for block := 1; block <= blocks; block++ {
+		add(types.EntryTypeL2Block, &types.L2BlockProto{L2Block: &datastream.L2Block{Number: uint64(block), BatchNumber: 1}})
+		var blockHashes []common.Hash
+		for i := 0; i < txsPerBlock; i++ {
+			tx, err := eritypes.SignTx(eritypes.NewTransaction(uint64(block*txsPerBlock+i), common.HexToAddress("0x1234"), uint256.NewInt(1), 21000, uint256.NewInt(1), make([]byte, 128)), *signer, key)
+			require.NoError(tb, err)
+			var encoded bytes.Buffer
+			require.NoError(tb, tx.EncodeRLP(&encoded))
+			add(types.EntryTypeL2Tx, &types.TxProto{Transaction: &datastream.Transaction{L2BlockNumber: uint64(block), Index: uint64(i), IsValid: true, EffectiveGasPricePercentage: 255, Encoded: encoded.Bytes()}})
+			blockHashes = append(blockHashes, tx.Hash())
+		}
+		add(types.EntryTypeL2BlockEnd, &types.L2BlockEndProto{Number: uint64(block)})
+		hashes = append(hashes, blockHashes)
+	}
+	return entries, hashes
+}
+
+// readBlockEntries reads the entries to the channel of a client served them, returning the channel with them
+func readBlockEntries(tb testing.TB, entries [][]byte, decodeWorkers int) chan interface{} {
+	tb.Helper()
+
+	// over a socket rather than a pipe, for the reads to cost what they do against a server
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(tb, err)
+	go func() {
+		serverConn, err := listener.Accept()
+		listener.Close()
+		if err != nil {
+			return
+		}
+		defer serverConn.Close()
+		_, _ = serverConn.Write(bytes.Join(entries, nil))
+		// held open until the client is done with it
+		_, _ = serverConn.Read(make([]byte, 1))
+	}()
+	clientConn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(tb, err)
+	tb.Cleanup(func() { clientConn.Close() })
+
+	c := NewClient(context.Background(), "", 0, 2*time.Second, 0)
+	c.conn = clientConn
+	c.header = &types.HeaderEntry{TotalEntries: uint64(len(entries))}
+	c.SetDecodeWorkers(decodeWorkers)
+
+	go func() {
+		if err := c.readAllFullL2BlocksToChannel(); err != nil {
+			tb.Error(err)
+			c.entryChan <- nil
+		}
+	}()
+	return c.entryChan
+}
+
+func TestStreamClientDecodeWorkers(t *testing.T) {
+	entries, hashes := createBlockEntries(t, 50, 3)
+
+	for _, workers := range []int{0, 4} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			entryChan := readBlockEntries(t, entries, workers)
+
+			batchStart, ok := (<-entryChan).(*types.BatchStart)
+			require.True(t, ok)
+			require.Equal(t, uint64(9), batchStart.ForkId)
+			// the blocks in order, decoded by the workers
+			for block := 1; block <= len(hashes); block++ {
+				l2Block, ok := (<-entryChan).(*types.FullL2Block)
+				require.True(t, ok)
+				require.Equal(t, uint64(block), l2Block.L2BlockNumber)
+				require.Equal(t, uint64(9), l2Block.ForkId)
+				require.Len(t, l2Block.L2Txs, len(hashes[block-1]))
+				if workers == 0 {
+					require.Nil(t, l2Block.DecodedTxs)
+					continue
+				}
+				require.Len(t, l2Block.DecodedTxs, len(hashes[block-1]))
+				for i, tx := range l2Block.DecodedTxs {
+					require.Equal(t, hashes[block-1][i], tx.Hash())
+				}
+			}
+			require.Nil(t, <-entryChan)
+		})
+	}
+}
+
+// benchmarkDecodeWorkers reads the blocks to the channel and decodes their transactions as the batches stage does,
+// taking the ones decoded by the workers
+func benchmarkDecodeWorkers(b *testing.B, decodeWorkers int) {
+	entries, _ := createBlockEntries(b, 200, 20)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		entryChan := readBlockEntries(b, entries, decodeWorkers)
+		for entry := range entryChan {
+			if entry == nil {
+				break
+			}
+			l2Block, ok := entry.(*types.FullL2Block)
+			if !ok || l2Block.DecodedTxs != nil {
+				continue
+			}
+			for _, l2Tx := range l2Block.L2Txs {
+				tx, _, err := txtype.DecodeTx(l2Tx.Encoded, l2Tx.EffectiveGasPricePercentage, l2Block.ForkId)
+				require.NoError(b, err)
+				tx.Hash()
+			}
+		}
+	}
+}
+
+func BenchmarkStreamClientSerialDecode(b *testing.B) {
+	benchmarkDecodeWorkers(b, 0)
+}
+
+func BenchmarkStreamClientDecodeWorkers(b *testing.B) {
+	benchmarkDecodeWorkers(b, 4)
+}
@@ -57,9 +57,9 @@ func writeFullUint32ToConn(conn net.Conn, value uint32) error {
 }
 
 // reads a set amount of bytes from a connection
-func readBuffer(conn net.Conn, n uint32) ([]byte, error) {
+func readBuffer(reader io.Reader, n uint32) ([]byte, error) {
 	buffer := make([]byte, n)
-	rbc, err := io.ReadFull(conn, buffer)
+	rbc, err := io.ReadFull(reader, buffer)
 	if err != nil {
 		return []byte{}, fmt.Errorf("%w: io.ReadFull: %w", ErrSocket, err)
 	}
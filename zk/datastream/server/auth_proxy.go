@@ -0,0 +1,521 @@
+package server
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ledgerwatch/erigon/zk/datastream/client"
+	"github.com/ledgerwatch/erigon/zk/datastream/types"
+	"github.com/ledgerwatch/log/v3"
+)
+
+/*
+the stream server of zkevm-data-streamer only speaks plaintext TCP and has no notion of clients being allowed in or not.
+When the stream is secured it listens on an internal port and the auth proxy takes over the public one: it terminates
+TLS, checks the token of CmdAuth and then pipes the connection through to the stream server untouched.  On a pruned
+stream it also reads the commands going through, answering the bookmarks before the start of the stream with
+CmdErrPruned, which the stream server would only report as a bad bookmark.  Given the stream it serves CmdStartFiltered
+itself, reading the stream file while the stream server connection sits idle until the client stops.  Given a client
+tracker it follows the entries the stream server sends each client.
+*/
+
+const (
+	authTimeout       = 10 * time.Second
+	maxAuthTokenBytes = 1024
+	maxBookmarkBytes  = 1024
+	maxFilterBytes    = 1 << 20
+
+	filteredWriteTimeout = 20 * time.Second
+)
+
+// AuthConfig secures the stream server, the zero value leaves it plaintext and open to anyone
+type AuthConfig struct {
+	// the server certificate and key, TLS is off if empty
+	CertFile string
+	KeyFile  string
+	// clients have to present a certificate signed by this CA, mutual TLS is off if empty
+	ClientCAFile string
+	// clients have to send this token with CmdAuth before anything else, none if empty
+	Token string
+}
+
+func (a AuthConfig) Enabled() bool {
+	return a.CertFile != "" || a.Token != ""
+}
+
+func (a AuthConfig) serverTLSConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(a.CertFile, a.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading the server certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if a.ClientCAFile != "" {
+		pool, err := client.LoadCertPool(a.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
+
+type AuthProxy struct {
+	addr   string
+	target string
+	auth   AuthConfig
+
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	// the start of a pruned stream, both 0 if the stream is not pruned
+	firstBatch, firstBlock uint64
+	// the filtered streams are read from it, not served if nil
+	stream StreamServer
+	// the clients are not tracked if nil
+	clients *ClientTracker
+}
+
+// NewAuthProxy creates a proxy listening on addr and forwarding authenticated connections to the stream server at target
+func NewAuthProxy(addr, target string, auth AuthConfig) *AuthProxy {
+	return &AuthProxy{
+		addr:   addr,
+		target: target,
+		auth:   auth,
+	}
+}
+
+func (p *AuthProxy) Start() error {
+	listener, err := net.Listen("tcp", p.addr)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", p.addr, err)
+	}
+
+	if p.auth.CertFile != "" {
+		tlsConfig, err := p.auth.serverTLSConfig()
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	p.listener = listener
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					log.Warn("[dataStream] Auth proxy stopped accepting connections", "err", err)
+				}
+				return
+			}
+			go p.handle(conn)
+		}
+	}()
+
+	return nil
+}
+
+// SetPrunedBefore makes the proxy answer the bookmarks before the first batch and block of the stream with CmdErrPruned
+func (p *AuthProxy) SetPrunedBefore(firstBatch, firstBlock uint64) {
+	p.firstBatch, p.firstBlock = firstBatch, firstBlock
+}
+
+// ServeFiltered makes the proxy serve CmdStartFiltered from the stream
+func (p *AuthProxy) ServeFiltered(stream StreamServer) {
+	p.stream = stream
+}
+
+// TrackClients makes the proxy follow its clients through the stream
+func (p *AuthProxy) TrackClients(clients *ClientTracker) {
+	p.clients = clients
+}
+
+// Addr is the address the proxy listens on, only set once started
+func (p *AuthProxy) Addr() net.Addr {
+	return p.listener.Addr()
+}
+
+// Close stops accepting connections, the ones already piped through are left to the stream server
+func (p *AuthProxy) Close() error {
+	err := p.listener.Close()
+	p.wg.Wait()
+	return err
+}
+
+func (p *AuthProxy) handle(conn net.Conn) {
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(authTimeout)); err != nil {
+		return
+	}
+	// completes the TLS handshake here rather than on the first read, so a client without a valid certificate is
+	// logged as such
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		if err := tlsConn.Handshake(); err != nil {
+			log.Debug("[dataStream] TLS handshake failed", "remote", conn.RemoteAddr(), "err", err)
+			return
+		}
+	}
+	if p.auth.Token != "" {
+		if err := p.checkToken(conn); err != nil {
+			log.Warn("[dataStream] Rejected datastream client", "remote", conn.RemoteAddr(), "err", err)
+			writeResult(conn, types.CmdErrUnauthorized, err.Error())
+			return
+		}
+		if err := writeResult(conn, types.CmdErrOK, ""); err != nil {
+			return
+		}
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return
+	}
+
+	upstream, err := net.DialTimeout("tcp", p.target, authTimeout)
+	if err != nil {
+		log.Warn("[dataStream] Could not reach the stream server", "target", p.target, "err", err)
+		return
+	}
+	defer upstream.Close()
+
+	tracked := p.clients.connect(ClientKindStream, conn.RemoteAddr().String())
+	defer tracked.disconnect()
+
+	// either side hanging up closes both connections, which ends the other copy
+	downstream := &lockedWriter{w: conn, client: tracked}
+	done := make(chan struct{}, 2)
+	go func() {
+		if p.stream != nil || p.firstBatch > 0 || p.firstBlock > 0 || tracked != nil {
+			p.forwardCommands(upstream, conn, downstream, tracked)
+		} else {
+			io.Copy(upstream, conn)
+		}
+		done <- struct{}{}
+	}()
+	go func() {
+		if tracked != nil {
+			io.Copy(downstream, io.TeeReader(upstream, &packetTracker{client: tracked}))
+		} else {
+			io.Copy(downstream, upstream)
+		}
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// forwardCommands passes the commands of the client on to the stream server, but for the bookmarks pruned from it and
+// the filtered streams
+func (p *AuthProxy) forwardCommands(upstream io.Writer, conn net.Conn, downstream io.Writer, tracked *trackedClient) error {
+	var filtered *filteredStream
+	defer func() {
+		if filtered != nil {
+			filtered.stop()
+		}
+	}()
+
+	for {
+		packet := make([]byte, 16)
+		if _, err := io.ReadFull(conn, packet); err != nil {
+			return err
+		}
+
+		cmd := client.Command(binary.BigEndian.Uint64(packet[:8]))
+		if filtered != nil {
+			// the stream server knows nothing of the filtered stream, the proxy answers while it runs
+			if cmd != client.CmdStop {
+				// the length of what follows the command may not be known, the connection can't go on
+				writeResult(downstream, types.CmdErrAlreadyStarted, "a filtered stream is running")
+				return fmt.Errorf("command %d during a filtered stream", cmd)
+			}
+			filtered.stop()
+			filtered = nil
+			tracked.stopped()
+			if err := conn.SetWriteDeadline(time.Time{}); err != nil {
+				return err
+			}
+			if err := writeResult(downstream, types.CmdErrOK, ""); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch cmd {
+		case client.CmdStartFiltered:
+			bookmark, err := readLengthPrefixed(conn, maxBookmarkBytes)
+			if err != nil {
+				return err
+			}
+			encodedFilter, err := readLengthPrefixed(conn, maxFilterBytes)
+			if err != nil {
+				return err
+			}
+			if filtered, err = p.startFiltered(conn, downstream, bookmark, encodedFilter, tracked); err != nil {
+				return err
+			}
+			continue
+		case client.CmdStop:
+			tracked.stopRequested()
+		case client.CmdHeader:
+		case client.CmdStart, client.CmdEntry:
+			entryNum := make([]byte, 8)
+			if _, err := io.ReadFull(conn, entryNum); err != nil {
+				return err
+			}
+			packet = append(packet, entryNum...)
+			if cmd == client.CmdStart {
+				tracked.startRequested(binary.BigEndian.Uint64(entryNum))
+			}
+		case client.CmdStartBookmark, client.CmdBookmark:
+			bookmark, err := readLengthPrefixed(conn, maxBookmarkBytes)
+			if err != nil {
+				return err
+			}
+
+			// a bookmark that doesn't decode is left to the stream server to turn down
+			if pruned, err := prunedBookmark(bookmark, p.firstBatch, p.firstBlock); err == nil && pruned {
+				msg := fmt.Sprintf("the stream is pruned before batch %d, block %d", p.firstBatch, p.firstBlock)
+				if err := writeResult(downstream, types.CmdErrPruned, msg); err != nil {
+					return err
+				}
+				continue
+			}
+			if cmd == client.CmdStartBookmark && tracked != nil && p.stream != nil {
+				if entryNum, err := p.stream.GetBookmark(bookmark); err == nil {
+					tracked.startRequested(entryNum)
+				}
+			}
+			packet = binary.BigEndian.AppendUint32(packet, uint32(len(bookmark)))
+			packet = append(packet, bookmark...)
+		default:
+			// not a command the proxy knows the length of, the rest of the connection goes through as it is
+			if _, err := upstream.Write(packet); err != nil {
+				return err
+			}
+			_, err := io.Copy(upstream, conn)
+			return err
+		}
+
+		if _, err := upstream.Write(packet); err != nil {
+			return err
+		}
+	}
+}
+
+// readLengthPrefixed reads a uint32 length and that many bytes
+func readLengthPrefixed(conn io.Reader, maxLength uint32) ([]byte, error) {
+	lengthBytes := make([]byte, 4)
+	if _, err := io.ReadFull(conn, lengthBytes); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(lengthBytes)
+	if length > maxLength {
+		return nil, fmt.Errorf("%d bytes is too long, at most %d", length, maxLength)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(conn, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// startFiltered answers CmdStartFiltered and starts streaming the entries, nil if it can't be
+func (p *AuthProxy) startFiltered(conn net.Conn, downstream io.Writer, bookmark, encodedFilter []byte, tracked *trackedClient) (*filteredStream, error) {
+	if p.stream == nil {
+		return nil, writeResult(downstream, types.CmdErrInvalidCommand, "filtered streams are not served")
+	}
+	filter, err := types.DecodeStreamFilter(encodedFilter)
+	if err != nil {
+		return nil, writeResult(downstream, types.CmdErrInvalidCommand, err.Error())
+	}
+	if pruned, err := prunedBookmark(bookmark, p.firstBatch, p.firstBlock); err == nil && pruned {
+		msg := fmt.Sprintf("the stream is pruned before batch %d, block %d", p.firstBatch, p.firstBlock)
+		return nil, writeResult(downstream, types.CmdErrPruned, msg)
+	}
+	entryNum, err := p.stream.GetBookmark(bookmark)
+	if err != nil {
+		return nil, writeResult(downstream, types.CmdErrBadFromBookmark, err.Error())
+	}
+	iterator, err := newFilteredIterator(p.stream, entryNum, filter)
+	if err != nil {
+		return nil, writeResult(downstream, types.CmdErrBadFromBookmark, err.Error())
+	}
+
+	if err := writeResult(downstream, types.CmdErrOK, ""); err != nil {
+		return nil, err
+	}
+	tracked.startAt(entryNum, ClientKindFiltered)
+	f := &filteredStream{quit: make(chan struct{}), done: make(chan struct{}), client: tracked}
+	go func() {
+		defer close(f.done)
+		if err := f.run(iterator, conn, downstream); err != nil {
+			log.Debug("[dataStream] Filtered stream stopped", "remote", conn.RemoteAddr(), "err", err)
+			// the client notices by the connection going away, and starts again from its last bookmark
+			conn.Close()
+		}
+	}()
+	return f, nil
+}
+
+type filteredStream struct {
+	quit   chan struct{}
+	done   chan struct{}
+	client *trackedClient
+}
+
+// run writes the entries of the iterator to the client as the stream server would, following the stream as it is
+// written until stopped
+func (f *filteredStream) run(iterator *filteredIterator, conn net.Conn, downstream io.Writer) error {
+	ticker := time.NewTicker(streamPollInterval)
+	defer ticker.Stop()
+
+	for {
+		file, err := iterator.NextFileEntry()
+		if err != nil {
+			return err
+		}
+		if file == nil {
+			select {
+			case <-f.quit:
+				return nil
+			case <-ticker.C:
+			}
+			if iterator.stream.GetHeader().TotalEntries < iterator.curEntryNum {
+				return fmt.Errorf("the stream was unwound before entry %d", iterator.curEntryNum)
+			}
+			f.client.skippedTo(iterator.curEntryNum)
+			iterator.refresh()
+			continue
+		}
+
+		select {
+		case <-f.quit:
+			return nil
+		default:
+		}
+		file.PacketType = client.PtData
+		file.Length = types.FileEntryMinSize + uint32(len(file.Data))
+		if err := conn.SetWriteDeadline(time.Now().Add(filteredWriteTimeout)); err != nil {
+			return err
+		}
+		if _, err := downstream.Write(file.Encode()); err != nil {
+			return err
+		}
+		f.client.entrySent(file.EntryType, file.EntryNum, file.Data)
+	}
+}
+
+func (f *filteredStream) stop() {
+	close(f.quit)
+	<-f.done
+}
+
+// lockedWriter keeps the answers of the proxy from interleaving with the writes of the stream server, counting the
+// bytes written to the client
+type lockedWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	client *trackedClient
+}
+
+func (l *lockedWriter) Write(b []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n, err := l.w.Write(b)
+	l.client.wrote(n)
+	return n, err
+}
+
+// checkToken reads CmdAuth, stream type, token length and token off the connection
+func (p *AuthProxy) checkToken(conn net.Conn) error {
+	command := make([]byte, 16)
+	if _, err := io.ReadFull(conn, command); err != nil {
+		return fmt.Errorf("reading the auth command: %w", err)
+	}
+	if cmd := client.Command(binary.BigEndian.Uint64(command[:8])); cmd != client.CmdAuth {
+		return fmt.Errorf("expected the auth command, got %d", cmd)
+	}
+
+	lengthBytes := make([]byte, 4)
+	if _, err := io.ReadFull(conn, lengthBytes); err != nil {
+		return fmt.Errorf("reading the token length: %w", err)
+	}
+	length := binary.BigEndian.Uint32(lengthBytes)
+	if length > maxAuthTokenBytes {
+		return fmt.Errorf("token of %d bytes is too long", length)
+	}
+	token := make([]byte, length)
+	if _, err := io.ReadFull(conn, token); err != nil {
+		return fmt.Errorf("reading the token: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare(token, []byte(p.auth.Token)) != 1 {
+		return errors.New("invalid token")
+	}
+	return nil
+}
+
+func writeResult(conn io.Writer, errorNum uint32, errorStr string) error {
+	re := &types.ResultEntry{
+		PacketType: client.PtResult,
+		Length:     types.ResultEntryMinSize + uint32(len(errorStr)),
+		ErrorNum:   errorNum,
+		ErrorStr:   []byte(errorStr),
+	}
+	_, err := conn.Write(re.Encode())
+	return err
+}
+
+// authStreamServer starts the auth proxy alongside the stream server
+type authStreamServer struct {
+	StreamServer
+	proxy *AuthProxy
+}
+
+// NewAuthStreamServer puts the auth proxy listening on addr in front of the stream server listening on internalPort,
+// tracking its clients with clients unless nil
+func NewAuthStreamServer(stream StreamServer, addr string, internalPort uint16, auth AuthConfig, clients *ClientTracker) StreamServer {
+	proxy := NewAuthProxy(addr, fmt.Sprintf("127.0.0.1:%d", internalPort), auth)
+	proxy.TrackClients(clients)
+	return &authStreamServer{
+		StreamServer: stream,
+		proxy:        proxy,
+	}
+}
+
+func (s *authStreamServer) Start() error {
+	if err := s.StreamServer.Start(); err != nil {
+		return err
+	}
+	// the stream server does not check any token, none but the proxy may reach it
+	if err := keepToLoopback(s.StreamServer); err != nil {
+		return fmt.Errorf("keeping the internal data stream port to loopback: %w", err)
+	}
+
+	// the stream is only ever pruned while the node is stopped
+	firstBatch, firstBlock, err := StreamStart(s.StreamServer)
+	if err != nil {
+		return fmt.Errorf("StreamStart: %w", err)
+	}
+	if firstBatch > 0 || firstBlock > 0 {
+		log.Info("[dataStream] The stream is pruned", "firstBatch", firstBatch, "firstBlock", firstBlock)
+		s.proxy.SetPrunedBefore(firstBatch, firstBlock)
+	}
+	s.proxy.ServeFiltered(s.StreamServer)
+
+	return s.proxy.Start()
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ledgerwatch/erigon/zk/datastream/client"
+	"github.com/ledgerwatch/erigon/zk/datastream/types"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSelfSignedCert writes a certificate for 127.0.0.1 good for both ends of a connection, and as its own CA
+func writeSelfSignedCert(t *testing.T, dir, name string) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return certFile, keyFile
+}
+
+// serveHeader stands in for the stream server, answering header commands with the given total entries
+func serveHeader(t *testing.T, totalEntries uint64) uint16 {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				for {
+					command := make([]byte, 16)
+					if _, err := io.ReadFull(conn, command); err != nil {
+						return
+					}
+					if client.Command(binary.BigEndian.Uint64(command[:8])) != client.CmdHeader {
+						return
+					}
+					re := &types.ResultEntry{PacketType: client.PtResult, Length: types.ResultEntryMinSize}
+					he := &types.HeaderEntry{
+						PacketType:   uint8(client.CmdHeader),
+						HeadLength:   types.HeaderSize,
+						Version:      2,
+						SystemId:     1,
+						StreamType:   types.StreamType(client.StSequencer),
+						TotalEntries: totalEntries,
+					}
+					conn.Write(append(re.Encode(), he.Encode()...))
+				}
+			}()
+		}
+	}()
+
+	return uint16(listener.Addr().(*net.TCPAddr).Port)
+}
+
+func TestAuthProxy(t *testing.T) {
+	dir := t.TempDir()
+	serverCert, serverKey := writeSelfSignedCert(t, dir, "server")
+	clientCert, clientKey := writeSelfSignedCert(t, dir, "client")
+	otherCert, otherKey := writeSelfSignedCert(t, dir, "other")
+
+	internalPort := serveHeader(t, 42)
+
+	startProxy := func(auth AuthConfig) string {
+		proxy := NewAuthProxy("127.0.0.1:0", net.JoinHostPort("127.0.0.1", strconv.Itoa(int(internalPort))), auth)
+		require.NoError(t, proxy.Start())
+		t.Cleanup(func() { proxy.Close() })
+		return proxy.Addr().String()
+	}
+	getHeader := func(addr string, auth client.AuthConfig) (uint64, error) {
+		c := client.NewClient(context.Background(), addr, 0, 500*time.Millisecond, 0)
+		c.SetAuth(auth)
+		if err := c.Start(); err != nil {
+			return 0, err
+		}
+		defer c.Stop()
+		header, err := c.GetHeader()
+		if err != nil {
+			return 0, err
+		}
+		return header.TotalEntries, nil
+	}
+
+	t.Run("token", func(t *testing.T) {
+		addr := startProxy(AuthConfig{Token: "secret"})
+
+		entries, err := getHeader(addr, client.AuthConfig{Token: "secret"})
+		require.NoError(t, err)
+		require.Equal(t, uint64(42), entries)
+
+		_, err = getHeader(addr, client.AuthConfig{Token: "wrong"})
+		require.ErrorIs(t, err, types.ErrUnauthorized)
+
+		// a client without a token sends the header command first
+		_, err = getHeader(addr, client.AuthConfig{})
+		require.ErrorIs(t, err, types.ErrUnauthorized)
+	})
+
+	t.Run("mutual TLS and token", func(t *testing.T) {
+		addr := startProxy(AuthConfig{CertFile: serverCert, KeyFile: serverKey, ClientCAFile: clientCert, Token: "secret"})
+
+		entries, err := getHeader(addr, client.AuthConfig{TLS: true, CAFile: serverCert, CertFile: clientCert, KeyFile: clientKey, Token: "secret"})
+		require.NoError(t, err)
+		require.Equal(t, uint64(42), entries)
+
+		// a certificate of another CA, and none at all
+		_, err = getHeader(addr, client.AuthConfig{TLS: true, CAFile: serverCert, CertFile: otherCert, KeyFile: otherKey, Token: "secret"})
+		require.Error(t, err)
+		_, err = getHeader(addr, client.AuthConfig{TLS: true, CAFile: serverCert, Token: "secret"})
+		require.Error(t, err)
+
+		// the server certificate is not signed by the CA
+		_, err = getHeader(addr, client.AuthConfig{TLS: true, CAFile: otherCert, CertFile: clientCert, KeyFile: clientKey, Token: "secret"})
+		require.Error(t, err)
+
+		// plaintext is refused
+		_, err = getHeader(addr, client.AuthConfig{Token: "secret"})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,471 @@
+package server
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
+	"github.com/ledgerwatch/erigon/zk/datastream/client"
+	"github.com/ledgerwatch/erigon/zk/datastream/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+/*
+the stream server of zkevm-data-streamer keeps its clients to itself, so the clients are tracked where they go through
+code of ours: the connections of the auth proxy, whose packets from the stream server are read on the way for the
+entries sent, the filtered streams and the JSON bridge.  The clients are only tracked behind the auth proxy, where none
+connects to the stream server directly: its internal port is kept to loopback.  The lag of a client is worked out
+against the stream when asked for, rather than on every entry written.
+*/
+
+const (
+	ClientKindStream   = "stream"
+	ClientKindFiltered = "filtered"
+	ClientKindBridge   = "bridge"
+
+	// the batch is taken from the batch starts and blocks sent through the proxy, buffered up to this size
+	maxTrackedPacketSize = 64 * 1024
+)
+
+var (
+	clientLabels = []string{"id", "kind", "remote"}
+
+	clientsDesc            = prometheus.NewDesc("datastream_clients", "Clients connected to the datastream", nil, nil)
+	clientConnectedDesc    = prometheus.NewDesc("datastream_client_connected_timestamp_seconds", "Time the client connected", clientLabels, nil)
+	clientCurrentEntryDesc = prometheus.NewDesc("datastream_client_current_entry", "Entry the client is sent next", clientLabels, nil)
+	clientEntryLagDesc     = prometheus.NewDesc("datastream_client_entry_lag", "Entries written to the stream that the client has yet to be sent", clientLabels, nil)
+	clientBatchLagDesc     = prometheus.NewDesc("datastream_client_batch_lag", "Batches started in the stream after the one the client is in", clientLabels, nil)
+	clientSentBytesDesc    = prometheus.NewDesc("datastream_client_sent_bytes_total", "Bytes sent to the client", clientLabels, nil)
+)
+
+// DatastreamClient is a client of the stream, the entries and batches are null until it starts streaming and its
+// batch is known
+type DatastreamClient struct {
+	Id           hexutil.Uint64  `json:"id"`
+	Kind         string          `json:"kind"`
+	RemoteAddr   string          `json:"remoteAddr"`
+	ConnectedAt  hexutil.Uint64  `json:"connectedAt"`
+	StartEntry   *hexutil.Uint64 `json:"startEntry"`
+	CurrentEntry *hexutil.Uint64 `json:"currentEntry"`
+	EntryLag     *hexutil.Uint64 `json:"entryLag"`
+	CurrentBatch *hexutil.Uint64 `json:"currentBatch"`
+	BatchLag     *hexutil.Uint64 `json:"batchLag"`
+	BytesSent    hexutil.Uint64  `json:"bytesSent"`
+}
+
+// ClientTracker keeps the clients of the stream, it is a prometheus collector of their metrics
+type ClientTracker struct {
+	stream StreamServer
+
+	mu      sync.Mutex
+	nextId  uint64
+	clients map[uint64]*trackedClient
+
+	// the last batch start of the stream, found scanning back from the end and then only through the entries since
+	scanMu         sync.Mutex
+	scannedEntries uint64
+	lastBatch      uint64
+	lastBatchFound bool
+}
+
+func NewClientTracker(stream StreamServer) *ClientTracker {
+	return &ClientTracker{
+		stream:  stream,
+		clients: make(map[uint64]*trackedClient),
+	}
+}
+
+// connect starts tracking a client, a nil tracker tracks nothing
+func (t *ClientTracker) connect(kind, remoteAddr string) *trackedClient {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.nextId++
+	c := &trackedClient{
+		tracker:     t,
+		id:          t.nextId,
+		remoteAddr:  remoteAddr,
+		connectedAt: time.Now(),
+		kind:        kind,
+	}
+	t.clients[c.id] = c
+	return c
+}
+
+// Clients are the clients connected, in the order they connected
+func (t *ClientTracker) Clients() ([]DatastreamClient, error) {
+	totalEntries := t.stream.GetHeader().TotalEntries
+	lastBatch, lastBatchFound, err := t.lastBatchStart(totalEntries)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	tracked := make([]*trackedClient, 0, len(t.clients))
+	for _, c := range t.clients {
+		tracked = append(tracked, c)
+	}
+	t.mu.Unlock()
+	sort.Slice(tracked, func(i, j int) bool { return tracked[i].id < tracked[j].id })
+
+	clients := make([]DatastreamClient, 0, len(tracked))
+	for _, c := range tracked {
+		clients = append(clients, c.snapshot(totalEntries, lastBatch, lastBatchFound))
+	}
+	return clients, nil
+}
+
+// lastBatchStart is the number of the last batch started in the stream, if any
+func (t *ClientTracker) lastBatchStart(totalEntries uint64) (uint64, bool, error) {
+	t.scanMu.Lock()
+	defer t.scanMu.Unlock()
+
+	from := t.scannedEntries
+	if totalEntries < t.scannedEntries {
+		// unwound, the batch start may be gone
+		from, t.lastBatchFound = 0, false
+	}
+	for entryNum := totalEntries; entryNum > from; entryNum-- {
+		entry, err := t.stream.GetEntry(entryNum - 1)
+		if err != nil {
+			return 0, false, fmt.Errorf("entry %d: %w", entryNum-1, err)
+		}
+		if types.EntryType(entry.Type) != types.EntryTypeBatchStart {
+			continue
+		}
+		batchStart, err := types.UnmarshalBatchStart(entry.Data)
+		if err != nil {
+			return 0, false, fmt.Errorf("entry %d: %w", entryNum-1, err)
+		}
+		t.lastBatch, t.lastBatchFound = batchStart.Number, true
+		break
+	}
+	t.scannedEntries = totalEntries
+	return t.lastBatch, t.lastBatchFound, nil
+}
+
+func (t *ClientTracker) Describe(ch chan<- *prometheus.Desc) {
+	ch <- clientsDesc
+	ch <- clientConnectedDesc
+	ch <- clientCurrentEntryDesc
+	ch <- clientEntryLagDesc
+	ch <- clientBatchLagDesc
+	ch <- clientSentBytesDesc
+}
+
+func (t *ClientTracker) Collect(ch chan<- prometheus.Metric) {
+	clients, err := t.Clients()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(clientsDesc, err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(clientsDesc, prometheus.GaugeValue, float64(len(clients)))
+	for _, c := range clients {
+		labels := []string{fmt.Sprint(uint64(c.Id)), c.Kind, c.RemoteAddr}
+		ch <- prometheus.MustNewConstMetric(clientConnectedDesc, prometheus.GaugeValue, float64(c.ConnectedAt), labels...)
+		ch <- prometheus.MustNewConstMetric(clientSentBytesDesc, prometheus.CounterValue, float64(c.BytesSent), labels...)
+		if c.CurrentEntry != nil {
+			ch <- prometheus.MustNewConstMetric(clientCurrentEntryDesc, prometheus.GaugeValue, float64(*c.CurrentEntry), labels...)
+			ch <- prometheus.MustNewConstMetric(clientEntryLagDesc, prometheus.GaugeValue, float64(*c.EntryLag), labels...)
+		}
+		if c.BatchLag != nil {
+			ch <- prometheus.MustNewConstMetric(clientBatchLagDesc, prometheus.GaugeValue, float64(*c.BatchLag), labels...)
+		}
+	}
+}
+
+// trackedClient is where a client is in the stream, all its methods do nothing on a nil client
+type trackedClient struct {
+	tracker     *ClientTracker
+	id          uint64
+	remoteAddr  string
+	connectedAt time.Time
+
+	mu         sync.Mutex
+	kind       string
+	streaming  bool
+	startEntry uint64
+	nextEntry  uint64
+	batch      uint64
+	batchKnown bool
+	bytesSent  uint64
+	// a start or stop was sent on to the stream server, streaming starts or ends with its result
+	starting      bool
+	startingEntry uint64
+	stopping      bool
+}
+
+func (c *trackedClient) disconnect() {
+	if c == nil {
+		return
+	}
+	c.tracker.mu.Lock()
+	defer c.tracker.mu.Unlock()
+	delete(c.tracker.clients, c.id)
+}
+
+// startAt starts streaming from the entry, the batch is known once an entry of it is sent
+func (c *trackedClient) startAt(entryNum uint64, kind string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.startLocked(entryNum)
+	c.kind = kind
+}
+
+func (c *trackedClient) startLocked(entryNum uint64) {
+	c.streaming, c.starting, c.stopping = true, false, false
+	c.startEntry, c.nextEntry = entryNum, entryNum
+	c.batchKnown = false
+}
+
+// entrySent moves the client past the entry, starting it if it wasn't streaming yet
+func (c *trackedClient) entrySent(entryType types.EntryType, entryNum uint64, data []byte) {
+	if c == nil {
+		return
+	}
+	var batch uint64
+	var batchKnown bool
+	switch entryType {
+	case types.EntryTypeBatchStart:
+		if batchStart, err := types.UnmarshalBatchStart(data); err == nil {
+			batch, batchKnown = batchStart.Number, true
+		}
+	case types.EntryTypeL2Block:
+		if l2Block, err := types.UnmarshalL2Block(data); err == nil {
+			batch, batchKnown = l2Block.BatchNumber, true
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.streaming {
+		c.startLocked(entryNum)
+	}
+	c.nextEntry = entryNum + 1
+	if batchKnown {
+		c.batch, c.batchKnown = batch, true
+	}
+}
+
+// messageSent moves the client past the entry of a message of the JSON bridge
+func (c *trackedClient) messageSent(msg *BridgeMessage) {
+	if c == nil {
+		return
+	}
+	var batch uint64
+	var batchKnown bool
+	switch data := msg.Data.(type) {
+	case *BridgeBatchStart:
+		batch, batchKnown = uint64(data.Number), true
+	case *BridgeL2Block:
+		batch, batchKnown = uint64(data.BatchNumber), true
+	case *BridgeBatchEnd:
+		batch, batchKnown = uint64(data.Number), true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextEntry = uint64(msg.Entry) + 1
+	if batchKnown {
+		c.batch, c.batchKnown = batch, true
+	}
+}
+
+// skippedTo moves the client to the entry without sending it anything, the entries before are filtered out
+func (c *trackedClient) skippedTo(entryNum uint64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.streaming && entryNum > c.nextEntry {
+		c.nextEntry = entryNum
+	}
+}
+
+func (c *trackedClient) startRequested(entryNum uint64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.starting, c.startingEntry = true, entryNum
+}
+
+func (c *trackedClient) stopRequested() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopping = true
+}
+
+func (c *trackedClient) stopped() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.streaming, c.stopping = false, false
+	if c.kind == ClientKindFiltered {
+		c.kind = ClientKindStream
+	}
+}
+
+// result is a command result sent on to the client, the one of a start or a stop starts or ends streaming
+func (c *trackedClient) result(ok bool) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case c.starting && ok:
+		c.startLocked(c.startingEntry)
+	case c.stopping && ok:
+		c.streaming = false
+	}
+	c.starting, c.stopping = false, false
+}
+
+func (c *trackedClient) wrote(n int) {
+	if c == nil || n <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bytesSent += uint64(n)
+}
+
+func (c *trackedClient) snapshot(totalEntries, lastBatch uint64, lastBatchFound bool) DatastreamClient {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dc := DatastreamClient{
+		Id:          hexutil.Uint64(c.id),
+		Kind:        c.kind,
+		RemoteAddr:  c.remoteAddr,
+		ConnectedAt: hexutil.Uint64(c.connectedAt.Unix()),
+		BytesSent:   hexutil.Uint64(c.bytesSent),
+	}
+	if c.streaming {
+		startEntry, currentEntry := hexutil.Uint64(c.startEntry), hexutil.Uint64(c.nextEntry)
+		var entryLag hexutil.Uint64
+		if totalEntries > c.nextEntry {
+			entryLag = hexutil.Uint64(totalEntries - c.nextEntry)
+		}
+		dc.StartEntry, dc.CurrentEntry, dc.EntryLag = &startEntry, &currentEntry, &entryLag
+
+		if c.batchKnown {
+			currentBatch := hexutil.Uint64(c.batch)
+			dc.CurrentBatch = &currentBatch
+			if lastBatchFound {
+				var batchLag hexutil.Uint64
+				if lastBatch > c.batch {
+					batchLag = hexutil.Uint64(lastBatch - c.batch)
+				}
+				dc.BatchLag = &batchLag
+			}
+		}
+	}
+	return dc
+}
+
+// packetTracker reads the packets the stream server sends a client through the proxy, following the entries sent
+type packetTracker struct {
+	client *trackedClient
+	// the start of the packet being read, and the bytes left of it once what's needed of it is read
+	head []byte
+	skip uint32
+	// a packet that can't be made sense of, nothing more is followed
+	lost bool
+}
+
+func (t *packetTracker) Write(b []byte) (int, error) {
+	n := len(b)
+	for len(b) > 0 && !t.lost {
+		if t.skip > 0 {
+			skipped := uint32(len(b))
+			if skipped > t.skip {
+				skipped = t.skip
+			}
+			t.skip -= skipped
+			b = b[skipped:]
+			continue
+		}
+
+		need, length, ok := t.need()
+		if !ok {
+			t.lost = true
+			break
+		}
+		if len(t.head) < need {
+			take := need - len(t.head)
+			if take > len(b) {
+				take = len(b)
+			}
+			t.head = append(t.head, b[:take]...)
+			b = b[take:]
+			continue
+		}
+
+		t.packet(length)
+		t.skip = length - uint32(len(t.head))
+		t.head = t.head[:0]
+	}
+	// the copy to the client goes on whatever the packets
+	return n, nil
+}
+
+// need is how much of the packet is read before it is followed, growing as more of it is known.  It is not ok for a
+// packet shorter than its head
+func (t *packetTracker) need() (int, uint32, bool) {
+	if len(t.head) < 5 {
+		return 5, 0, true
+	}
+	length := binary.BigEndian.Uint32(t.head[1:5])
+	switch t.head[0] {
+	case client.PtData:
+	case client.PtResult:
+		return int(types.ResultEntryMinSize), length, length >= types.ResultEntryMinSize
+	default:
+		return 5, length, length >= 5
+	}
+
+	if length < types.FileEntryMinSize {
+		return 0, 0, false
+	}
+	if len(t.head) < int(types.FileEntryMinSize) {
+		return int(types.FileEntryMinSize), length, true
+	}
+	entryType := types.EntryType(binary.BigEndian.Uint32(t.head[5:9]))
+	if (entryType == types.EntryTypeBatchStart || entryType == types.EntryTypeL2Block) && length <= maxTrackedPacketSize {
+		return int(length), length, true
+	}
+	return int(types.FileEntryMinSize), length, true
+}
+
+func (t *packetTracker) packet(length uint32) {
+	switch t.head[0] {
+	case client.PtData:
+		entryType := types.EntryType(binary.BigEndian.Uint32(t.head[5:9]))
+		entryNum := binary.BigEndian.Uint64(t.head[9:17])
+		var data []byte
+		if uint32(len(t.head)) == length {
+			data = t.head[types.FileEntryMinSize:]
+		}
+		t.client.entrySent(entryType, entryNum, data)
+	case client.PtResult:
+		t.client.result(binary.BigEndian.Uint32(t.head[5:9]) == types.CmdErrOK)
+	}
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-data-streamer/datastreamer"
+	dslog "github.com/0xPolygonHermez/zkevm-data-streamer/log"
+	"github.com/gorilla/websocket"
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
+	"github.com/ledgerwatch/erigon/zk/datastream/client"
+	"github.com/ledgerwatch/erigon/zk/datastream/proto/github.com/0xPolygonHermez/zkevm-node/state/datastream"
+	"github.com/ledgerwatch/erigon/zk/datastream/types"
+	"github.com/stretchr/testify/require"
+)
+
+func uint64Ptr(n uint64) *hexutil.Uint64 {
+	h := hexutil.Uint64(n)
+	return &h
+}
+
+func TestDatastreamClients(t *testing.T) {
+	internalPort := freePort(t)
+	stream, err := NewZkEVMDataStreamServerFactory().CreateStreamServer(internalPort, types.EntriesHashVersion, 1, datastreamer.StreamType(1), filepath.Join(t.TempDir(), "data-stream"), time.Second, time.Minute, time.Minute, &dslog.Config{Level: "warn"})
+	require.NoError(t, err)
+	require.NoError(t, stream.Start())
+	srv := NewZkEVMDataStreamServerFactory().CreateDataStreamServer(stream, 1, types.EntriesHashVersion).(*ZkEVMDataStreamServer)
+	writeTestBatch(t, srv, 1, 1)
+	writeTestBatch(t, srv, 2, 3)
+	totalEntries := stream.GetHeader().TotalEntries
+	bookmark, err := types.NewBookmarkProto(2, datastream.BookmarkType_BOOKMARK_TYPE_BATCH).Marshal()
+	require.NoError(t, err)
+	batch2, err := stream.GetBookmark(bookmark)
+	require.NoError(t, err)
+	tracker := NewClientTracker(stream)
+
+	// the packets of the stream server, split anywhere, up to the end of batch 1
+	tracked := tracker.connect(ClientKindStream, "10.0.0.1:1234")
+	tracked.startRequested(0)
+	packets := (&types.ResultEntry{PacketType: client.PtResult, Length: types.ResultEntryMinSize, ErrorNum: types.CmdErrOK}).Encode()
+	for entryNum := uint64(0); entryNum < batch2; entryNum++ {
+		entry, err := stream.GetEntry(entryNum)
+		require.NoError(t, err)
+		file := &types.FileEntry{PacketType: client.PtData, Length: types.FileEntryMinSize + uint32(len(entry.Data)), EntryType: types.EntryType(entry.Type), EntryNum: entry.Number, Data: entry.Data}
+		packets = append(packets, file.Encode()...)
+	}
+	packetTracker := &packetTracker{client: tracked}
+	for len(packets) > 0 {
+		n := min(3, len(packets))
+		_, err := packetTracker.Write(packets[:n])
+		require.NoError(t, err)
+		packets = packets[n:]
+	}
+	clients, err := tracker.Clients()
+	require.NoError(t, err)
+	require.Len(t, clients, 1)
+	require.Equal(t, "10.0.0.1:1234", clients[0].RemoteAddr)
+	require.Equal(t, uint64Ptr(0), clients[0].StartEntry)
+	require.Equal(t, uint64Ptr(batch2), clients[0].CurrentEntry)
+	require.Equal(t, uint64Ptr(totalEntries-batch2), clients[0].EntryLag)
+	require.Equal(t, uint64Ptr(1), clients[0].CurrentBatch)
+	require.Equal(t, uint64Ptr(1), clients[0].BatchLag)
+	tracked.disconnect()
+
+	// the clients of the proxy and the bridge, all the way through the stream
+	proxy := NewAuthProxy("127.0.0.1:0", net.JoinHostPort("127.0.0.1", strconv.Itoa(int(internalPort))), AuthConfig{})
+	proxy.TrackClients(tracker)
+	require.NoError(t, proxy.Start())
+	defer proxy.Close()
+	bridge := NewDataStreamBridge("127.0.0.1:0", stream, AuthConfig{}, time.Second)
+	bridge.TrackClients(tracker)
+	require.NoError(t, bridge.Start())
+	defer bridge.Close()
+
+	c := client.NewClient(context.Background(), proxy.Addr().String(), 0, 2*time.Second, 0)
+	require.NoError(t, c.Start())
+	defer c.Stop()
+	errDone := errors.New("done")
+	err = c.ExecutePerFile(types.NewBookmarkProto(2, datastream.BookmarkType_BOOKMARK_TYPE_BATCH), func(file *types.FileEntry) error {
+		if file.EntryNum == totalEntries-1 {
+			return errDone
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, errDone)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+bridge.Addr().String()+"/datastream?block=2", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.Eventually(t, func() bool {
+		clients, err := tracker.Clients()
+		require.NoError(t, err)
+		if len(clients) != 2 || clients[0].EntryLag == nil || clients[1].EntryLag == nil {
+			return false
+		}
+		return clients[0].Kind == ClientKindStream && *clients[0].StartEntry == hexutil.Uint64(batch2) &&
+			clients[1].Kind == ClientKindBridge && clients[1].BytesSent > 0 &&
+			*clients[0].EntryLag == 0 && *clients[1].EntryLag == 0 && *clients[0].BatchLag == 0 && *clients[1].BatchLag == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// asking for the header stops the streaming of the client, hanging up ends the tracking
+	_, err = c.GetHeader()
+	require.NoError(t, err)
+	conn.Close()
+	require.Eventually(t, func() bool {
+		clients, err := tracker.Clients()
+		require.NoError(t, err)
+		return len(clients) == 1 && clients[0].Kind == ClientKindStream && clients[0].CurrentEntry == nil && clients[0].BytesSent > 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -31,6 +31,7 @@ type DbReader interface {
 	GetEffectiveGasPricePercentage(txHash libcommon.Hash) (uint8, error)
 	GetHighestBlockInBatch(batchNumber uint64) (uint64, bool, error)
 	GetInvalidBatch(batchNumber uint64) (bool, error)
+	GetBatchForcedBatch(batchNo uint64) (uint64, bool, error)
 	GetBatchNoByL2Block(blockNumber uint64) (uint64, error)
 	CheckBatchNoByL2Block(l2BlockNo uint64) (uint64, bool, error)
 	GetPreviousIndexBlock(blockNumber uint64) (uint64, uint64, bool, error)
@@ -46,9 +47,13 @@ const (
 type ZkEVMDataStreamServer struct {
 	streamServer StreamServer
 	chainId      uint64
+	version      uint8
 	highestBlockWritten,
 	highestClosedBatchWritten,
 	highestBatchWritten *uint64
+	// the entries hash of the stream as of entriesHashTotal entries, and the one including the atomic op in progress
+	entriesHash, pendingEntriesHash *types.EntriesHash
+	entriesHashTotal                uint64
 }
 
 type DataStreamEntry interface {
@@ -72,10 +77,11 @@ func (f *ZkEVMDataStreamServerFactory) CreateStreamServer(port uint16, version u
 	return datastreamer.NewServer(port, version, systemID, streamType, fileName, writeTimeout, inactivityTimeout, inactivityCheckInterval, cfg)
 }
 
-func (f *ZkEVMDataStreamServerFactory) CreateDataStreamServer(streamServer StreamServer, chainId uint64) DataStreamServer {
+func (f *ZkEVMDataStreamServerFactory) CreateDataStreamServer(streamServer StreamServer, chainId uint64, version uint8) DataStreamServer {
 	return &ZkEVMDataStreamServer{
 		streamServer:        streamServer,
 		chainId:             chainId,
+		version:             version,
 		highestBlockWritten: nil,
 		highestBatchWritten: nil,
 	}
@@ -138,11 +144,64 @@ func NewDataStreamEntries(size int) *DataStreamEntries {
 	}
 }
 
+// startAtomicOp starts an atomic op on the stream, from version 4 on rebuilding the entries hash from the stream first
+// when it was written or unwound since the last commit of this server
+func (srv *ZkEVMDataStreamServer) startAtomicOp() error {
+	if srv.version >= types.EntriesHashVersion {
+		totalEntries := srv.streamServer.GetHeader().TotalEntries
+		if srv.entriesHash == nil || srv.entriesHashTotal != totalEntries {
+			entriesHash, err := srv.loadEntriesHash()
+			if err != nil {
+				return fmt.Errorf("loadEntriesHash: %w", err)
+			}
+			srv.entriesHash, srv.entriesHashTotal = entriesHash, totalEntries
+		}
+		pending := *srv.entriesHash
+		srv.pendingEntriesHash = &pending
+	}
+
+	return srv.streamServer.StartAtomicOp()
+}
+
+// loadEntriesHash hashes the entries after the last batch end of the stream, starting from the hash it carries
+func (srv *ZkEVMDataStreamServer) loadEntriesHash() (*types.EntriesHash, error) {
+	entriesHash := &types.EntriesHash{}
+
+	from := uint64(0)
+	entry, found, err := srv.getLastEntryOfType(datastreamer.EntryType(types.EntryTypeBatchEnd))
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		batchEnd, err := types.UnmarshalBatchEnd(entry.Data)
+		if err != nil {
+			return nil, err
+		}
+		entriesHash.Reset(batchEnd.EntriesHash)
+		from = entry.Number + 1
+	}
+
+	totalEntries := srv.streamServer.GetHeader().TotalEntries
+	for entryNum := from; entryNum < totalEntries; entryNum++ {
+		if entry, err = srv.streamServer.GetEntry(entryNum); err != nil {
+			return nil, err
+		}
+		entriesHash.Add(types.EntryType(entry.Type), entry.Data)
+	}
+
+	return entriesHash, nil
+}
+
 func (srv *ZkEVMDataStreamServer) commitAtomicOp(latestBlockNum, latestBatchNum, latestClosedBatch *uint64) error {
 	if err := srv.streamServer.CommitAtomicOp(); err != nil {
 		return err
 	}
 
+	if srv.pendingEntriesHash != nil {
+		srv.entriesHash, srv.entriesHashTotal = srv.pendingEntriesHash, srv.streamServer.GetHeader().TotalEntries
+		srv.pendingEntriesHash = nil
+	}
+
 	// copy the values in case they are changed outside the function
 	// pointers are used for easier check if we should set check them from the DS or not
 	// since 0 is a valid number, we can't use it
@@ -178,6 +237,15 @@ func (srv *ZkEVMDataStreamServer) commitEntriesToStreamProto(entries []DataStrea
 				return err
 			}
 		} else {
+			if srv.pendingEntriesHash != nil {
+				if entryType == types.EntryTypeBatchEnd {
+					if em, err = srv.pendingEntriesHash.SealBatchEnd(em); err != nil {
+						return err
+					}
+				} else {
+					srv.pendingEntriesHash.Add(entryType, em)
+				}
+			}
 			if _, err = srv.streamServer.AddStreamEntry(datastreamer.EntryType(entryType), em); err != nil {
 				return err
 			}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-data-streamer/datastreamer"
+	dslog "github.com/0xPolygonHermez/zkevm-data-streamer/log"
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon/zk/datastream/proto/github.com/0xPolygonHermez/zkevm-node/state/datastream"
+	"github.com/ledgerwatch/erigon/zk/datastream/types"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestBatch(t *testing.T, srv *ZkEVMDataStreamServer, batch, fromBlock uint64) {
+	t.Helper()
+
+	entries := []DataStreamEntryProto{
+		newBatchBookmarkEntryProto(batch),
+		newBatchStartProto(batch, 1, 9, datastream.BatchType_BATCH_TYPE_REGULAR),
+	}
+	for block := fromBlock; block < fromBlock+2; block++ {
+		entries = append(entries,
+			newL2BlockBookmarkEntryProto(block),
+			&types.L2BlockProto{L2Block: &datastream.L2Block{Number: block, BatchNumber: batch}},
+			newL2BlockEndProto(block),
+		)
+	}
+	entries = append(entries, newBatchEndProto(libcommon.Hash{}, libcommon.Hash{byte(batch)}, batch))
+
+	require.NoError(t, srv.startAtomicOp())
+	require.NoError(t, srv.commitEntriesToStreamProto(entries))
+	require.NoError(t, srv.commitAtomicOp(nil, &batch, &batch))
+}
+
+func TestWriteEntriesHash(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "data-stream")
+	stream, err := NewZkEVMDataStreamServerFactory().CreateStreamServer(0, types.EntriesHashVersion, 1, datastreamer.StreamType(1), file, time.Second, time.Second, time.Second, &dslog.Config{Level: "warn"})
+	require.NoError(t, err)
+	require.NoError(t, stream.Start())
+
+	srv := NewZkEVMDataStreamServerFactory().CreateDataStreamServer(stream, 1, types.EntriesHashVersion).(*ZkEVMDataStreamServer)
+	writeTestBatch(t, srv, 1, 1)
+	writeTestBatch(t, srv, 2, 3)
+	// another server on the same stream, as after a restart, picks the hash up from the stream
+	other := NewZkEVMDataStreamServerFactory().CreateDataStreamServer(stream, 1, types.EntriesHashVersion).(*ZkEVMDataStreamServer)
+	writeTestBatch(t, other, 3, 5)
+	// and the first one notices it fell behind
+	writeTestBatch(t, srv, 4, 7)
+
+	entriesHash := &types.EntriesHash{}
+	for entryNum := uint64(0); entryNum < stream.GetHeader().TotalEntries; entryNum++ {
+		entry, err := stream.GetEntry(entryNum)
+		require.NoError(t, err)
+		fileEntry := &types.FileEntry{EntryType: types.EntryType(entry.Type), EntryNum: entry.Number, Data: entry.Data}
+		require.NoError(t, entriesHash.Verify(fileEntry))
+
+		if fileEntry.EntryType == types.EntryTypeBatchEnd {
+			batchEnd, err := types.UnmarshalBatchEnd(entry.Data)
+			require.NoError(t, err)
+			require.NotZero(t, batchEnd.EntriesHash)
+		}
+	}
+	require.NotZero(t, entriesHash.Hash())
+}
@@ -219,8 +219,14 @@ func getBatchTypeAndFork(batchNumber uint64, reader DbReader) (datastream.BatchT
 	if err != nil {
 		return datastream.BatchType_BATCH_TYPE_UNSPECIFIED, 0, err
 	}
+	_, forcedBatch, err := reader.GetBatchForcedBatch(batchNumber)
+	if err != nil {
+		return datastream.BatchType_BATCH_TYPE_UNSPECIFIED, 0, err
+	}
 	if invalidBatch {
 		batchType = datastream.BatchType_BATCH_TYPE_INVALID
+	} else if forcedBatch {
+		batchType = datastream.BatchType_BATCH_TYPE_FORCED
 	} else if batchNumber == 1 {
 		batchType = datastream.BatchType_BATCH_TYPE_INJECTED
 	} else {
@@ -55,7 +55,7 @@ func (srv *ZkEVMDataStreamServer) WriteWholeBatchToStream(
 		return err
 	}
 
-	if err = srv.streamServer.StartAtomicOp(); err != nil {
+	if err = srv.startAtomicOp(); err != nil {
 		return err
 	}
 	defer srv.streamServer.RollbackAtomicOp()
@@ -122,7 +122,7 @@ func (srv *ZkEVMDataStreamServer) WriteBlocksToStreamConsecutively(
 		return err
 	}
 
-	if err = srv.streamServer.StartAtomicOp(); err != nil {
+	if err = srv.startAtomicOp(); err != nil {
 		return err
 	}
 	defer srv.streamServer.RollbackAtomicOp()
@@ -201,10 +201,10 @@ LOOP:
 				return err
 			}
 			entries = make([]DataStreamEntryProto, 0, insertEntryCount)
-			if err = srv.streamServer.CommitAtomicOp(); err != nil {
+			if err = srv.commitAtomicOp(nil, nil, nil); err != nil {
 				return err
 			}
-			if err = srv.streamServer.StartAtomicOp(); err != nil {
+			if err = srv.startAtomicOp(); err != nil {
 				return err
 			}
 		}
@@ -241,7 +241,7 @@ func (srv *ZkEVMDataStreamServer) WriteBlockWithBatchStartToStream(
 		return err
 	}
 
-	if err = srv.streamServer.StartAtomicOp(); err != nil {
+	if err = srv.startAtomicOp(); err != nil {
 		return err
 	}
 	defer srv.streamServer.RollbackAtomicOp()
@@ -339,7 +339,7 @@ func (srv *ZkEVMDataStreamServer) WriteBatchEnd(
 		return err
 	}
 
-	if err = srv.streamServer.StartAtomicOp(); err != nil {
+	if err = srv.startAtomicOp(); err != nil {
 		return err
 	}
 	defer srv.streamServer.RollbackAtomicOp()
@@ -376,7 +376,7 @@ func (srv *ZkEVMDataStreamServer) WriteGenesisToStream(
 		return err
 	}
 
-	err = srv.streamServer.StartAtomicOp()
+	err = srv.startAtomicOp()
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"fmt"
+	"math/big"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	eritypes "github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/zk/datastream/types"
+	txtype "github.com/ledgerwatch/erigon/zk/tx"
+)
+
+// filteredIterator goes over the bookmarks and the entries matching the filter, the transactions are decoded for their
+// sender and recipient only when the filter has addresses
+type filteredIterator struct {
+	*dataStreamServerIterator
+	filter     *types.StreamFilter
+	senders    map[libcommon.Address]struct{}
+	recipients map[libcommon.Address]struct{}
+
+	// taken from the batch starts, for decoding the transactions
+	forkId uint64
+	signer *eritypes.Signer
+}
+
+// newFilteredIterator starts at the entry, going back to the batch start before it for the fork and chain
+func newFilteredIterator(stream StreamServer, start uint64, filter *types.StreamFilter) (*filteredIterator, error) {
+	it := &filteredIterator{
+		dataStreamServerIterator: newDataStreamServerIterator(stream, start),
+		filter:                   filter,
+		senders:                  make(map[libcommon.Address]struct{}, len(filter.Senders)),
+		recipients:               make(map[libcommon.Address]struct{}, len(filter.Recipients)),
+	}
+	for _, address := range filter.Senders {
+		it.senders[address] = struct{}{}
+	}
+	for _, address := range filter.Recipients {
+		it.recipients[address] = struct{}{}
+	}
+
+	if filter.FiltersAddresses() {
+		for entryNum := int64(start); entryNum >= 0; entryNum-- {
+			entry, err := stream.GetEntry(uint64(entryNum))
+			if err != nil {
+				return nil, err
+			}
+			if types.EntryType(entry.Type) == types.EntryTypeBatchStart {
+				if err := it.setBatchStart(entry.Data); err != nil {
+					return nil, err
+				}
+				break
+			}
+		}
+	}
+
+	return it, nil
+}
+
+// refresh takes in the entries written since the iterator was created or last refreshed
+func (it *filteredIterator) refresh() {
+	it.dataStreamServerIterator = newDataStreamServerIterator(it.stream, it.curEntryNum)
+}
+
+func (it *filteredIterator) NextFileEntry() (*types.FileEntry, error) {
+	for {
+		file, err := it.dataStreamServerIterator.NextFileEntry()
+		if err != nil || file == nil {
+			return file, err
+		}
+
+		switch file.EntryType {
+		case types.BookmarkEntryType:
+			return file, nil
+		case types.EntryTypeBatchStart:
+			if err := it.setBatchStart(file.Data); err != nil {
+				return nil, err
+			}
+		case types.EntryTypeL2Tx:
+			if !it.filter.HasEntryType(file.EntryType) {
+				continue
+			}
+			match, err := it.matchesTx(file.Data)
+			if err != nil {
+				return nil, fmt.Errorf("entry %d: %w", file.EntryNum, err)
+			}
+			if match {
+				return file, nil
+			}
+			continue
+		}
+
+		if it.filter.HasEntryType(file.EntryType) {
+			return file, nil
+		}
+	}
+}
+
+func (it *filteredIterator) setBatchStart(data []byte) error {
+	batchStart, err := types.UnmarshalBatchStart(data)
+	if err != nil {
+		return err
+	}
+	it.forkId = batchStart.ForkId
+	it.signer = eritypes.LatestSignerForChainID(new(big.Int).SetUint64(batchStart.ChainId))
+	return nil
+}
+
+func (it *filteredIterator) matchesTx(data []byte) (bool, error) {
+	if !it.filter.FiltersAddresses() {
+		return true, nil
+	}
+
+	l2Tx, err := types.UnmarshalTx(data)
+	if err != nil {
+		return false, err
+	}
+	tx, _, err := txtype.DecodeTx(l2Tx.Encoded, l2Tx.EffectiveGasPricePercentage, it.forkId)
+	if err != nil {
+		return false, fmt.Errorf("DecodeTx: %w", err)
+	}
+
+	if to := tx.GetTo(); to != nil {
+		if _, ok := it.recipients[*to]; ok {
+			return true, nil
+		}
+	}
+	if len(it.senders) == 0 {
+		return false, nil
+	}
+	if it.signer == nil {
+		return false, fmt.Errorf("no batch start before the transaction to recover its sender with")
+	}
+	sender, err := tx.Sender(*it.signer)
+	if err != nil {
+		return false, fmt.Errorf("recovering the sender: %w", err)
+	}
+	_, ok := it.senders[sender]
+	return ok, nil
+}
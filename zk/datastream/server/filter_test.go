@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-data-streamer/datastreamer"
+	dslog "github.com/0xPolygonHermez/zkevm-data-streamer/log"
+	"github.com/holiman/uint256"
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	eritypes "github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/zk/datastream/client"
+	"github.com/ledgerwatch/erigon/zk/datastream/proto/github.com/0xPolygonHermez/zkevm-node/state/datastream"
+	"github.com/ledgerwatch/erigon/zk/datastream/types"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestBatchWithTxs writes a batch of a single block holding the transactions
+func writeTestBatchWithTxs(t *testing.T, srv *ZkEVMDataStreamServer, batch, block uint64, txs ...eritypes.Transaction) {
+	t.Helper()
+
+	entries := []DataStreamEntryProto{
+		newBatchBookmarkEntryProto(batch),
+		newBatchStartProto(batch, 1, 9, datastream.BatchType_BATCH_TYPE_REGULAR),
+		newL2BlockBookmarkEntryProto(block),
+		&types.L2BlockProto{L2Block: &datastream.L2Block{Number: block, BatchNumber: batch}},
+	}
+	for _, tx := range txs {
+		txProto, err := newTransactionProto(255, libcommon.Hash{}, tx, block)
+		require.NoError(t, err)
+		entries = append(entries, txProto)
+	}
+	entries = append(entries, newL2BlockEndProto(block), newBatchEndProto(libcommon.Hash{}, libcommon.Hash{}, batch))
+
+	require.NoError(t, srv.startAtomicOp())
+	require.NoError(t, srv.commitEntriesToStreamProto(entries))
+	require.NoError(t, srv.commitAtomicOp(nil, &batch, &batch))
+}
+
+func TestFilteredStream(t *testing.T) {
+	signer := eritypes.LatestSignerForChainID(big.NewInt(1))
+	signedTx := func(nonce uint64, to libcommon.Address) (eritypes.Transaction, libcommon.Address) {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		tx, err := eritypes.SignTx(eritypes.NewTransaction(nonce, to, uint256.NewInt(1), 21000, uint256.NewInt(1), nil), *signer, key)
+		require.NoError(t, err)
+		return tx, crypto.PubkeyToAddress(key.PublicKey)
+	}
+	watched := libcommon.HexToAddress("0x1234")
+	toWatched, _ := signedTx(0, watched)
+	fromWatched, watchedSender := signedTx(0, libcommon.HexToAddress("0x5678"))
+	other, _ := signedTx(1, libcommon.HexToAddress("0x5678"))
+
+	internalPort := freePort(t)
+	stream, err := NewZkEVMDataStreamServerFactory().CreateStreamServer(internalPort, types.EntriesHashVersion, 1, datastreamer.StreamType(1), filepath.Join(t.TempDir(), "data-stream"), time.Second, time.Minute, time.Minute, &dslog.Config{Level: "warn"})
+	require.NoError(t, err)
+	require.NoError(t, stream.Start())
+	srv := NewZkEVMDataStreamServerFactory().CreateDataStreamServer(stream, 1, types.EntriesHashVersion).(*ZkEVMDataStreamServer)
+	writeTestBatch(t, srv, 1, 1)
+	writeTestBatchWithTxs(t, srv, 2, 3, other, toWatched, fromWatched)
+
+	proxy := NewAuthProxy("127.0.0.1:0", net.JoinHostPort("127.0.0.1", strconv.Itoa(int(internalPort))), AuthConfig{})
+	proxy.ServeFiltered(stream)
+	require.NoError(t, proxy.Start())
+	defer proxy.Close()
+
+	c := client.NewClient(context.Background(), proxy.Addr().String(), 0, 2*time.Second, 0)
+	require.NoError(t, c.Start())
+	defer c.Stop()
+
+	moreToWatched, _ := signedTx(1, watched)
+	txNames := map[string]string{}
+	for name, tx := range map[string]eritypes.Transaction{"to watched": toWatched, "from watched": fromWatched, "more to watched": moreToWatched, "other": other} {
+		txProto, err := newTransactionProto(255, libcommon.Hash{}, tx, 0)
+		require.NoError(t, err)
+		txNames[string(txProto.Encoded)] = name
+	}
+
+	// collects the entries as "bookmark <value>", "tx <name>" or "entry <type>" until the function returns an error
+	var seen []string
+	collect := func(function func(last string) error) func(file *types.FileEntry) error {
+		return func(file *types.FileEntry) error {
+			var s string
+			switch file.EntryType {
+			case types.BookmarkEntryType:
+				bookmark, err := types.UnmarshalBookmark(file.Data)
+				require.NoError(t, err)
+				s = fmt.Sprintf("bookmark %d", bookmark.Value)
+			case types.EntryTypeL2Tx:
+				l2Tx, err := types.UnmarshalTx(file.Data)
+				require.NoError(t, err)
+				s = "tx " + txNames[string(l2Tx.Encoded)]
+			default:
+				s = fmt.Sprintf("entry %d", file.EntryType)
+			}
+			seen = append(seen, s)
+			return function(s)
+		}
+	}
+	errDone := errors.New("done")
+
+	// the batch boundaries and the transactions from the sender, along with all the bookmarks
+	filter := &types.StreamFilter{
+		EntryTypes: []types.EntryType{types.EntryTypeBatchStart, types.EntryTypeL2Tx, types.EntryTypeBatchEnd},
+		Senders:    []libcommon.Address{watchedSender},
+	}
+	err = c.ExecutePerFilteredFile(types.NewBookmarkProto(1, datastream.BookmarkType_BOOKMARK_TYPE_BATCH), filter, collect(func(last string) error {
+		if last == "tx from watched" {
+			return errDone
+		}
+		return nil
+	}))
+	require.ErrorIs(t, err, errDone)
+	batchStart, batchEnd := fmt.Sprintf("entry %d", types.EntryTypeBatchStart), fmt.Sprintf("entry %d", types.EntryTypeBatchEnd)
+	require.Equal(t, []string{
+		"bookmark 1", batchStart, "bookmark 1", "bookmark 2", batchEnd,
+		"bookmark 2", batchStart, "bookmark 3", "tx from watched",
+	}, seen)
+
+	// the transactions to the recipient, on the same connection and following the stream as it is written
+	seen = nil
+	filter = &types.StreamFilter{
+		EntryTypes: []types.EntryType{types.EntryTypeL2Tx},
+		Recipients: []libcommon.Address{watched},
+	}
+	err = c.ExecutePerFilteredFile(types.NewBookmarkProto(3, datastream.BookmarkType_BOOKMARK_TYPE_L2_BLOCK), filter, collect(func(last string) error {
+		switch last {
+		case "tx to watched":
+			writeTestBatchWithTxs(t, srv, 3, 4, other, moreToWatched)
+		case "tx more to watched":
+			return errDone
+		}
+		return nil
+	}))
+	require.ErrorIs(t, err, errDone)
+	require.Equal(t, []string{"bookmark 3", "tx to watched", "bookmark 3", "bookmark 4", "tx more to watched"}, seen)
+}
@@ -0,0 +1,606 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
+	"github.com/ledgerwatch/erigon/zk/datastream/client"
+	"github.com/ledgerwatch/erigon/zk/datastream/proto/github.com/0xPolygonHermez/zkevm-node/state/datastream"
+	"github.com/ledgerwatch/erigon/zk/datastream/types"
+	"github.com/ledgerwatch/log/v3"
+)
+
+/*
+the JSON bridge serves the stream to clients that don't speak the binary protocol.  GET /datastream with one of
+batch=<number>, block=<number> or resume=<token> streams the batch starts, l2 blocks, GER updates and batch ends from
+there on as JSON messages, over a WebSocket when asked for an upgrade and as newline delimited JSON otherwise, following
+the stream as it is written.  Every message carries a resume token: the entry number to go on from and the block
+expected next, so a token left behind by an unwind or a prune is refused rather than skipping or repeating blocks.
+*/
+
+const (
+	BridgeMessageBatchStart = "batchStart"
+	BridgeMessageL2Block    = "l2Block"
+	BridgeMessageGerUpdate  = "gerUpdate"
+	BridgeMessageBatchEnd   = "batchEnd"
+	BridgeMessageError      = "error"
+
+	bridgePath          = "/datastream"
+	bridgePingInterval  = 30 * time.Second
+	bridgeHeaderTimeout = 10 * time.Second
+	bridgeReadMessages  = 1000
+
+	// how often the bridge and the filtered streams look for new entries once they caught up with the stream
+	streamPollInterval = 250 * time.Millisecond
+)
+
+var (
+	errBridgeCaughtUp   = errors.New("caught up with the stream")
+	errBridgeClientGone = errors.New("the client went away or the bridge closed")
+)
+
+// BridgeMessage is the JSON form of an entry, Data holds one of the Bridge* types below
+type BridgeMessage struct {
+	Type   string         `json:"type"`
+	Entry  hexutil.Uint64 `json:"entry"`
+	Resume string         `json:"resume,omitempty"`
+	Data   interface{}    `json:"data,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+type BridgeBatchStart struct {
+	Number  hexutil.Uint64 `json:"number"`
+	Type    hexutil.Uint64 `json:"type"`
+	ForkId  hexutil.Uint64 `json:"forkId"`
+	ChainId hexutil.Uint64 `json:"chainId"`
+}
+
+type BridgeL2Block struct {
+	Number          hexutil.Uint64      `json:"number"`
+	BatchNumber     hexutil.Uint64      `json:"batchNumber"`
+	Timestamp       hexutil.Uint64      `json:"timestamp"`
+	DeltaTimestamp  hexutil.Uint64      `json:"deltaTimestamp"`
+	L1InfoTreeIndex hexutil.Uint64      `json:"l1InfoTreeIndex"`
+	GlobalExitRoot  libcommon.Hash      `json:"globalExitRoot"`
+	Coinbase        libcommon.Address   `json:"coinbase"`
+	L1BlockHash     libcommon.Hash      `json:"l1BlockHash"`
+	Hash            libcommon.Hash      `json:"hash"`
+	StateRoot       libcommon.Hash      `json:"stateRoot"`
+	BlockGasLimit   hexutil.Uint64      `json:"blockGasLimit"`
+	BlockInfoRoot   libcommon.Hash      `json:"blockInfoRoot"`
+	Transactions    []BridgeTransaction `json:"transactions"`
+}
+
+type BridgeTransaction struct {
+	Index                       hexutil.Uint64 `json:"index"`
+	IsValid                     bool           `json:"isValid"`
+	Encoded                     hexutil.Bytes  `json:"encoded"`
+	EffectiveGasPricePercentage hexutil.Uint64 `json:"effectiveGasPricePercentage"`
+	IntermediateStateRoot       libcommon.Hash `json:"intermediateStateRoot"`
+}
+
+type BridgeGerUpdate struct {
+	BatchNumber    hexutil.Uint64    `json:"batchNumber"`
+	Timestamp      hexutil.Uint64    `json:"timestamp"`
+	GlobalExitRoot libcommon.Hash    `json:"globalExitRoot"`
+	Coinbase       libcommon.Address `json:"coinbase"`
+	ForkId         hexutil.Uint64    `json:"forkId"`
+	ChainId        hexutil.Uint64    `json:"chainId"`
+	StateRoot      libcommon.Hash    `json:"stateRoot"`
+}
+
+type BridgeBatchEnd struct {
+	Number        hexutil.Uint64 `json:"number"`
+	LocalExitRoot libcommon.Hash `json:"localExitRoot"`
+	StateRoot     libcommon.Hash `json:"stateRoot"`
+	EntriesHash   libcommon.Hash `json:"entriesHash"`
+}
+
+// bridgeMessage converts a parsed entry, nil for the entries the bridge doesn't send
+func bridgeMessage(parsed interface{}) *BridgeMessage {
+	switch parsed := parsed.(type) {
+	case *types.BatchStart:
+		return &BridgeMessage{Type: BridgeMessageBatchStart, Data: &BridgeBatchStart{
+			Number:  hexutil.Uint64(parsed.Number),
+			Type:    hexutil.Uint64(parsed.BatchType),
+			ForkId:  hexutil.Uint64(parsed.ForkId),
+			ChainId: hexutil.Uint64(parsed.ChainId),
+		}}
+	case *types.FullL2Block:
+		txs := make([]BridgeTransaction, 0, len(parsed.L2Txs))
+		for _, tx := range parsed.L2Txs {
+			txs = append(txs, BridgeTransaction{
+				Index:                       hexutil.Uint64(tx.Index),
+				IsValid:                     tx.IsValid,
+				Encoded:                     tx.Encoded,
+				EffectiveGasPricePercentage: hexutil.Uint64(tx.EffectiveGasPricePercentage),
+				IntermediateStateRoot:       tx.IntermediateStateRoot,
+			})
+		}
+		return &BridgeMessage{Type: BridgeMessageL2Block, Data: &BridgeL2Block{
+			Number:          hexutil.Uint64(parsed.L2BlockNumber),
+			BatchNumber:     hexutil.Uint64(parsed.BatchNumber),
+			Timestamp:       hexutil.Uint64(parsed.Timestamp),
+			DeltaTimestamp:  hexutil.Uint64(parsed.DeltaTimestamp),
+			L1InfoTreeIndex: hexutil.Uint64(parsed.L1InfoTreeIndex),
+			GlobalExitRoot:  parsed.GlobalExitRoot,
+			Coinbase:        parsed.Coinbase,
+			L1BlockHash:     parsed.L1BlockHash,
+			Hash:            parsed.L2Blockhash,
+			StateRoot:       parsed.StateRoot,
+			BlockGasLimit:   hexutil.Uint64(parsed.BlockGasLimit),
+			BlockInfoRoot:   parsed.BlockInfoRoot,
+			Transactions:    txs,
+		}}
+	case *types.GerUpdate:
+		return &BridgeMessage{Type: BridgeMessageGerUpdate, Data: &BridgeGerUpdate{
+			BatchNumber:    hexutil.Uint64(parsed.BatchNumber),
+			Timestamp:      hexutil.Uint64(parsed.Timestamp),
+			GlobalExitRoot: parsed.GlobalExitRoot,
+			Coinbase:       parsed.Coinbase,
+			ForkId:         hexutil.Uint64(parsed.ForkId),
+			ChainId:        hexutil.Uint64(parsed.ChainId),
+			StateRoot:      parsed.StateRoot,
+		}}
+	case *types.BatchEnd:
+		return &BridgeMessage{Type: BridgeMessageBatchEnd, Data: &BridgeBatchEnd{
+			Number:        hexutil.Uint64(parsed.Number),
+			LocalExitRoot: parsed.LocalExitRoot,
+			StateRoot:     parsed.StateRoot,
+			EntriesHash:   parsed.EntriesHash,
+		}}
+	}
+	return nil
+}
+
+// bridgePosition is where a client is in the stream, the entry to read next and the block expected next, 0 when it
+// hasn't seen a block yet
+type bridgePosition struct {
+	entry     uint64
+	nextBlock uint64
+}
+
+func (p bridgePosition) token() string {
+	return fmt.Sprintf("%d.%d", p.entry, p.nextBlock)
+}
+
+func parseResumeToken(token string) (bridgePosition, error) {
+	entry, nextBlock, ok := strings.Cut(token, ".")
+	if !ok {
+		return bridgePosition{}, fmt.Errorf("invalid resume token %q", token)
+	}
+	var p bridgePosition
+	var err error
+	if p.entry, err = strconv.ParseUint(entry, 10, 64); err != nil {
+		return bridgePosition{}, fmt.Errorf("invalid resume token %q", token)
+	}
+	if p.nextBlock, err = strconv.ParseUint(nextBlock, 10, 64); err != nil {
+		return bridgePosition{}, fmt.Errorf("invalid resume token %q", token)
+	}
+	return p, nil
+}
+
+// blockMismatchError is a block other than the one expected next, the stream was unwound or pruned since the position
+// was taken
+type blockMismatchError struct {
+	entry, expected, found uint64
+}
+
+func (e *blockMismatchError) Error() string {
+	return fmt.Sprintf("expected block %d at entry %d, found %d: the stream was unwound or pruned, resume from a block", e.expected, e.entry, e.found)
+}
+
+// readMessages reads the messages from the position on, moving it past them, until it caught up with the stream or
+// read max of them.  It stops before a block other than the one expected next with a blockMismatchError
+func readMessages(stream StreamServer, position *bridgePosition, max int) ([]*BridgeMessage, error) {
+	var msgs []*BridgeMessage
+	iterator := &bridgeIterator{newDataStreamServerIterator(stream, position.entry)}
+	for len(msgs) < max {
+		parsed, entryNum, err := client.ReadParsedProto(iterator)
+		if errors.Is(err, errBridgeCaughtUp) {
+			break
+		}
+		if err != nil {
+			return msgs, fmt.Errorf("entry %d: %w", position.entry, err)
+		}
+
+		entry := position.entry
+		if l2Block, ok := parsed.(*types.FullL2Block); ok {
+			if position.nextBlock != 0 && l2Block.L2BlockNumber != position.nextBlock {
+				return msgs, &blockMismatchError{entry: entry, expected: position.nextBlock, found: l2Block.L2BlockNumber}
+			}
+			position.nextBlock = l2Block.L2BlockNumber + 1
+		}
+		position.entry = entryNum + 1
+
+		msg := bridgeMessage(parsed)
+		if msg == nil {
+			continue
+		}
+		msg.Entry = hexutil.Uint64(entry)
+		msg.Resume = position.token()
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+// bridgeIterator reports running out of entries as an error, a block cut short by it is read again once written
+type bridgeIterator struct {
+	*dataStreamServerIterator
+}
+
+func (it *bridgeIterator) NextFileEntry() (*types.FileEntry, error) {
+	file, err := it.dataStreamServerIterator.NextFileEntry()
+	if err == nil && file == nil {
+		return nil, errBridgeCaughtUp
+	}
+	return file, err
+}
+
+type DataStreamBridge struct {
+	addr         string
+	stream       StreamServer
+	auth         AuthConfig
+	writeTimeout time.Duration
+
+	server   *http.Server
+	listener net.Listener
+	wg       sync.WaitGroup
+	upgrader websocket.Upgrader
+	// closed on Close, the WebSockets are hijacked from the http server and not closed along with it
+	quit chan struct{}
+	// the clients are not tracked if nil
+	clients *ClientTracker
+}
+
+// NewDataStreamBridge creates a bridge listening on addr, secured with the TLS and token of the stream.  The token is
+// sent as "Authorization: Bearer <token>"
+func NewDataStreamBridge(addr string, stream StreamServer, auth AuthConfig, writeTimeout time.Duration) *DataStreamBridge {
+	return &DataStreamBridge{
+		addr:         addr,
+		stream:       stream,
+		auth:         auth,
+		writeTimeout: writeTimeout,
+		quit:         make(chan struct{}),
+		upgrader: websocket.Upgrader{
+			// the clients are indexers rather than browsers
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+func (b *DataStreamBridge) Start() error {
+	listener, err := net.Listen("tcp", b.addr)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", b.addr, err)
+	}
+
+	b.server = &http.Server{
+		Handler:           b,
+		ReadHeaderTimeout: bridgeHeaderTimeout,
+	}
+	if b.auth.CertFile != "" {
+		tlsConfig, err := b.auth.serverTLSConfig()
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		b.server.TLSConfig = tlsConfig
+	}
+	b.listener = listener
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		var err error
+		if b.auth.CertFile != "" {
+			err = b.server.ServeTLS(listener, "", "")
+		} else {
+			err = b.server.Serve(listener)
+		}
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Warn("[dataStream] JSON bridge stopped serving", "err", err)
+		}
+	}()
+
+	return nil
+}
+
+// TrackClients makes the bridge follow its clients through the stream
+func (b *DataStreamBridge) TrackClients(clients *ClientTracker) {
+	b.clients = clients
+}
+
+// Addr is the address the bridge listens on, only set once started
+func (b *DataStreamBridge) Addr() net.Addr {
+	return b.listener.Addr()
+}
+
+// Close stops the bridge along with the clients streaming from it
+func (b *DataStreamBridge) Close() error {
+	close(b.quit)
+	err := b.server.Close()
+	b.wg.Wait()
+	return err
+}
+
+func (b *DataStreamBridge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != bridgePath {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if b.auth.Token != "" {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(b.auth.Token)) != 1 {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	position, status, err := b.startPosition(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	var writer bridgeWriter
+	if websocket.IsWebSocketUpgrade(r) {
+		conn, err := b.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// the upgrader has answered the client already
+			return
+		}
+		defer conn.Close()
+		writer = newWsBridgeWriter(conn, b.writeTimeout)
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		ndjson := &ndjsonBridgeWriter{w: w, rc: http.NewResponseController(w), writeTimeout: b.writeTimeout, ctx: r.Context()}
+		// answers right away rather than with the first message, which may be a while on a caught up stream
+		if err := ndjson.rc.Flush(); err != nil {
+			return
+		}
+		writer = ndjson
+	}
+
+	tracked := b.clients.connect(ClientKindBridge, r.RemoteAddr)
+	defer tracked.disconnect()
+	tracked.startAt(position.entry, ClientKindBridge)
+
+	if err := b.serve(writer, &position, tracked); err != nil && !errors.Is(err, errBridgeClientGone) {
+		log.Debug("[dataStream] JSON bridge client stopped", "remote", r.RemoteAddr, "err", err)
+		writer.write(&BridgeMessage{Type: BridgeMessageError, Entry: hexutil.Uint64(position.entry), Error: err.Error()})
+	}
+}
+
+// startPosition finds where the request asks to start, with the HTTP status to answer if it can't
+func (b *DataStreamBridge) startPosition(r *http.Request) (bridgePosition, int, error) {
+	query := r.URL.Query()
+	if token := query.Get("resume"); token != "" {
+		position, err := parseResumeToken(token)
+		if err != nil {
+			return bridgePosition{}, http.StatusBadRequest, err
+		}
+		if position.entry > b.stream.GetHeader().TotalEntries {
+			return bridgePosition{}, http.StatusGone, errors.New("the resume token is past the end of the stream, it was unwound")
+		}
+		return position, http.StatusOK, nil
+	}
+
+	var bookmarkType datastream.BookmarkType
+	var param string
+	switch {
+	case query.Has("batch"):
+		bookmarkType, param = datastream.BookmarkType_BOOKMARK_TYPE_BATCH, query.Get("batch")
+	case query.Has("block"):
+		bookmarkType, param = datastream.BookmarkType_BOOKMARK_TYPE_L2_BLOCK, query.Get("block")
+	default:
+		return bridgePosition{}, http.StatusBadRequest, errors.New("one of batch, block or resume is required")
+	}
+	number, err := strconv.ParseUint(param, 10, 64)
+	if err != nil {
+		return bridgePosition{}, http.StatusBadRequest, fmt.Errorf("invalid number %q", param)
+	}
+
+	bookmark, err := types.NewBookmarkProto(number, bookmarkType).Marshal()
+	if err != nil {
+		return bridgePosition{}, http.StatusInternalServerError, err
+	}
+	entry, err := b.stream.GetBookmark(bookmark)
+	if err != nil {
+		firstBatch, firstBlock, startErr := StreamStart(b.stream)
+		if startErr != nil {
+			return bridgePosition{}, http.StatusInternalServerError, startErr
+		}
+		if pruned, _ := prunedBookmark(bookmark, firstBatch, firstBlock); pruned {
+			return bridgePosition{}, http.StatusGone, fmt.Errorf("the stream is pruned before batch %d, block %d", firstBatch, firstBlock)
+		}
+		return bridgePosition{}, http.StatusNotFound, fmt.Errorf("%s %d is not in the stream", bookmarkType, number)
+	}
+
+	position := bridgePosition{entry: entry}
+	if bookmarkType == datastream.BookmarkType_BOOKMARK_TYPE_L2_BLOCK {
+		position.nextBlock = number
+	}
+	return position, http.StatusOK, nil
+}
+
+// serve sends the entries from the position on, waiting for more once it caught up with the stream
+func (b *DataStreamBridge) serve(writer bridgeWriter, position *bridgePosition, tracked *trackedClient) error {
+	ticker := time.NewTicker(streamPollInterval)
+	defer ticker.Stop()
+	lastWrite := time.Now()
+
+	for {
+		totalEntries := b.stream.GetHeader().TotalEntries
+		if position.entry > totalEntries {
+			return fmt.Errorf("the stream was unwound before entry %d, resume from a block", position.entry)
+		}
+
+		if position.entry < totalEntries {
+			msgs, err := readMessages(b.stream, position, bridgeReadMessages)
+			for _, msg := range msgs {
+				n, err := writer.write(msg)
+				tracked.wrote(n)
+				if err != nil {
+					return err
+				}
+				tracked.messageSent(msg)
+				lastWrite = time.Now()
+			}
+			if err != nil {
+				return err
+			}
+			tracked.skippedTo(position.entry)
+			if len(msgs) == bridgeReadMessages {
+				continue
+			}
+		}
+
+		select {
+		case <-writer.done():
+			return errBridgeClientGone
+		case <-b.quit:
+			return errBridgeClientGone
+		case <-ticker.C:
+		}
+		if time.Since(lastWrite) > bridgePingInterval {
+			if err := writer.keepAlive(); err != nil {
+				return err
+			}
+			lastWrite = time.Now()
+		}
+	}
+}
+
+type bridgeWriter interface {
+	// write sends the message, returning the bytes written
+	write(msg *BridgeMessage) (int, error)
+	// keepAlive lets an idle client know the bridge is still there
+	keepAlive() error
+	// done is closed once the client goes away
+	done() <-chan struct{}
+}
+
+type wsBridgeWriter struct {
+	conn         *websocket.Conn
+	writeTimeout time.Duration
+	closed       chan struct{}
+}
+
+func newWsBridgeWriter(conn *websocket.Conn, writeTimeout time.Duration) *wsBridgeWriter {
+	w := &wsBridgeWriter{conn: conn, writeTimeout: writeTimeout, closed: make(chan struct{})}
+	// the client isn't expected to send anything, reading only handles the control messages and notices it leaving
+	go func() {
+		defer close(w.closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+	return w
+}
+
+func (w *wsBridgeWriter) write(msg *BridgeMessage) (int, error) {
+	if err := w.conn.SetWriteDeadline(time.Now().Add(w.writeTimeout)); err != nil {
+		return 0, err
+	}
+	// as WriteJSON does, counting the bytes
+	mw, err := w.conn.NextWriter(websocket.TextMessage)
+	if err != nil {
+		return 0, err
+	}
+	counter := &countingWriter{w: mw}
+	err = json.NewEncoder(counter).Encode(msg)
+	if closeErr := mw.Close(); err == nil {
+		err = closeErr
+	}
+	return counter.n, err
+}
+
+func (w *wsBridgeWriter) keepAlive() error {
+	return w.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(w.writeTimeout))
+}
+
+func (w *wsBridgeWriter) done() <-chan struct{} {
+	return w.closed
+}
+
+type ndjsonBridgeWriter struct {
+	w            http.ResponseWriter
+	rc           *http.ResponseController
+	writeTimeout time.Duration
+	ctx          context.Context
+}
+
+func (w *ndjsonBridgeWriter) write(msg *BridgeMessage) (int, error) {
+	if err := w.rc.SetWriteDeadline(time.Now().Add(w.writeTimeout)); err != nil {
+		return 0, err
+	}
+	counter := &countingWriter{w: w.w}
+	if err := json.NewEncoder(counter).Encode(msg); err != nil {
+		return counter.n, err
+	}
+	return counter.n, w.rc.Flush()
+}
+
+// keepAlive does nothing, the server notices the client hanging up through the request context
+func (w *ndjsonBridgeWriter) keepAlive() error {
+	return nil
+}
+
+func (w *ndjsonBridgeWriter) done() <-chan struct{} {
+	return w.ctx.Done()
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += n
+	return n, err
+}
+
+// bridgeStreamServer starts the JSON bridge alongside the stream server
+type bridgeStreamServer struct {
+	StreamServer
+	bridge *DataStreamBridge
+}
+
+// NewBridgeStreamServer puts the JSON bridge listening on addr on top of the stream server, tracking its clients with
+// clients unless nil
+func NewBridgeStreamServer(stream StreamServer, addr string, auth AuthConfig, writeTimeout time.Duration, clients *ClientTracker) StreamServer {
+	bridge := NewDataStreamBridge(addr, stream, auth, writeTimeout)
+	bridge.TrackClients(clients)
+	return &bridgeStreamServer{
+		StreamServer: stream,
+		bridge:       bridge,
+	}
+}
+
+func (s *bridgeStreamServer) Start() error {
+	if err := s.StreamServer.Start(); err != nil {
+		return err
+	}
+	return s.bridge.Start()
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-data-streamer/datastreamer"
+	dslog "github.com/0xPolygonHermez/zkevm-data-streamer/log"
+	"github.com/gorilla/websocket"
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
+	"github.com/ledgerwatch/erigon/zk/datastream/types"
+	"github.com/stretchr/testify/require"
+)
+
+type testBridgeMessage struct {
+	Type   string          `json:"type"`
+	Entry  hexutil.Uint64  `json:"entry"`
+	Resume string          `json:"resume"`
+	Data   json.RawMessage `json:"data"`
+	Error  string          `json:"error"`
+}
+
+// summary is the type and number of the message, as in "l2Block 3"
+func (m *testBridgeMessage) summary(t *testing.T) string {
+	t.Helper()
+
+	var data struct {
+		Number hexutil.Uint64 `json:"number"`
+	}
+	require.NoError(t, json.Unmarshal(m.Data, &data))
+	return fmt.Sprintf("%s %d", m.Type, data.Number)
+}
+
+func TestDataStreamBridge(t *testing.T) {
+	stream, err := NewZkEVMDataStreamServerFactory().CreateStreamServer(0, types.EntriesHashVersion, 1, datastreamer.StreamType(1), filepath.Join(t.TempDir(), "data-stream"), time.Second, time.Minute, time.Minute, &dslog.Config{Level: "warn"})
+	require.NoError(t, err)
+	require.NoError(t, stream.Start())
+	srv := NewZkEVMDataStreamServerFactory().CreateDataStreamServer(stream, 1, types.EntriesHashVersion).(*ZkEVMDataStreamServer)
+	writeTestBatch(t, srv, 1, 1)
+	writeTestBatch(t, srv, 2, 3)
+
+	bridge := NewDataStreamBridge("127.0.0.1:0", stream, AuthConfig{Token: "secret"}, time.Second)
+	require.NoError(t, bridge.Start())
+	defer bridge.Close()
+	url := fmt.Sprintf("ws://%s/datastream", bridge.Addr())
+	header := http.Header{"Authorization": []string{"Bearer secret"}}
+
+	dial := func(query string) *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial(url+query, header)
+		require.NoError(t, err)
+		return conn
+	}
+	read := func(conn *websocket.Conn) *testBridgeMessage {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		msg := &testBridgeMessage{}
+		require.NoError(t, conn.ReadJSON(msg))
+		return msg
+	}
+
+	// from a batch, following the stream as it is written
+	conn := dial("?batch=2")
+	defer conn.Close()
+	var resume string
+	for _, expected := range []string{"batchStart 2", "l2Block 3", "l2Block 4", "batchEnd 2"} {
+		msg := read(conn)
+		require.Equal(t, expected, msg.summary(t))
+		if expected == "l2Block 3" {
+			resume = msg.Resume
+		}
+	}
+	writeTestBatch(t, srv, 3, 5)
+	for _, expected := range []string{"batchStart 3", "l2Block 5", "l2Block 6", "batchEnd 3"} {
+		require.Equal(t, expected, read(conn).summary(t))
+	}
+
+	// resuming goes on after the last message received
+	conn = dial("?resume=" + resume)
+	defer conn.Close()
+	require.Equal(t, "l2Block 4", read(conn).summary(t))
+
+	// from a block as newline delimited JSON
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s/datastream?block=2", bridge.Addr()), nil)
+	require.NoError(t, err)
+	req.Header = header
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	scanner := bufio.NewScanner(resp.Body)
+	for _, expected := range []string{"l2Block 2", "batchEnd 1", "batchStart 2", "l2Block 3"} {
+		require.True(t, scanner.Scan())
+		msg := &testBridgeMessage{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), msg))
+		require.Equal(t, expected, msg.summary(t))
+	}
+
+	// a resume token from before an unwind is refused once it doesn't line up with the blocks anymore
+	require.NoError(t, srv.UnwindToBatchStart(2))
+	writeTestBatch(t, srv, 2, 5)
+	stale := dial("?resume=" + resume)
+	defer stale.Close()
+	msg := read(stale)
+	require.Equal(t, BridgeMessageError, msg.Type)
+	require.Contains(t, msg.Error, "expected block 4")
+
+	for query, status := range map[string]int{
+		"?batch=9":      http.StatusNotFound,
+		"?block=x":      http.StatusBadRequest,
+		"":              http.StatusBadRequest,
+		"?resume=999.0": http.StatusGone,
+	} {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s/datastream%s", bridge.Addr(), query), nil)
+		require.NoError(t, err)
+		req.Header = header
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, status, resp.StatusCode, query)
+	}
+
+	// and nothing without the token
+	_, resp, err = websocket.DefaultDialer.Dial(url+"?batch=1", nil)
+	require.Error(t, err)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
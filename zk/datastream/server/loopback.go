@@ -0,0 +1,55 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"unsafe"
+
+	"github.com/0xPolygonHermez/zkevm-data-streamer/datastreamer"
+)
+
+/*
+the stream server of zkevm-data-streamer listens on all interfaces, with no way to pick the address.  Behind the auth
+proxy it serves the stream in plaintext and without a token on the internal port, so that listener is kept to the
+loopback interface: only the proxy, and whatever else runs on the host, reaches it.
+*/
+
+// keepToLoopback restricts the listener of the started stream server, under its wrappers, to the loopback interface
+func keepToLoopback(stream StreamServer) error {
+	for {
+		switch wrapped := stream.(type) {
+		case *sinkStreamServer:
+			stream = wrapped.StreamServer
+			continue
+		case *authStreamServer:
+			stream = wrapped.StreamServer
+			continue
+		}
+		break
+	}
+
+	streamServer, ok := stream.(*datastreamer.StreamServer)
+	if !ok {
+		return fmt.Errorf("unexpected stream server %T", stream)
+	}
+	listener, err := streamListener(streamServer)
+	if err != nil {
+		return err
+	}
+	return bindToLoopback(listener)
+}
+
+// streamListener returns the listener the stream server keeps to itself
+func streamListener(stream *datastreamer.StreamServer) (net.Listener, error) {
+	field := reflect.ValueOf(stream).Elem().FieldByName("ln")
+	if !field.IsValid() || field.Type() != reflect.TypeOf((*net.Listener)(nil)).Elem() {
+		return nil, errors.New("the stream server has no listener")
+	}
+	listener, _ := reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem().Interface().(net.Listener)
+	if listener == nil {
+		return nil, errors.New("the stream server is not listening")
+	}
+	return listener, nil
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// bindToLoopback makes the kernel only hand the listener the connections arriving on the loopback interface, the
+// others are refused as if nothing was listening
+func bindToLoopback(listener net.Listener) error {
+	tcpListener, ok := listener.(*net.TCPListener)
+	if !ok {
+		return fmt.Errorf("unexpected listener %T", listener)
+	}
+	rawConn, err := tcpListener.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var sockErr error
+	if err := rawConn.Control(func(fd uintptr) {
+		sockErr = syscall.SetsockoptString(int(fd), syscall.SOL_SOCKET, syscall.SO_BINDTODEVICE, "lo")
+	}); err != nil {
+		return err
+	}
+	if sockErr != nil {
+		return errors.Join(errors.New("binding the listener to the loopback interface"), sockErr)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-data-streamer/datastreamer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeepToLoopback(t *testing.T) {
+	// an address of the host other than loopback to reach the stream server at
+	var external net.IP
+	addrs, err := net.InterfaceAddrs()
+	require.NoError(t, err)
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() && ipNet.IP.To4() != nil {
+			external = ipNet.IP
+			break
+		}
+	}
+	if external == nil {
+		t.Skip("no address other than loopback")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := uint16(listener.Addr().(*net.TCPAddr).Port)
+	require.NoError(t, listener.Close())
+
+	stream, err := datastreamer.NewServer(port, 3, 1, datastreamer.StreamType(1), filepath.Join(t.TempDir(), "data-stream"), time.Second, time.Minute, time.Minute, nil)
+	require.NoError(t, err)
+	require.NoError(t, stream.Start())
+
+	// the stream server listens on all interfaces
+	conn, err := net.Dial("tcp", net.JoinHostPort(external.String(), fmt.Sprint(port)))
+	require.NoError(t, err)
+	conn.Close()
+
+	require.NoError(t, keepToLoopback(NewAuthStreamServer(stream, "127.0.0.1:0", port, AuthConfig{}, nil)))
+
+	// only the proxy on loopback reaches it then
+	_, err = net.Dial("tcp", net.JoinHostPort(external.String(), fmt.Sprint(port)))
+	require.Error(t, err)
+	conn, err = net.Dial("tcp", net.JoinHostPort("127.0.0.1", fmt.Sprint(port)))
+	require.NoError(t, err)
+	conn.Close()
+}
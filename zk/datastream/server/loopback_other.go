@@ -0,0 +1,12 @@
+//go:build !linux
+
+package server
+
+import (
+	"errors"
+	"net"
+)
+
+func bindToLoopback(net.Listener) error {
+	return errors.New("keeping the internal data stream port to the loopback interface is only supported on linux")
+}
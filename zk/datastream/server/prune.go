@@ -0,0 +1,245 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ledgerwatch/erigon/zk/datastream/proto/github.com/0xPolygonHermez/zkevm-node/state/datastream"
+	"github.com/ledgerwatch/erigon/zk/datastream/types"
+	"github.com/ledgerwatch/log/v3"
+)
+
+/*
+the stream file of zkevm-data-streamer can only be appended to or truncated at its end, and the stream server can't be
+closed once created.  Pruning the batches before some batch means copying the stream from that batch on into a new
+stream, bookmarks included, and swapping the files while the node is stopped.  The entries are renumbered from 0, which
+clients don't notice as they start from bookmarks.  A marker is written once the compacted stream is complete and on
+disk, from then on the swap is finished from whatever state it was left in, by the next prune or when the node starts.
+*/
+
+const compactCommitEntries = 10_000
+
+// RetentionPolicy decides the first batch kept when pruning the stream, the zero value keeps everything
+type RetentionPolicy struct {
+	// keep the last RetainBatches closed batches
+	RetainBatches uint64
+	// keep the batches after the last verified one
+	KeepUnverified bool
+}
+
+// PruneFromBatch is the first batch the policy keeps, 0 if nothing is to be pruned.  With both rules set the one
+// keeping more batches wins
+func (p RetentionPolicy) PruneFromBatch(highestClosedBatch, lastVerifiedBatch uint64) uint64 {
+	var fromBatch uint64
+	if p.RetainBatches > 0 && highestClosedBatch >= p.RetainBatches {
+		fromBatch = highestClosedBatch - p.RetainBatches + 1
+	}
+	if p.KeepUnverified && (p.RetainBatches == 0 || lastVerifiedBatch+1 < fromBatch) {
+		fromBatch = lastVerifiedBatch + 1
+	}
+	if fromBatch > highestClosedBatch {
+		// never prune the batches still being written
+		fromBatch = highestClosedBatch
+	}
+	return fromBatch
+}
+
+// StreamStart returns the first batch and block in the stream, both 0 for a stream starting at genesis or empty
+func StreamStart(stream StreamServer) (batch, block uint64, err error) {
+	totalEntries := stream.GetHeader().TotalEntries
+	foundBatch := false
+	for entryNum := uint64(0); entryNum < totalEntries; entryNum++ {
+		entry, err := stream.GetEntry(entryNum)
+		if err != nil {
+			return 0, 0, err
+		}
+		switch types.EntryType(entry.Type) {
+		case types.EntryTypeBatchStart:
+			if !foundBatch {
+				batchStart, err := types.UnmarshalBatchStart(entry.Data)
+				if err != nil {
+					return 0, 0, err
+				}
+				batch, foundBatch = batchStart.Number, true
+			}
+		case types.EntryTypeL2Block:
+			l2Block, err := types.UnmarshalL2Block(entry.Data)
+			if err != nil {
+				return 0, 0, err
+			}
+			return batch, l2Block.L2BlockNumber, nil
+		}
+	}
+
+	return batch, 0, nil
+}
+
+// CompactStream copies the stream from the bookmark of fromBatch on into compacted, which has to be started and empty
+func CompactStream(stream, compacted StreamServer, fromBatch uint64) (err error) {
+	if compacted.GetHeader().TotalEntries != 0 {
+		return fmt.Errorf("the compacted stream is not empty")
+	}
+
+	bookmark, err := types.NewBookmarkProto(fromBatch, datastream.BookmarkType_BOOKMARK_TYPE_BATCH).Marshal()
+	if err != nil {
+		return err
+	}
+	from, err := stream.GetBookmark(bookmark)
+	if err != nil {
+		return fmt.Errorf("batch %d not in the datastream: %w", fromBatch, err)
+	}
+
+	if err = compacted.StartAtomicOp(); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			compacted.RollbackAtomicOp()
+		}
+	}()
+
+	totalEntries := stream.GetHeader().TotalEntries
+	for entryNum := from; entryNum < totalEntries; entryNum++ {
+		entry, err := stream.GetEntry(entryNum)
+		if err != nil {
+			return fmt.Errorf("GetEntry %d: %w", entryNum, err)
+		}
+		if types.EntryType(entry.Type) == types.BookmarkEntryType {
+			_, err = compacted.AddStreamBookmark(entry.Data)
+		} else {
+			_, err = compacted.AddStreamEntry(entry.Type, entry.Data)
+		}
+		if err != nil {
+			return err
+		}
+
+		if (entryNum-from+1)%compactCommitEntries == 0 {
+			if err = compacted.CommitAtomicOp(); err != nil {
+				return err
+			}
+			if err = compacted.StartAtomicOp(); err != nil {
+				return err
+			}
+			log.Info("[dataStream] Compacting the stream", "entry", entryNum, "totalEntries", totalEntries)
+		}
+	}
+
+	return compacted.CommitAtomicOp()
+}
+
+// prunedBookmark tells if the bookmark points before the start of a pruned stream
+func prunedBookmark(data []byte, firstBatch, firstBlock uint64) (bool, error) {
+	bookmark, err := types.UnmarshalBookmark(data)
+	if err != nil {
+		return false, err
+	}
+	switch bookmark.BookmarkType() {
+	case datastream.BookmarkType_BOOKMARK_TYPE_BATCH:
+		return bookmark.Value < firstBatch, nil
+	case datastream.BookmarkType_BOOKMARK_TYPE_L2_BLOCK:
+		return bookmark.Value < firstBlock, nil
+	}
+	return false, nil
+}
+
+// StreamFiles are the stream file and bookmarks db the stream server opens for the name, it adds the extensions of
+// both at the first dot of the path
+func StreamFiles(name string) (streamFile, bookmarksDb string) {
+	streamFile = name
+	if !strings.Contains(streamFile, ".") {
+		streamFile += ".bin"
+	}
+	return streamFile, streamFile[:strings.Index(streamFile, ".")] + ".db"
+}
+
+// CompactedStreamName is the name the stream of the name is compacted to, next to it
+func CompactedStreamName(name string) string {
+	return name + "-compacted"
+}
+
+func swapMarker(name string) string {
+	return CompactedStreamName(name) + ".swap"
+}
+
+// SwapCompactedStream replaces the stream of the name with its compacted stream
+func SwapCompactedStream(name string) error {
+	compactedFile, compactedBookmarksDb := StreamFiles(CompactedStreamName(name))
+	for _, path := range []string{compactedFile, compactedBookmarksDb} {
+		if err := syncTree(path); err != nil {
+			return err
+		}
+	}
+
+	marker, err := os.Create(swapMarker(name))
+	if err != nil {
+		return err
+	}
+	if err := errors.Join(marker.Sync(), marker.Close()); err != nil {
+		return err
+	}
+	if err := syncFile(filepath.Dir(name)); err != nil {
+		return err
+	}
+
+	return CompleteStreamSwap(name)
+}
+
+// CompleteStreamSwap finishes the swap of the compacted stream if one was started, no node may be serving the stream of
+// the name.  Each step is skipped once done: the stream file is replaced first, then the bookmarks db
+func CompleteStreamSwap(name string) error {
+	marker := swapMarker(name)
+	if _, err := os.Stat(marker); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	streamFile, bookmarksDb := StreamFiles(name)
+	compactedFile, compactedBookmarksDb := StreamFiles(CompactedStreamName(name))
+	if _, err := os.Stat(compactedFile); err == nil {
+		if err := os.Rename(compactedFile, streamFile); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if _, err := os.Stat(compactedBookmarksDb); err == nil {
+		if err := os.RemoveAll(bookmarksDb); err != nil {
+			return err
+		}
+		if err := os.Rename(compactedBookmarksDb, bookmarksDb); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := syncFile(filepath.Dir(name)); err != nil {
+		return err
+	}
+
+	log.Info("[dataStream] Swapped in the compacted stream", "stream", streamFile)
+	return os.Remove(marker)
+}
+
+// syncTree flushes the file, or the directory and everything in it, to disk
+func syncTree(path string) error {
+	return filepath.WalkDir(path, func(path string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return syncFile(path)
+	})
+}
+
+func syncFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	return errors.Join(f.Sync(), f.Close())
+}
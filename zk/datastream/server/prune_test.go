@@ -0,0 +1,162 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-data-streamer/datastreamer"
+	dslog "github.com/0xPolygonHermez/zkevm-data-streamer/log"
+	"github.com/ledgerwatch/erigon/zk/datastream/client"
+	"github.com/ledgerwatch/erigon/zk/datastream/proto/github.com/0xPolygonHermez/zkevm-node/state/datastream"
+	"github.com/ledgerwatch/erigon/zk/datastream/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetentionPolicy(t *testing.T) {
+	scenarios := map[string]struct {
+		policy       RetentionPolicy
+		lastVerified uint64
+		expected     uint64
+	}{
+		"keep everything":                  {RetentionPolicy{}, 5, 0},
+		"last batches":                     {RetentionPolicy{RetainBatches: 3}, 5, 8},
+		"more batches than in the stream":  {RetentionPolicy{RetainBatches: 20}, 5, 0},
+		"unverified":                       {RetentionPolicy{KeepUnverified: true}, 5, 6},
+		"more unverified than last":        {RetentionPolicy{RetainBatches: 3, KeepUnverified: true}, 5, 6},
+		"less unverified than last":        {RetentionPolicy{RetainBatches: 3, KeepUnverified: true}, 9, 8},
+		"all verified keeps the last one":  {RetentionPolicy{KeepUnverified: true}, 10, 10},
+		"nothing verified keeps from zero": {RetentionPolicy{KeepUnverified: true}, 0, 1},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, scenario.expected, scenario.policy.PruneFromBatch(10, scenario.lastVerified))
+		})
+	}
+}
+
+// freePort finds a port for the stream server, which doesn't tell the one it got for 0
+func freePort(t *testing.T) uint16 {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return uint16(listener.Addr().(*net.TCPAddr).Port)
+}
+
+func TestCompactStream(t *testing.T) {
+	dir := t.TempDir()
+	newStream := func(name string, port uint16) StreamServer {
+		stream, err := NewZkEVMDataStreamServerFactory().CreateStreamServer(port, types.EntriesHashVersion, 1, datastreamer.StreamType(1), filepath.Join(dir, name), time.Second, time.Minute, time.Minute, &dslog.Config{Level: "warn"})
+		require.NoError(t, err)
+		require.NoError(t, stream.Start())
+		return stream
+	}
+
+	stream := newStream("data-stream", 0)
+	srv := NewZkEVMDataStreamServerFactory().CreateDataStreamServer(stream, 1, types.EntriesHashVersion).(*ZkEVMDataStreamServer)
+	for batch := uint64(1); batch <= 4; batch++ {
+		writeTestBatch(t, srv, batch, 2*batch-1)
+	}
+
+	internalPort := freePort(t)
+	compacted := newStream("data-stream-compacted", internalPort)
+	require.NoError(t, CompactStream(stream, compacted, 3))
+
+	// the first batch kept starts the stream, with the bookmarks from it on
+	firstBatch, firstBlock, err := StreamStart(compacted)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), firstBatch)
+	require.Equal(t, uint64(5), firstBlock)
+	for _, bookmark := range []*types.BookmarkProto{
+		types.NewBookmarkProto(3, datastream.BookmarkType_BOOKMARK_TYPE_BATCH),
+		types.NewBookmarkProto(8, datastream.BookmarkType_BOOKMARK_TYPE_L2_BLOCK),
+	} {
+		data, err := bookmark.Marshal()
+		require.NoError(t, err)
+		_, err = compacted.GetBookmark(data)
+		require.NoError(t, err)
+	}
+
+	// the entries hash carries over, the next batch written goes on from it
+	writeTestBatch(t, NewZkEVMDataStreamServerFactory().CreateDataStreamServer(compacted, 1, types.EntriesHashVersion).(*ZkEVMDataStreamServer), 5, 9)
+	entriesHash := &types.EntriesHash{}
+	for entryNum := uint64(0); entryNum < compacted.GetHeader().TotalEntries; entryNum++ {
+		entry, err := compacted.GetEntry(entryNum)
+		require.NoError(t, err)
+		require.NoError(t, entriesHash.Verify(&types.FileEntry{EntryType: types.EntryType(entry.Type), Data: entry.Data}))
+	}
+
+	// behind the proxy the clients asking for pruned blocks are told so
+	proxy := NewAuthProxy("127.0.0.1:0", net.JoinHostPort("127.0.0.1", strconv.Itoa(int(internalPort))), AuthConfig{})
+	proxy.SetPrunedBefore(firstBatch, firstBlock)
+	require.NoError(t, proxy.Start())
+	defer proxy.Close()
+
+	c := client.NewClient(context.Background(), proxy.Addr().String(), 0, 500*time.Millisecond, 0)
+	require.NoError(t, c.Start())
+	defer c.Stop()
+	_, err = c.GetL2BlockByNumber(2)
+	require.ErrorIs(t, err, types.ErrPruned)
+
+	c = client.NewClient(context.Background(), proxy.Addr().String(), 0, 500*time.Millisecond, 0)
+	require.NoError(t, c.Start())
+	defer c.Stop()
+	l2Block, err := c.GetL2BlockByNumber(6)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), l2Block.BatchNumber)
+}
+
+func TestCompleteStreamSwap(t *testing.T) {
+	write := func(path, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	read := func(path string) string {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	// the swap may be left after any of its steps
+	for stepsDone := 0; stepsDone <= 3; stepsDone++ {
+		t.Run(fmt.Sprintf("%d steps done", stepsDone), func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "data-stream")
+			streamFile, bookmarksDb := StreamFiles(name)
+			compactedFile, compactedBookmarksDb := StreamFiles(CompactedStreamName(name))
+			write(streamFile, "stream")
+			write(filepath.Join(bookmarksDb, "bookmarks"), "bookmarks")
+			write(compactedFile, "compacted stream")
+			write(filepath.Join(compactedBookmarksDb, "bookmarks"), "compacted bookmarks")
+
+			// nothing is swapped before the marker is written
+			require.NoError(t, CompleteStreamSwap(name))
+			require.Equal(t, "stream", read(streamFile))
+
+			write(swapMarker(name), "")
+			steps := []func() error{
+				func() error { return os.Rename(compactedFile, streamFile) },
+				func() error { return os.RemoveAll(bookmarksDb) },
+				func() error { return os.Rename(compactedBookmarksDb, bookmarksDb) },
+			}
+			for _, step := range steps[:stepsDone] {
+				require.NoError(t, step())
+			}
+
+			require.NoError(t, CompleteStreamSwap(name))
+			require.Equal(t, "compacted stream", read(streamFile))
+			require.Equal(t, "compacted bookmarks", read(filepath.Join(bookmarksDb, "bookmarks")))
+			for _, path := range []string{compactedFile, compactedBookmarksDb, swapMarker(name)} {
+				_, err := os.Stat(path)
+				require.ErrorIs(t, err, os.ErrNotExist)
+			}
+		})
+	}
+}
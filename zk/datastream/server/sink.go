@@ -0,0 +1,360 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
+	"github.com/ledgerwatch/erigon/zk/datastream/types"
+	"github.com/ledgerwatch/log/v3"
+)
+
+/*
+the sinks get the stream as it is committed, in the messages of the JSON bridge.  Each sink has a worker reading the
+stream from the cursor of the sink, writing the messages to it and only then moving the cursor on, which is kept in a
+file so after a failed write or a restart the messages from the cursor on are written again: delivery is at least once.
+An unwind of the stream is written as a reorg message: the blocks from its next block on are void and come again.  The
+cursors are moved back before the stream is truncated, so an unwind is never lost to a crash.  A stream changed while
+the node was stopped is only told apart by its entries and block numbers, the cursors have to be deleted after
+importing or compacting it.
+*/
+
+const (
+	BridgeMessageReorg = "reorg"
+
+	sinkWriteMessages    = 1000
+	sinkRetryMinInterval = time.Second
+	sinkRetryMaxInterval = time.Minute
+	// the workers also look for new entries on their own, in case the stream was written through another server
+	sinkPollInterval = 5 * time.Second
+)
+
+// DatastreamSink gets the messages of the stream at least once, in order.  A reorg message voids the blocks from its
+// next block on, the blocks sent after it replace them
+type DatastreamSink interface {
+	// Name identifies the sink, its cursor is kept under it
+	Name() string
+	// Write returns once the messages are delivered, a failed write is retried with the same messages and more
+	Write(msgs []*BridgeMessage) error
+	Close() error
+}
+
+type SinkReorg struct {
+	NextBlock hexutil.Uint64 `json:"nextBlock"`
+}
+
+// sinkCursor is the position of a sink in the stream, kept in its file
+type sinkCursor struct {
+	Entry     uint64 `json:"entry"`
+	NextBlock uint64 `json:"nextBlock"`
+	// a reorg message is due before the entries from Entry on
+	Reorg bool `json:"reorg"`
+}
+
+type sinkWorker struct {
+	sink   DatastreamSink
+	file   string
+	notify chan struct{}
+
+	mu     sync.Mutex
+	cursor sinkCursor
+	// bumped on every unwind, a write started before one doesn't move the cursor
+	generation uint64
+	delivering bool
+}
+
+// SinkDispatcher feeds the sinks from the stream, notified of its commits and unwinds by the stream server wrapper
+type SinkDispatcher struct {
+	workers []*sinkWorker
+	stream  StreamServer
+	// held for reading the stream, and for writing while truncating it
+	streamMu sync.RWMutex
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewSinkDispatcher creates a dispatcher keeping the cursors of the sinks in dir
+func NewSinkDispatcher(dir string, sinks ...DatastreamSink) (*SinkDispatcher, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	d := &SinkDispatcher{quit: make(chan struct{})}
+	for _, sink := range sinks {
+		w := &sinkWorker{
+			sink:   sink,
+			file:   filepath.Join(dir, sink.Name()+".json"),
+			notify: make(chan struct{}, 1),
+		}
+		data, err := os.ReadFile(w.file)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if err == nil {
+			if err := json.Unmarshal(data, &w.cursor); err != nil {
+				return nil, fmt.Errorf("cursor of sink %s: %w", sink.Name(), err)
+			}
+		}
+		d.workers = append(d.workers, w)
+	}
+	return d, nil
+}
+
+// start starts the workers on the stream set by the stream server wrapper
+func (d *SinkDispatcher) start() error {
+	for _, w := range d.workers {
+		d.wg.Add(1)
+		go func(w *sinkWorker) {
+			defer d.wg.Done()
+			d.run(w)
+		}(w)
+	}
+	return nil
+}
+
+// committed wakes the workers up, they read the new entries from the stream
+func (d *SinkDispatcher) committed() {
+	for _, w := range d.workers {
+		select {
+		case w.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// unwind moves the cursors back to the end of the stream truncated at entryNum, with a reorg due, and only then
+// truncates it.  A sink in the middle of a write may be getting entries from before the unwind, it is due a reorg as well
+func (d *SinkDispatcher) unwind(entryNum uint64, truncate func() error) error {
+	d.streamMu.Lock()
+	defer d.streamMu.Unlock()
+
+	nextBlock, err := nextBlockAt(d.stream, entryNum)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range d.workers {
+		w.mu.Lock()
+		w.generation++
+		if w.cursor.Entry > entryNum {
+			w.cursor = sinkCursor{Entry: entryNum, NextBlock: nextBlock, Reorg: true}
+		} else if w.delivering {
+			w.cursor.Reorg = true
+		}
+		err := w.saveCursor()
+		w.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := truncate(); err != nil {
+		return err
+	}
+	d.committed()
+	return nil
+}
+
+// Close stops the workers and closes the sinks
+func (d *SinkDispatcher) Close() error {
+	close(d.quit)
+	d.wg.Wait()
+
+	var errs []error
+	for _, w := range d.workers {
+		if err := w.sink.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d *SinkDispatcher) run(w *sinkWorker) {
+	ticker := time.NewTicker(sinkPollInterval)
+	defer ticker.Stop()
+	retryInterval := sinkRetryMinInterval
+
+	for {
+		more, err := d.deliver(w)
+		if err != nil {
+			log.Warn("[dataStream] Writing to the sink failed", "sink", w.sink.Name(), "retryIn", retryInterval, "err", err)
+			select {
+			case <-d.quit:
+				return
+			case <-time.After(retryInterval):
+			}
+			retryInterval = min(2*retryInterval, sinkRetryMaxInterval)
+			continue
+		}
+		retryInterval = sinkRetryMinInterval
+		if more {
+			continue
+		}
+
+		select {
+		case <-d.quit:
+			return
+		case <-w.notify:
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliver writes the messages from the cursor of the sink on, telling if there are more to write
+func (d *SinkDispatcher) deliver(w *sinkWorker) (more bool, err error) {
+	w.mu.Lock()
+	cursor, generation := w.cursor, w.generation
+	w.delivering = true
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		w.delivering = false
+		w.mu.Unlock()
+	}()
+
+	msgs, cursor, more, err := d.read(cursor)
+	if err != nil && len(msgs) == 0 {
+		return false, err
+	}
+	if len(msgs) == 0 {
+		return false, nil
+	}
+
+	if err := w.sink.Write(msgs); err != nil {
+		return false, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.generation != generation {
+		// unwound during the write, the cursor was moved already
+		return true, nil
+	}
+	w.cursor = cursor
+	if err := w.saveCursor(); err != nil {
+		return false, err
+	}
+	// an error after some messages is met again right away
+	return more || err != nil, nil
+}
+
+// read reads the messages from the cursor on, with the cursor past them
+func (d *SinkDispatcher) read(cursor sinkCursor) (msgs []*BridgeMessage, next sinkCursor, more bool, err error) {
+	d.streamMu.RLock()
+	defer d.streamMu.RUnlock()
+
+	// truncated while the node was stopped
+	totalEntries := d.stream.GetHeader().TotalEntries
+	if cursor.Entry > totalEntries {
+		nextBlock, err := nextBlockAt(d.stream, totalEntries)
+		if err != nil {
+			return nil, cursor, false, err
+		}
+		cursor = sinkCursor{Entry: totalEntries, NextBlock: nextBlock, Reorg: true}
+	}
+
+	if cursor.Reorg {
+		msgs = append(msgs, reorgMessage(cursor))
+		cursor.Reorg = false
+	}
+	if cursor.Entry == totalEntries {
+		return msgs, cursor, false, nil
+	}
+
+	position := bridgePosition{entry: cursor.Entry, nextBlock: cursor.NextBlock}
+	read, err := readMessages(d.stream, &position, sinkWriteMessages)
+	msgs = append(msgs, read...)
+	more = len(read) == sinkWriteMessages
+	cursor.Entry, cursor.NextBlock = position.entry, position.nextBlock
+
+	var mismatch *blockMismatchError
+	if errors.As(err, &mismatch) {
+		if mismatch.found > mismatch.expected {
+			return msgs, cursor, more, fmt.Errorf("%w, delete the cursor of the sink to start it over", err)
+		}
+		// unwound and written again while the node was stopped, the blocks from the one found on come again
+		return msgs, sinkCursor{Entry: mismatch.entry, NextBlock: mismatch.found, Reorg: true}, true, nil
+	}
+	return msgs, cursor, more, err
+}
+
+func reorgMessage(cursor sinkCursor) *BridgeMessage {
+	return &BridgeMessage{
+		Type:  BridgeMessageReorg,
+		Entry: hexutil.Uint64(cursor.Entry),
+		Data:  &SinkReorg{NextBlock: hexutil.Uint64(cursor.NextBlock)},
+	}
+}
+
+// saveCursor writes the cursor to its file, replacing the old one only once written
+func (w *sinkWorker) saveCursor() error {
+	data, err := json.Marshal(w.cursor)
+	if err != nil {
+		return err
+	}
+	tmp := w.file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, w.file)
+}
+
+// nextBlockAt is the block after the last one before entryNum, 0 if there is none
+func nextBlockAt(stream StreamServer, entryNum uint64) (uint64, error) {
+	for e := int64(entryNum) - 1; e >= 0; e-- {
+		entry, err := stream.GetEntry(uint64(e))
+		if err != nil {
+			return 0, err
+		}
+		if types.EntryType(entry.Type) == types.EntryTypeL2Block {
+			l2Block, err := types.UnmarshalL2Block(entry.Data)
+			if err != nil {
+				return 0, err
+			}
+			return l2Block.L2BlockNumber + 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// sinkStreamServer tells the sinks of the commits and unwinds of the stream server
+type sinkStreamServer struct {
+	StreamServer
+	sinks *SinkDispatcher
+}
+
+// NewSinkStreamServer feeds the sinks of the dispatcher from the stream server, starting them along with it
+func NewSinkStreamServer(stream StreamServer, sinks *SinkDispatcher) StreamServer {
+	sinks.stream = stream
+	return &sinkStreamServer{
+		StreamServer: stream,
+		sinks:        sinks,
+	}
+}
+
+func (s *sinkStreamServer) Start() error {
+	if err := s.StreamServer.Start(); err != nil {
+		return err
+	}
+	return s.sinks.start()
+}
+
+func (s *sinkStreamServer) CommitAtomicOp() error {
+	if err := s.StreamServer.CommitAtomicOp(); err != nil {
+		return err
+	}
+	s.sinks.committed()
+	return nil
+}
+
+func (s *sinkStreamServer) TruncateFile(entryNum uint64) error {
+	return s.sinks.unwind(entryNum, func() error {
+		return s.StreamServer.TruncateFile(entryNum)
+	})
+}
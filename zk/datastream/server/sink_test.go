@@ -0,0 +1,105 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0xPolygonHermez/zkevm-data-streamer/datastreamer"
+	dslog "github.com/0xPolygonHermez/zkevm-data-streamer/log"
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
+	"github.com/ledgerwatch/erigon/zk/datastream/types"
+	"github.com/stretchr/testify/require"
+)
+
+// testSink keeps the summaries of the messages written, failing the first writes
+type testSink struct {
+	mu        sync.Mutex
+	summaries []string
+	failures  int
+	writes    int
+}
+
+func (s *testSink) Name() string {
+	return "test"
+}
+
+func (s *testSink) Write(msgs []*BridgeMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writes++
+	if s.writes <= s.failures {
+		return errors.New("sink down")
+	}
+	for _, msg := range msgs {
+		if reorg, ok := msg.Data.(*SinkReorg); ok {
+			s.summaries = append(s.summaries, fmt.Sprintf("reorg %d", reorg.NextBlock))
+			continue
+		}
+		data, err := json.Marshal(msg.Data)
+		if err != nil {
+			return err
+		}
+		var number struct {
+			Number hexutil.Uint64 `json:"number"`
+		}
+		if err := json.Unmarshal(data, &number); err != nil {
+			return err
+		}
+		s.summaries = append(s.summaries, fmt.Sprintf("%s %d", msg.Type, number.Number))
+	}
+	return nil
+}
+
+func (s *testSink) Close() error {
+	return nil
+}
+
+func (s *testSink) requireSummaries(t *testing.T, expected ...string) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return fmt.Sprint(s.summaries) == fmt.Sprint(expected)
+	}, 5*time.Second, 10*time.Millisecond, "expected %v", expected)
+}
+
+func TestDatastreamSinks(t *testing.T) {
+	dir := t.TempDir()
+	stream, err := NewZkEVMDataStreamServerFactory().CreateStreamServer(0, types.EntriesHashVersion, 1, datastreamer.StreamType(1), filepath.Join(dir, "data-stream"), time.Second, time.Minute, time.Minute, &dslog.Config{Level: "warn"})
+	require.NoError(t, err)
+
+	// the first write fails and is retried
+	sink := &testSink{failures: 1}
+	sinks, err := NewSinkDispatcher(filepath.Join(dir, "sinks"), sink)
+	require.NoError(t, err)
+	wrapped := NewSinkStreamServer(stream, sinks)
+	require.NoError(t, wrapped.Start())
+	srv := NewZkEVMDataStreamServerFactory().CreateDataStreamServer(wrapped, 1, types.EntriesHashVersion).(*ZkEVMDataStreamServer)
+	writeTestBatch(t, srv, 1, 1)
+	writeTestBatch(t, srv, 2, 3)
+	sink.requireSummaries(t, "batchStart 1", "l2Block 1", "l2Block 2", "batchEnd 1", "batchStart 2", "l2Block 3", "l2Block 4", "batchEnd 2")
+
+	// an unwind voids the blocks from the first one unwound on
+	require.NoError(t, srv.UnwindToBatchStart(2))
+	writeTestBatch(t, srv, 2, 3)
+	sink.requireSummaries(t, "batchStart 1", "l2Block 1", "l2Block 2", "batchEnd 1", "batchStart 2", "l2Block 3", "l2Block 4", "batchEnd 2",
+		"reorg 3", "batchStart 2", "l2Block 3", "l2Block 4", "batchEnd 2")
+	require.NoError(t, sinks.Close())
+
+	// unwound while the sinks were stopped, they go on from their cursors with a reorg
+	require.NoError(t, srv.UnwindToBatchStart(2))
+	writeTestBatch(t, srv, 2, 3)
+	writeTestBatch(t, srv, 3, 5)
+	sink = &testSink{}
+	sinks, err = NewSinkDispatcher(filepath.Join(dir, "sinks"), sink)
+	require.NoError(t, err)
+	NewSinkStreamServer(stream, sinks)
+	require.NoError(t, sinks.start())
+	defer sinks.Close()
+	sink.requireSummaries(t, "reorg 3", "batchStart 2", "l2Block 3", "l2Block 4", "batchEnd 2", "batchStart 3", "l2Block 5", "l2Block 6", "batchEnd 3")
+}
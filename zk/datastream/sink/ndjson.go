@@ -0,0 +1,159 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ledgerwatch/erigon/zk/datastream/server"
+)
+
+const (
+	ndjsonPrefix = "datastream-"
+	ndjsonSuffix = ".ndjson"
+)
+
+// NDJSONSink appends the messages as newline delimited JSON to numbered files in a directory, moving on to the next
+// file once one is over its size and deleting the oldest beyond the number of files kept
+type NDJSONSink struct {
+	dir         string
+	maxFileSize int64
+	maxFiles    int
+
+	file *os.File
+	seq  uint64
+	size int64
+}
+
+// NewNDJSONSink appends to the last file in dir, no limit is kept on the file size or the number of files when 0
+func NewNDJSONSink(dir string, maxFileSize int64, maxFiles int) (*NDJSONSink, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	s := &NDJSONSink{dir: dir, maxFileSize: maxFileSize, maxFiles: maxFiles}
+
+	seqs, err := s.fileSeqs()
+	if err != nil {
+		return nil, err
+	}
+	if len(seqs) > 0 {
+		s.seq = seqs[len(seqs)-1]
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *NDJSONSink) Name() string {
+	return "ndjson"
+}
+
+func (s *NDJSONSink) Write(msgs []*server.BridgeMessage) error {
+	var buf bytes.Buffer
+	for _, msg := range msgs {
+		line, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		if s.maxFileSize > 0 && s.size > 0 && s.size+int64(buf.Len()+len(line)+1) > s.maxFileSize {
+			if err := s.write(buf.Bytes()); err != nil {
+				return err
+			}
+			buf.Reset()
+			if err := s.rotate(); err != nil {
+				return err
+			}
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return s.write(buf.Bytes())
+}
+
+func (s *NDJSONSink) Close() error {
+	return s.file.Close()
+}
+
+// write appends the lines and syncs them, they are delivered once on disk
+func (s *NDJSONSink) write(lines []byte) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	n, err := s.file.Write(lines)
+	s.size += int64(n)
+	if err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+func (s *NDJSONSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	s.seq++
+	if err := s.open(); err != nil {
+		return err
+	}
+
+	if s.maxFiles <= 0 {
+		return nil
+	}
+	seqs, err := s.fileSeqs()
+	if err != nil {
+		return err
+	}
+	for len(seqs) > s.maxFiles {
+		if err := os.Remove(s.path(seqs[0])); err != nil {
+			return err
+		}
+		seqs = seqs[1:]
+	}
+	return nil
+}
+
+func (s *NDJSONSink) open() error {
+	file, err := os.OpenFile(s.path(s.seq), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	s.file, s.size = file, info.Size()
+	return nil
+}
+
+func (s *NDJSONSink) path(seq uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%s%06d%s", ndjsonPrefix, seq, ndjsonSuffix))
+}
+
+// fileSeqs are the numbers of the files in the directory, in order
+func (s *NDJSONSink) fileSeqs() ([]uint64, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var seqs []uint64
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, ndjsonPrefix) || !strings.HasSuffix(name, ndjsonSuffix) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, ndjsonPrefix), ndjsonSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	return seqs, nil
+}
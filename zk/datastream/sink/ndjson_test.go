@@ -0,0 +1,62 @@
+package sink
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
+	"github.com/ledgerwatch/erigon/zk/datastream/server"
+	"github.com/stretchr/testify/require"
+)
+
+// readEntries reads the entry numbers of the messages in the file
+func readEntries(t *testing.T, path string) []uint64 {
+	t.Helper()
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var entries []uint64
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var msg struct {
+			Entry hexutil.Uint64 `json:"entry"`
+		}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &msg))
+		entries = append(entries, uint64(msg.Entry))
+	}
+	require.NoError(t, scanner.Err())
+	return entries
+}
+
+func TestNDJSONSink(t *testing.T) {
+	dir := t.TempDir()
+	msg := func(entry uint64) *server.BridgeMessage {
+		return &server.BridgeMessage{Type: server.BridgeMessageBatchEnd, Entry: hexutil.Uint64(entry), Data: &server.BridgeBatchEnd{Number: 1}}
+	}
+	line, err := json.Marshal(msg(0))
+	require.NoError(t, err)
+
+	// three lines to a file, two files kept
+	sink, err := NewNDJSONSink(dir, int64(3*(len(line)+1)), 2)
+	require.NoError(t, err)
+	require.NoError(t, sink.Write([]*server.BridgeMessage{msg(0), msg(1)}))
+	require.NoError(t, sink.Write([]*server.BridgeMessage{msg(2), msg(3), msg(4), msg(5)}))
+	require.NoError(t, sink.Close())
+
+	// reopened, it appends to the last file
+	sink, err = NewNDJSONSink(dir, int64(3*(len(line)+1)), 2)
+	require.NoError(t, err)
+	require.NoError(t, sink.Write([]*server.BridgeMessage{msg(6), msg(7)}))
+	require.NoError(t, sink.Close())
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.ndjson"))
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "datastream-000001.ndjson"), filepath.Join(dir, "datastream-000002.ndjson")}, files)
+	require.Equal(t, []uint64{3, 4, 5}, readEntries(t, files[0]))
+	require.Equal(t, []uint64{6, 7}, readEntries(t, files[1]))
+}
@@ -0,0 +1,90 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/ledgerwatch/erigon/zk/datastream/server"
+	kafka "github.com/segmentio/kafka-go"
+)
+
+const (
+	// all the messages go under the same key to keep them in order on one partition
+	producerKey          = "datastream"
+	producerWriteTimeout = 30 * time.Second
+)
+
+// ProducerMessage is a message for a message queue
+type ProducerMessage struct {
+	Key   []byte
+	Value []byte
+}
+
+// Producer publishes to a message queue, returning once the messages are acknowledged
+type Producer interface {
+	Produce(ctx context.Context, msgs []ProducerMessage) error
+	Close() error
+}
+
+// ProducerSink publishes the messages as JSON through a producer
+type ProducerSink struct {
+	name     string
+	producer Producer
+}
+
+func NewProducerSink(name string, producer Producer) *ProducerSink {
+	return &ProducerSink{name: name, producer: producer}
+}
+
+func (s *ProducerSink) Name() string {
+	return s.name
+}
+
+func (s *ProducerSink) Write(msgs []*server.BridgeMessage) error {
+	produced := make([]ProducerMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		value, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		produced = append(produced, ProducerMessage{Key: []byte(producerKey), Value: value})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), producerWriteTimeout)
+	defer cancel()
+	return s.producer.Produce(ctx, produced)
+}
+
+func (s *ProducerSink) Close() error {
+	return s.producer.Close()
+}
+
+// KafkaProducer produces to a Kafka topic, acknowledged by all the in-sync replicas
+type KafkaProducer struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaProducer(brokers []string, topic string) *KafkaProducer {
+	return &KafkaProducer{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			BatchTimeout: 10 * time.Millisecond,
+		},
+	}
+}
+
+func (p *KafkaProducer) Produce(ctx context.Context, msgs []ProducerMessage) error {
+	kafkaMsgs := make([]kafka.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		kafkaMsgs = append(kafkaMsgs, kafka.Message{Key: msg.Key, Value: msg.Value})
+	}
+	return p.writer.WriteMessages(ctx, kafkaMsgs...)
+}
+
+func (p *KafkaProducer) Close() error {
+	return p.writer.Close()
+}
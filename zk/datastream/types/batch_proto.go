@@ -45,6 +45,7 @@ type BatchEnd struct {
 	LocalExitRoot libcommon.Hash
 	StateRoot     libcommon.Hash
 	Debug         Debug
+	EntriesHash   libcommon.Hash
 }
 
 func (b *BatchEndProto) Marshal() ([]byte, error) {
@@ -81,5 +82,6 @@ func UnmarshalBatchEnd(data []byte) (*BatchEnd, error) {
 		LocalExitRoot: libcommon.BytesToHash(batchEnd.LocalExitRoot),
 		StateRoot:     libcommon.BytesToHash(batchEnd.StateRoot),
 		Debug:         ProcessDebug(batchEnd.Debug),
+		EntriesHash:   libcommon.BytesToHash(batchEnd.EntriesHash),
 	}, nil
 }
@@ -0,0 +1,124 @@
+package types
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/zk/datastream/proto/github.com/0xPolygonHermez/zkevm-node/state/datastream"
+	"google.golang.org/protobuf/proto"
+)
+
+// EntriesHashVersion is the first datastream version whose batch ends carry the entries hash
+const EntriesHashVersion = 4
+
+var ErrEntriesHashMismatch = errors.New("datastream entries hash mismatch")
+
+// EntriesHash is the rolling hash over the l2 block, transaction, l2 block end and batch end entries of the stream.
+// Each entry is hashed as keccak256(previous hash | entry type | data), a batch end with its entries hash left empty.
+// From datastream version 4 on every batch end carries the hash up to and including itself, and the hash restarts
+// from it, or from zero after a batch end without one, so a reader can verify every batch it sees from its start
+type EntriesHash struct {
+	hash libcommon.Hash
+	// positioned is set when every hashed entry since the last batch end has been seen
+	positioned bool
+	// sealed is set once a batch end carrying a hash has been seen, from then on all of them have to carry one
+	sealed    bool
+	lastBlock uint64
+}
+
+func (h *EntriesHash) Hash() libcommon.Hash {
+	return h.hash
+}
+
+// Reset sets the hash to the one of the last batch end, the entries after it still have to be added
+func (h *EntriesHash) Reset(batchEndHash libcommon.Hash) {
+	h.hash = batchEndHash
+	h.positioned = true
+}
+
+// Add hashes the entry, entries not covered by the hash are ignored
+func (h *EntriesHash) Add(entryType EntryType, data []byte) {
+	switch entryType {
+	case EntryTypeL2Block, EntryTypeL2Tx, EntryTypeL2BlockEnd, EntryTypeBatchEnd:
+	default:
+		return
+	}
+
+	var typeBytes [4]byte
+	binary.BigEndian.PutUint32(typeBytes[:], uint32(entryType))
+	h.hash = crypto.Keccak256Hash(h.hash[:], typeBytes[:], data)
+}
+
+// SealBatchEnd hashes the marshalled batch end and returns it with the resulting entries hash set
+func (h *EntriesHash) SealBatchEnd(data []byte) ([]byte, error) {
+	batchEnd, stripped, err := stripBatchEnd(data)
+	if err != nil {
+		return nil, err
+	}
+	h.Add(EntryTypeBatchEnd, stripped)
+	batchEnd.EntriesHash = h.hash.Bytes()
+
+	return proto.Marshal(batchEnd)
+}
+
+// Verify hashes the entry read from the stream and, on a batch end, checks the hash it carries.  A batch is only
+// verified when the reader has seen all of it, otherwise the hash of its batch end is taken as it comes
+func (h *EntriesHash) Verify(entry *FileEntry) error {
+	switch entry.EntryType {
+	case EntryTypeL2Block:
+		l2Block := &datastream.L2Block{}
+		if err := proto.Unmarshal(entry.Data, l2Block); err != nil {
+			return fmt.Errorf("unmarshal l2 block: %w", err)
+		}
+		// a reader resuming anywhere but after the last block it hashed misses entries of the batch
+		if l2Block.Number != h.lastBlock+1 {
+			h.positioned = false
+		}
+		h.lastBlock = l2Block.Number
+	case EntryTypeBatchEnd:
+		batchEnd, stripped, err := stripBatchEnd(entry.Data)
+		if err != nil {
+			return err
+		}
+		h.Add(EntryTypeBatchEnd, stripped)
+
+		if len(batchEnd.EntriesHash) == 0 {
+			if h.sealed {
+				return fmt.Errorf("%w: batch end %d carries no entries hash", ErrEntriesHashMismatch, batchEnd.Number)
+			}
+			h.Reset(libcommon.Hash{})
+			return nil
+		}
+
+		expected := libcommon.BytesToHash(batchEnd.EntriesHash)
+		if h.positioned && h.hash != expected {
+			return fmt.Errorf("%w: batch end %d carries %s, entries hash to %s", ErrEntriesHashMismatch, batchEnd.Number, expected, h.hash)
+		}
+		h.sealed = true
+		h.Reset(expected)
+		return nil
+	}
+
+	h.Add(entry.EntryType, entry.Data)
+	return nil
+}
+
+// stripBatchEnd returns the batch end and its marshalled form without the entries hash, the one that is hashed
+func stripBatchEnd(data []byte) (*datastream.BatchEnd, []byte, error) {
+	batchEnd := &datastream.BatchEnd{}
+	if err := proto.Unmarshal(data, batchEnd); err != nil {
+		return nil, nil, fmt.Errorf("unmarshal batch end: %w", err)
+	}
+	entriesHash := batchEnd.EntriesHash
+	batchEnd.EntriesHash = nil
+	stripped, err := proto.Marshal(batchEnd)
+	if err != nil {
+		return nil, nil, err
+	}
+	batchEnd.EntriesHash = entriesHash
+
+	return batchEnd, stripped, nil
+}
@@ -0,0 +1,96 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/ledgerwatch/erigon/zk/datastream/proto/github.com/0xPolygonHermez/zkevm-node/state/datastream"
+	"github.com/stretchr/testify/require"
+)
+
+// testBatchEntries returns the entries of batches with two blocks each, sealed as a server would with the given hash
+func testBatchEntries(t *testing.T, entriesHash *EntriesHash, fromBatch, toBatch, fromBlock uint64) []*FileEntry {
+	t.Helper()
+
+	var entries []*FileEntry
+	add := func(entryType EntryType, data []byte, err error) {
+		require.NoError(t, err)
+		if entryType == EntryTypeBatchEnd {
+			data, err = entriesHash.SealBatchEnd(data)
+			require.NoError(t, err)
+		} else {
+			entriesHash.Add(entryType, data)
+		}
+		entries = append(entries, &FileEntry{EntryType: entryType, Data: data})
+	}
+
+	block := fromBlock
+	for batch := fromBatch; batch <= toBatch; batch++ {
+		data, err := NewBookmarkProto(batch, datastream.BookmarkType_BOOKMARK_TYPE_BATCH).Marshal()
+		add(BookmarkEntryType, data, err)
+		data, err = (&BatchStartProto{BatchStart: &datastream.BatchStart{Number: batch}}).Marshal()
+		add(EntryTypeBatchStart, data, err)
+		for i := 0; i < 2; i++ {
+			data, err = (&L2BlockProto{L2Block: &datastream.L2Block{Number: block, BatchNumber: batch}}).Marshal()
+			add(EntryTypeL2Block, data, err)
+			data, err = (&L2BlockEndProto{Number: block}).Marshal()
+			add(EntryTypeL2BlockEnd, data, err)
+			block++
+		}
+		data, err = (&BatchEndProto{BatchEnd: &datastream.BatchEnd{Number: batch}}).Marshal()
+		add(EntryTypeBatchEnd, data, err)
+	}
+
+	return entries
+}
+
+func verifyEntries(h *EntriesHash, entries []*FileEntry) error {
+	for _, entry := range entries {
+		if err := h.Verify(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestEntriesHashVerify(t *testing.T) {
+	entries := testBatchEntries(t, &EntriesHash{}, 1, 3, 1)
+
+	// the batch ends carry the chained hash
+	batchEnd, err := UnmarshalBatchEnd(entries[len(entries)-1].Data)
+	require.NoError(t, err)
+	require.NotZero(t, batchEnd.EntriesHash)
+	require.Equal(t, uint64(3), batchEnd.Number)
+
+	// from the start, or from the middle of a batch
+	require.NoError(t, verifyEntries(&EntriesHash{}, entries))
+	require.NoError(t, verifyEntries(&EntriesHash{}, entries[4:]))
+
+	// a tampered block is caught at the end of its batch, unless the reader started within it
+	tampered := make([]*FileEntry, len(entries))
+	copy(tampered, entries)
+	data, err := (&L2BlockProto{L2Block: &datastream.L2Block{Number: 4, BatchNumber: 2, Timestamp: 1}}).Marshal()
+	require.NoError(t, err)
+	tampered[11] = &FileEntry{EntryType: EntryTypeL2Block, Data: data}
+	require.ErrorIs(t, verifyEntries(&EntriesHash{}, tampered), ErrEntriesHashMismatch)
+	require.NoError(t, verifyEntries(&EntriesHash{}, tampered[11:]))
+
+	// a reader skipping blocks doesn't verify the batch it skipped into
+	h := &EntriesHash{}
+	require.NoError(t, verifyEntries(h, entries[:8]))
+	require.NoError(t, verifyEntries(h, tampered[11:]))
+}
+
+func TestEntriesHashUpgrade(t *testing.T) {
+	// batches written before the upgrade carry no hash, the first one after it is hashed from zero
+	entries := testBatchEntries(t, &EntriesHash{}, 1, 1, 1)
+	unsealed, err := (&BatchEndProto{BatchEnd: &datastream.BatchEnd{Number: 1}}).Marshal()
+	require.NoError(t, err)
+	entries[len(entries)-1] = &FileEntry{EntryType: EntryTypeBatchEnd, Data: unsealed}
+	entries = append(entries, testBatchEntries(t, &EntriesHash{}, 2, 2, 3)...)
+	require.NoError(t, verifyEntries(&EntriesHash{}, entries))
+
+	// but once they are hashed a batch end without one is refused
+	entries = append(entries, testBatchEntries(t, &EntriesHash{}, 3, 3, 5)...)
+	entries[len(entries)-1] = &FileEntry{EntryType: EntryTypeBatchEnd, Data: unsealed}
+	require.ErrorIs(t, verifyEntries(&EntriesHash{}, entries), ErrEntriesHashMismatch)
+}
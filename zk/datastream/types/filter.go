@@ -0,0 +1,89 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/length"
+)
+
+// StreamFilter selects the entries sent on a stream started with CmdStartFiltered, the bookmarks are always sent so
+// the client can resume from them
+type StreamFilter struct {
+	// the entry types sent, all of them if empty
+	EntryTypes []EntryType
+	// a transaction is only sent if it is from one of Senders or to one of Recipients, any of them if both are empty
+	Senders    []common.Address
+	Recipients []common.Address
+}
+
+func (f *StreamFilter) HasEntryType(entryType EntryType) bool {
+	if len(f.EntryTypes) == 0 {
+		return true
+	}
+	for _, t := range f.EntryTypes {
+		if t == entryType {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *StreamFilter) FiltersAddresses() bool {
+	return len(f.Senders) > 0 || len(f.Recipients) > 0
+}
+
+// Encode encodes the filter as the count and the entry types, then the count and the senders and the same for the
+// recipients, all big endian
+func (f *StreamFilter) Encode() []byte {
+	be := binary.BigEndian.AppendUint32(nil, uint32(len(f.EntryTypes)))
+	for _, t := range f.EntryTypes {
+		be = binary.BigEndian.AppendUint32(be, uint32(t))
+	}
+	for _, addresses := range [][]common.Address{f.Senders, f.Recipients} {
+		be = binary.BigEndian.AppendUint32(be, uint32(len(addresses)))
+		for _, address := range addresses {
+			be = append(be, address.Bytes()...)
+		}
+	}
+	return be
+}
+
+func DecodeStreamFilter(b []byte) (*StreamFilter, error) {
+	readCount := func(size int) (int, error) {
+		if len(b) < 4 {
+			return 0, fmt.Errorf("invalid stream filter binary size %d", len(b))
+		}
+		count := int(binary.BigEndian.Uint32(b[:4]))
+		b = b[4:]
+		if len(b) < count*size {
+			return 0, fmt.Errorf("invalid stream filter binary size. Expected: >=%d, got: %d", count*size, len(b))
+		}
+		return count, nil
+	}
+
+	f := &StreamFilter{}
+	count, err := readCount(4)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < count; i++ {
+		f.EntryTypes = append(f.EntryTypes, EntryType(binary.BigEndian.Uint32(b[:4])))
+		b = b[4:]
+	}
+	for _, addresses := range []*[]common.Address{&f.Senders, &f.Recipients} {
+		if count, err = readCount(length.Addr); err != nil {
+			return nil, err
+		}
+		for i := 0; i < count; i++ {
+			*addresses = append(*addresses, common.BytesToAddress(b[:length.Addr]))
+			b = b[length.Addr:]
+		}
+	}
+	if len(b) != 0 {
+		return nil, fmt.Errorf("invalid stream filter binary size, %d bytes left over", len(b))
+	}
+
+	return f, nil
+}
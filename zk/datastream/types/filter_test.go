@@ -0,0 +1,35 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamFilterEncode(t *testing.T) {
+	filters := []*StreamFilter{
+		{},
+		{EntryTypes: []EntryType{EntryTypeBatchStart, EntryTypeGerUpdate}},
+		{EntryTypes: []EntryType{EntryTypeL2Tx}, Senders: []common.Address{common.HexToAddress("0x01")}},
+		{Senders: []common.Address{common.HexToAddress("0x01")}, Recipients: []common.Address{common.HexToAddress("0x02"), common.HexToAddress("0x03")}},
+	}
+	for _, filter := range filters {
+		decoded, err := DecodeStreamFilter(filter.Encode())
+		require.NoError(t, err)
+		require.Equal(t, filter, decoded)
+	}
+
+	encoded := filters[3].Encode()
+	_, err := DecodeStreamFilter(encoded[:len(encoded)-1])
+	require.Error(t, err)
+	_, err = DecodeStreamFilter(append(encoded, 0))
+	require.Error(t, err)
+}
+
+func TestStreamFilterHasEntryType(t *testing.T) {
+	require.True(t, (&StreamFilter{}).HasEntryType(EntryTypeL2Block))
+	filter := &StreamFilter{EntryTypes: []EntryType{EntryTypeBatchStart, EntryTypeBatchEnd}}
+	require.True(t, filter.HasEntryType(EntryTypeBatchEnd))
+	require.False(t, filter.HasEntryType(EntryTypeL2Block))
+}
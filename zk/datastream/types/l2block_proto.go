@@ -2,6 +2,7 @@ package types
 
 import (
 	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	eritypes "github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/zk/datastream/proto/github.com/0xPolygonHermez/zkevm-node/state/datastream"
 	"google.golang.org/protobuf/proto"
 )
@@ -55,7 +56,9 @@ type FullL2Block struct {
 	BlockGasLimit   uint64
 	BlockInfoRoot   libcommon.Hash
 	L2Txs           []L2TransactionProto
-	Debug           Debug
+	// L2Txs decoded by the decode workers of the client, nil when not decoded
+	DecodedTxs []eritypes.Transaction
+	Debug      Debug
 }
 
 func (b *L2BlockProto) Marshal() ([]byte, error) {
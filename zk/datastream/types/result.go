@@ -12,12 +12,14 @@ const (
 	ResultEntryMinSize = uint32(9)
 
 	// Command errors
-	CmdErrOK              = 0 // CmdErrOK for no error
-	CmdErrAlreadyStarted  = 1 // CmdErrAlreadyStarted for client already started error
-	CmdErrAlreadyStopped  = 2 // CmdErrAlreadyStopped for client already stopped error
-	CmdErrBadFromEntry    = 3 // CmdErrBadFromEntry for invalid starting entry number
-	CmdErrBadFromBookmark = 4 // CmdErrBadFromBookmark for invalid starting bookmark
-	CmdErrInvalidCommand  = 9 // CmdErrInvalidCommand for invalid/unknown command error
+	CmdErrOK              = 0  // CmdErrOK for no error
+	CmdErrAlreadyStarted  = 1  // CmdErrAlreadyStarted for client already started error
+	CmdErrAlreadyStopped  = 2  // CmdErrAlreadyStopped for client already stopped error
+	CmdErrBadFromEntry    = 3  // CmdErrBadFromEntry for invalid starting entry number
+	CmdErrBadFromBookmark = 4  // CmdErrBadFromBookmark for invalid starting bookmark
+	CmdErrInvalidCommand  = 9  // CmdErrInvalidCommand for invalid/unknown command error
+	CmdErrUnauthorized    = 10 // CmdErrUnauthorized for a missing or wrong authentication token
+	CmdErrPruned          = 11 // CmdErrPruned for a bookmark before the start of a pruned stream
 )
 
 var (
@@ -26,6 +28,8 @@ var (
 	ErrBadFromEntry    = errors.New("invalid starting entry number")
 	ErrBadFromBookmark = errors.New("invalid starting bookmark")
 	ErrInvalidCommand  = errors.New("invalid/unknown command")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrPruned          = errors.New("pruned from the stream")
 )
 
 type ResultEntry struct {
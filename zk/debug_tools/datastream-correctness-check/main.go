@@ -36,8 +36,14 @@ func main() {
 	progressBlock := uint64(0)
 	lastSeenBatch := uint64(0)
 	lastSeenBlock := uint64(0)
+	// checks the entries hash carried by the batch ends from datastream version 4 on
+	var entriesHash types.EntriesHash
 
 	function := func(file *types.FileEntry) error {
+		if err := entriesHash.Verify(file); err != nil {
+			return err
+		}
+
 		switch file.EntryType {
 		case types.EntryTypeL2BlockEnd:
 			if previousFile != nil && previousFile.EntryType != types.EntryTypeL2Block && previousFile.EntryType != types.EntryTypeL2Tx {
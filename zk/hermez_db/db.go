@@ -53,6 +53,11 @@ const PLAIN_STATE_VERSION = "plain_state_version"                       // batch
 const ERIGON_VERSIONS = "erigon_versions"                               // erigon version -> timestamp of startup
 const BATCH_ENDS = "batch_ends"                                         // batch number -> true
 const WITNESS_CACHE = "witness_cache"                                   // block number -> witness for 1 block
+const L1_FORCED_BATCHES = "l1_forced_batches"                           // forced batch number -> forced batch from the L1
+const BATCH_FORCED_BATCHES = "batch_forced_batches"                     // batch number -> forced batch number included in it
+const BATCH_CLOSE_INFO = "batch_close_info"                             // batch number -> why and how full the sequencer closed the batch
+const L1_SYNCER_BLOCK_HASHES = "l1_syncer_block_hashes"                 // l1 block number -> hash of the l1 block the l1 syncer stage took data from
+const L1_INFO_TREE_BLOCK_HASHES = "l1_info_tree_block_hashes"           // l1 block number -> hash of the l1 block the l1 info tree stage took data from
 
 var HermezDbTables = []string{
 	L1VERIFICATIONS,
@@ -91,6 +96,11 @@ var HermezDbTables = []string{
 	INNER_TX,
 	BATCH_ENDS,
 	WITNESS_CACHE,
+	L1_FORCED_BATCHES,
+	BATCH_FORCED_BATCHES,
+	BATCH_CLOSE_INFO,
+	L1_SYNCER_BLOCK_HASHES,
+	L1_INFO_TREE_BLOCK_HASHES,
 }
 
 type HermezDb struct {
@@ -674,6 +684,41 @@ func (db *HermezDb) TruncateVerifications(l2BlockNo uint64) error {
 	return nil
 }
 
+// DeleteSequencesFromL1Block deletes the sequences found in the given L1 block and after it
+func (db *HermezDb) DeleteSequencesFromL1Block(l1BlockNo uint64) error {
+	return db.deleteFromL1Block(L1SEQUENCES, l1BlockNo)
+}
+
+// DeleteVerificationsFromL1Block deletes the verifications found in the given L1 block and after it
+func (db *HermezDb) DeleteVerificationsFromL1Block(l1BlockNo uint64) error {
+	return db.deleteFromL1Block(L1VERIFICATIONS, l1BlockNo)
+}
+
+// deleteFromL1Block deletes the keys of a table keyed by l1 block number and batch number from the given l1 block on
+func (db *HermezDb) deleteFromL1Block(table string, l1BlockNo uint64) error {
+	c, err := db.tx.Cursor(table)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	var keys [][]byte
+	for k, _, err := c.Seek(ConcatKey(l1BlockNo, 0)); k != nil; k, _, err = c.Next() {
+		if err != nil {
+			return err
+		}
+		keys = append(keys, common.CopyBytes(k))
+	}
+
+	for _, k := range keys {
+		if err = db.tx.Delete(table, k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (db *HermezDb) WriteBlockBatch(l2BlockNo, batchNo uint64) error {
 	// first store the block -> batch record
 	err := db.tx.Put(BLOCKBATCHES, Uint64ToBytes(l2BlockNo), Uint64ToBytes(batchNo))
@@ -1399,6 +1444,149 @@ func (db *HermezDbReader) GetL1InjectedBatch(index uint64) (*types.L1InjectedBat
 	return ib, nil
 }
 
+func (db *HermezDb) WriteL1ForcedBatch(batch *types.L1ForcedBatch) error {
+	return db.tx.Put(L1_FORCED_BATCHES, Uint64ToBytes(batch.ForcedBatchNumber), batch.Marshall())
+}
+
+func (db *HermezDbReader) GetL1ForcedBatch(forcedBatchNo uint64) (*types.L1ForcedBatch, error) {
+	v, err := db.tx.GetOne(L1_FORCED_BATCHES, Uint64ToBytes(forcedBatchNo))
+	if err != nil {
+		return nil, err
+	}
+	if len(v) == 0 {
+		return nil, nil
+	}
+	fb := new(types.L1ForcedBatch)
+	if err = fb.Unmarshall(v); err != nil {
+		return nil, err
+	}
+	return fb, nil
+}
+
+func (db *HermezDbReader) GetLatestL1ForcedBatch() (*types.L1ForcedBatch, error) {
+	c, err := db.tx.Cursor(L1_FORCED_BATCHES)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	k, v, err := c.Last()
+	if err != nil {
+		return nil, err
+	}
+	if k == nil {
+		return nil, nil
+	}
+	fb := new(types.L1ForcedBatch)
+	if err = fb.Unmarshall(v); err != nil {
+		return nil, err
+	}
+	return fb, nil
+}
+
+// DeleteL1ForcedBatchesFromL1Block deletes the forced batches forced in the given L1 block and after it
+func (db *HermezDb) DeleteL1ForcedBatchesFromL1Block(l1BlockNo uint64) error {
+	for {
+		latest, err := db.GetLatestL1ForcedBatch()
+		if err != nil {
+			return err
+		}
+		if latest == nil || latest.L1BlockNumber < l1BlockNo {
+			return nil
+		}
+		if err = db.tx.Delete(L1_FORCED_BATCHES, Uint64ToBytes(latest.ForcedBatchNumber)); err != nil {
+			return err
+		}
+	}
+}
+
+// WriteBatchForcedBatch records that the given forced batch has been included in the L2 batch
+func (db *HermezDb) WriteBatchForcedBatch(batchNo, forcedBatchNo uint64) error {
+	return db.tx.Put(BATCH_FORCED_BATCHES, Uint64ToBytes(batchNo), Uint64ToBytes(forcedBatchNo))
+}
+
+func (db *HermezDbReader) GetBatchForcedBatch(batchNo uint64) (forcedBatchNo uint64, found bool, err error) {
+	v, err := db.tx.GetOne(BATCH_FORCED_BATCHES, Uint64ToBytes(batchNo))
+	if err != nil {
+		return 0, false, err
+	}
+	if len(v) == 0 {
+		return 0, false, nil
+	}
+	return BytesToUint64(v), true, nil
+}
+
+// GetBatchByForcedBatch returns the L2 batch that included the given forced batch
+func (db *HermezDbReader) GetBatchByForcedBatch(forcedBatchNo uint64) (batchNo uint64, found bool, err error) {
+	c, err := db.tx.Cursor(BATCH_FORCED_BATCHES)
+	if err != nil {
+		return 0, false, err
+	}
+	defer c.Close()
+
+	// forced batches are included in order so walk backwards from the latest inclusion
+	var k, v []byte
+	for k, v, err = c.Last(); err == nil && k != nil; k, v, err = c.Prev() {
+		included := BytesToUint64(v)
+		if included == forcedBatchNo {
+			return BytesToUint64(k), true, nil
+		}
+		if included < forcedBatchNo {
+			break
+		}
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return 0, false, nil
+}
+
+// GetLatestIncludedForcedBatch returns the highest forced batch number that has been included in an L2 batch
+func (db *HermezDbReader) GetLatestIncludedForcedBatch() (uint64, error) {
+	c, err := db.tx.Cursor(BATCH_FORCED_BATCHES)
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+
+	k, v, err := c.Last()
+	if err != nil {
+		return 0, err
+	}
+	if k == nil {
+		return 0, nil
+	}
+	return BytesToUint64(v), nil
+}
+
+func (db *HermezDb) DeleteBatchForcedBatches(fromBatchNo, toBatchNo uint64) error {
+	return db.deleteFromBucketWithUintKeysRange(BATCH_FORCED_BATCHES, fromBatchNo, toBatchNo)
+}
+
+func (db *HermezDb) WriteBatchCloseInfo(batchNo uint64, info *types.BatchCloseInfo) error {
+	return db.tx.Put(BATCH_CLOSE_INFO, Uint64ToBytes(batchNo), info.Marshall())
+}
+
+func (db *HermezDbReader) GetBatchCloseInfo(batchNo uint64) (*types.BatchCloseInfo, error) {
+	v, err := db.tx.GetOne(BATCH_CLOSE_INFO, Uint64ToBytes(batchNo))
+	if err != nil {
+		return nil, err
+	}
+	if len(v) == 0 {
+		return nil, nil
+	}
+	info := new(types.BatchCloseInfo)
+	if err = info.Unmarshall(v); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (db *HermezDb) DeleteBatchCloseInfos(fromBatchNo, toBatchNo uint64) error {
+	return db.deleteFromBucketWithUintKeysRange(BATCH_CLOSE_INFO, fromBatchNo, toBatchNo)
+}
+
 func (db *HermezDb) WriteBlockInfoRoot(blockNumber uint64, root common.Hash) error {
 	k := Uint64ToBytes(blockNumber)
 	return db.tx.Put(BLOCK_INFO_ROOTS, k, root.Bytes())
@@ -1640,6 +1828,61 @@ func (db *HermezDbReader) GetL1InfoTreeIndexToRoots() (map[uint64]common.Hash, e
 	return indexToRoot, nil
 }
 
+// DeleteL1InfoTreeUpdatesFromL1Block deletes the l1 info tree updates made in the given L1 block and after it, along
+// with their leaves and the roots of the tree they made
+func (db *HermezDb) DeleteL1InfoTreeUpdatesFromL1Block(l1BlockNo uint64) error {
+	var fromIndex uint64
+	deleted := false
+	for {
+		latest, err := db.GetLatestL1InfoTreeUpdate()
+		if err != nil {
+			return err
+		}
+		if latest == nil || latest.BlockNumber < l1BlockNo {
+			break
+		}
+
+		if err = db.tx.Delete(L1_INFO_TREE_UPDATES, Uint64ToBytes(latest.Index)); err != nil {
+			return err
+		}
+		// the same GER can be the result of an earlier update, only the update it points to goes
+		byGer, err := db.GetL1InfoTreeUpdateByGer(latest.GER)
+		if err != nil {
+			return err
+		}
+		if byGer != nil && byGer.Index == latest.Index {
+			if err = db.tx.Delete(L1_INFO_TREE_UPDATES_BY_GER, latest.GER.Bytes()); err != nil {
+				return err
+			}
+		}
+		if err = db.tx.Delete(L1_INFO_LEAVES, Uint64ToBytes(latest.Index)); err != nil {
+			return err
+		}
+
+		fromIndex = latest.Index
+		deleted = true
+	}
+
+	if !deleted {
+		return nil
+	}
+
+	indexToRoots, err := db.GetL1InfoTreeIndexToRoots()
+	if err != nil {
+		return err
+	}
+	for index, root := range indexToRoots {
+		if index < fromIndex {
+			continue
+		}
+		if err = db.tx.Delete(L1_INFO_ROOTS, root.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (db *HermezDbReader) GetForkIdByBlockNum(blockNum uint64) (uint64, error) {
 	blockbatch, err := db.GetBatchNoByL2Block(blockNum)
 	if err != nil {
@@ -1907,3 +2150,78 @@ func (db *HermezDbReader) GetWitnessCache(blockNo uint64) ([]byte, error) {
 func (db *HermezDb) DeleteWitnessCaches(from, to uint64) error {
 	return db.deleteFromBucketWithUintKeysRange(WITNESS_CACHE, from, to)
 }
+
+// WriteL1BlockHash records the hash of an L1 block data was taken from in one of the L1 block hash tables, so that a
+// reorg of the L1 past it can be found
+func (db *HermezDb) WriteL1BlockHash(table string, l1BlockNo uint64, hash common.Hash) error {
+	return db.tx.Put(table, Uint64ToBytes(l1BlockNo), hash.Bytes())
+}
+
+// GetL1BlockHashes returns the L1 block hashes recorded in one of the L1 block hash tables, by L1 block number
+func (db *HermezDbReader) GetL1BlockHashes(table string) (map[uint64]common.Hash, error) {
+	c, err := db.tx.Cursor(table)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	hashes := make(map[uint64]common.Hash)
+	for k, v, err := c.First(); k != nil; k, v, err = c.Next() {
+		if err != nil {
+			return nil, err
+		}
+		hashes[BytesToUint64(k)] = common.BytesToHash(v)
+	}
+
+	return hashes, nil
+}
+
+// TruncateL1BlockHashes deletes the L1 block hashes recorded from the given L1 block on
+func (db *HermezDb) TruncateL1BlockHashes(table string, fromL1BlockNo uint64) error {
+	c, err := db.tx.Cursor(table)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	var keys [][]byte
+	for k, _, err := c.Seek(Uint64ToBytes(fromL1BlockNo)); k != nil; k, _, err = c.Next() {
+		if err != nil {
+			return err
+		}
+		keys = append(keys, common.CopyBytes(k))
+	}
+
+	for _, k := range keys {
+		if err = db.tx.Delete(table, k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PruneL1BlockHashes deletes the L1 block hashes recorded before the given L1 block
+func (db *HermezDb) PruneL1BlockHashes(table string, toL1BlockNo uint64) error {
+	c, err := db.tx.Cursor(table)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	var keys [][]byte
+	for k, _, err := c.First(); k != nil && BytesToUint64(k) < toL1BlockNo; k, _, err = c.Next() {
+		if err != nil {
+			return err
+		}
+		keys = append(keys, common.CopyBytes(k))
+	}
+
+	for _, k := range keys {
+		if err = db.tx.Delete(table, k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
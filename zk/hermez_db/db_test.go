@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"math/big"
 	"testing"
 
 	"github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon-lib/kv/mdbx"
+	"github.com/ledgerwatch/erigon/zk/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -622,3 +624,145 @@ func TestDeleteForkId(t *testing.T) {
 		})
 	}
 }
+
+func TestForcedBatches(t *testing.T) {
+	tx, cleanup := GetDbTx()
+	defer cleanup()
+	db := NewHermezDb(tx)
+
+	latest, err := db.GetLatestL1ForcedBatch()
+	require.NoError(t, err)
+	assert.Nil(t, latest)
+
+	for i := uint64(1); i <= 3; i++ {
+		require.NoError(t, db.WriteL1ForcedBatch(&types.L1ForcedBatch{
+			ForcedBatchNumber: i,
+			L1BlockNumber:     100 + i,
+			ForcedAt:          1000 + i,
+			Transactions:      []byte{byte(i)},
+		}))
+	}
+
+	fb, err := db.GetL1ForcedBatch(2)
+	require.NoError(t, err)
+	require.NotNil(t, fb)
+	assert.Equal(t, uint64(102), fb.L1BlockNumber)
+	assert.Equal(t, []byte{2}, fb.Transactions)
+
+	fb, err = db.GetL1ForcedBatch(10)
+	require.NoError(t, err)
+	assert.Nil(t, fb)
+
+	latest, err = db.GetLatestL1ForcedBatch()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), latest.ForcedBatchNumber)
+
+	require.NoError(t, db.WriteBatchForcedBatch(10, 1))
+	require.NoError(t, db.WriteBatchForcedBatch(15, 2))
+
+	included, err := db.GetLatestIncludedForcedBatch()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), included)
+
+	batchNo, found, err := db.GetBatchByForcedBatch(1)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, uint64(10), batchNo)
+
+	_, found, err = db.GetBatchByForcedBatch(3)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	forcedBatchNo, found, err := db.GetBatchForcedBatch(15)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, uint64(2), forcedBatchNo)
+
+	require.NoError(t, db.DeleteBatchForcedBatches(12, 20))
+	included, err = db.GetLatestIncludedForcedBatch()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), included)
+}
+
+func TestBatchCloseInfo(t *testing.T) {
+	tx, cleanup := GetDbTx()
+	defer cleanup()
+	db := NewHermezDb(tx)
+
+	for i := uint64(1); i <= 3; i++ {
+		require.NoError(t, db.WriteBatchCloseInfo(i, &types.BatchCloseInfo{
+			Reason:           "EmptyBatchTimeOut",
+			BlockCount:       i,
+			TransactionCount: 10 * i,
+		}))
+	}
+
+	info, err := db.GetBatchCloseInfo(2)
+	require.NoError(t, err)
+	require.NotNil(t, info)
+	assert.Equal(t, "EmptyBatchTimeOut", info.Reason)
+	assert.Equal(t, uint64(20), info.TransactionCount)
+
+	require.NoError(t, db.DeleteBatchCloseInfos(2, 3))
+	info, err = db.GetBatchCloseInfo(2)
+	require.NoError(t, err)
+	assert.Nil(t, info)
+
+	info, err = db.GetBatchCloseInfo(1)
+	require.NoError(t, err)
+	assert.NotNil(t, info)
+}
+
+func TestDeleteFromL1Block(t *testing.T) {
+	tx, cleanup := GetDbTx()
+	defer cleanup()
+	db := NewHermezDb(tx)
+
+	for i := uint64(1); i <= 4; i++ {
+		l1BlockNo := 100 + i
+		require.NoError(t, db.WriteSequence(l1BlockNo, i, common.HexToHash("0x1"), common.Hash{}, common.Hash{}))
+		require.NoError(t, db.WriteVerification(l1BlockNo, i, common.HexToHash("0x2"), common.HexToHash("0x3")))
+		require.NoError(t, db.WriteL1ForcedBatch(&types.L1ForcedBatch{ForcedBatchNumber: i, L1BlockNumber: l1BlockNo}))
+
+		update := &types.L1InfoTreeUpdate{Index: i - 1, GER: common.BigToHash(big.NewInt(int64(i))), BlockNumber: l1BlockNo}
+		require.NoError(t, db.WriteL1InfoTreeUpdate(update))
+		require.NoError(t, db.WriteL1InfoTreeUpdateToGer(update))
+		require.NoError(t, db.WriteL1InfoTreeLeaf(update.Index, common.BigToHash(big.NewInt(int64(10+i)))))
+		require.NoError(t, db.WriteL1InfoTreeRoot(common.BigToHash(big.NewInt(int64(20+i))), update.Index))
+		require.NoError(t, db.WriteL1BlockHash(L1_SYNCER_BLOCK_HASHES, l1BlockNo, common.BigToHash(big.NewInt(int64(30+i)))))
+	}
+
+	require.NoError(t, db.DeleteSequencesFromL1Block(103))
+	require.NoError(t, db.DeleteVerificationsFromL1Block(103))
+	require.NoError(t, db.DeleteL1ForcedBatchesFromL1Block(103))
+	require.NoError(t, db.DeleteL1InfoTreeUpdatesFromL1Block(103))
+	require.NoError(t, db.TruncateL1BlockHashes(L1_SYNCER_BLOCK_HASHES, 103))
+	require.NoError(t, db.PruneL1BlockHashes(L1_SYNCER_BLOCK_HASHES, 102))
+
+	sequence, err := db.GetLatestSequence()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), sequence.BatchNo)
+	verification, err := db.GetLatestVerification()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), verification.BatchNo)
+	forcedBatch, err := db.GetLatestL1ForcedBatch()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), forcedBatch.ForcedBatchNumber)
+
+	update, err := db.GetLatestL1InfoTreeUpdate()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), update.Index)
+	update, err = db.GetL1InfoTreeUpdateByGer(common.BigToHash(big.NewInt(3)))
+	require.NoError(t, err)
+	assert.Nil(t, update)
+	leaves, err := db.GetAllL1InfoTreeLeaves()
+	require.NoError(t, err)
+	assert.Equal(t, []common.Hash{common.BigToHash(big.NewInt(11)), common.BigToHash(big.NewInt(12))}, leaves)
+	roots, err := db.GetL1InfoTreeIndexToRoots()
+	require.NoError(t, err)
+	assert.Equal(t, map[uint64]common.Hash{0: common.BigToHash(big.NewInt(21)), 1: common.BigToHash(big.NewInt(22))}, roots)
+
+	hashes, err := db.GetL1BlockHashes(L1_SYNCER_BLOCK_HASHES)
+	require.NoError(t, err)
+	assert.Equal(t, map[uint64]common.Hash{102: common.BigToHash(big.NewInt(32))}, hashes)
+}
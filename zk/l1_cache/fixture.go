@@ -0,0 +1,90 @@
+package l1_cache
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// fixtureEntry is a line of a fixture, the cache key of a request and the response to it.  A response that isn't JSON
+// is kept as is in Raw
+type fixtureEntry struct {
+	Key      string          `json:"key"`
+	Response json.RawMessage `json:"response,omitempty"`
+	Raw      []byte          `json:"raw,omitempty"`
+}
+
+// ExportFixture writes the responses served during the run to a fixture at the path, one JSON line per request sorted
+// by key so that fixtures of the same run compare equal.  Only available in record mode
+func (c *L1Cache) ExportFixture(path string) error {
+	if c.mode != ModeRecord {
+		return fmt.Errorf("the l1 cache only exports fixtures in %s mode", ModeRecord)
+	}
+
+	c.servedMtx.Lock()
+	served := make(map[string][]byte, len(c.served))
+	keys := make([]string, 0, len(c.served))
+	for key, response := range c.served {
+		served[key] = response
+		keys = append(keys, key)
+	}
+	c.servedMtx.Unlock()
+	sort.Strings(keys)
+
+	// written next to the fixture and moved over it so that a failed export doesn't leave a partial one behind
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	w := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(w)
+	for _, key := range keys {
+		entry := fixtureEntry{Key: key}
+		if response := served[key]; json.Valid(response) {
+			entry.Response = response
+		} else {
+			entry.Raw = response
+		}
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func loadFixture(path string) (map[string][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the l1 cache fixture: %w", err)
+	}
+	defer f.Close()
+
+	fixture := make(map[string][]byte)
+	decoder := json.NewDecoder(bufio.NewReader(f))
+	for decoder.More() {
+		var entry fixtureEntry
+		if err := decoder.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("invalid l1 cache fixture %s: %w", path, err)
+		}
+		if entry.Raw != nil {
+			fixture[entry.Key] = entry.Raw
+		} else {
+			fixture[entry.Key] = entry.Response
+		}
+	}
+
+	return fixture, nil
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	"errors"
@@ -35,60 +36,138 @@ var paramsToExpire = map[string]struct{}{
 	"finalized": {},
 }
 
+// the modes the L1 cache runs in
+const (
+	// ModeCache serves the responses cached, fetching and caching the rest from upstream
+	ModeCache = "cache"
+	// ModeRecord caches as ModeCache does and, when closed, exports every response served during the run to a fixture
+	ModeRecord = "record"
+	// ModeReplay serves only the responses in a fixture, without a database or upstream, failing requests not in it
+	ModeReplay = "replay"
+)
+
 type L1Cache struct {
-	server *http.Server
-	db     kv.RwDB
+	server      *http.Server
+	db          kv.RwDB
+	mode        string
+	fixturePath string
+
+	// record mode: the last response served for each request during the run, kept apart from the cache so that the
+	// errors and responses it doesn't cache are recorded too
+	servedMtx sync.Mutex
+	served    map[string][]byte
+	// replay mode: the responses of the fixture by key
+	fixture map[string][]byte
+
+	closeOnce sync.Once
+	closeErr  error
 }
 
-func NewL1Cache(ctx context.Context, dbPath string, port uint) (*L1Cache, error) {
-	db := mdbx.NewMDBX(log.New()).Path(dbPath).MustOpen()
-
-	tx, err := db.BeginRw(ctx)
-	if err != nil {
-		return nil, err
+func NewL1Cache(ctx context.Context, dbPath string, port uint, mode, fixturePath string) (*L1Cache, error) {
+	if mode == "" {
+		mode = ModeCache
 	}
-	defer tx.Rollback()
-
-	if err := tx.CreateBucket(bucketName); err != nil {
-		return nil, err
+	c := &L1Cache{
+		mode:        mode,
+		fixturePath: fixturePath,
 	}
-	if err := tx.CreateBucket(expiryBucket); err != nil {
-		return nil, err
+
+	switch mode {
+	case ModeCache:
+	case ModeRecord, ModeReplay:
+		if fixturePath == "" {
+			return nil, fmt.Errorf("the l1 cache needs a fixture file to %s", mode)
+		}
+	default:
+		return nil, fmt.Errorf("unknown l1 cache mode %q", mode)
 	}
-	if err := tx.Commit(); err != nil {
-		return nil, err
+
+	if mode == ModeReplay {
+		fixture, err := loadFixture(fixturePath)
+		if err != nil {
+			return nil, err
+		}
+		c.fixture = fixture
+		log.Info("Replaying the L1 from a fixture", "fixture", fixturePath, "responses", len(fixture))
+	} else {
+		db := mdbx.NewMDBX(log.New()).Path(dbPath).MustOpen()
+
+		tx, err := db.BeginRw(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer tx.Rollback()
+
+		if err := tx.CreateBucket(bucketName); err != nil {
+			return nil, err
+		}
+		if err := tx.CreateBucket(expiryBucket); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		c.db = db
+		c.served = make(map[string][]byte)
 	}
 
-	http.HandleFunc("/", handleRequest(db))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", c.handleRequest())
 	addr := fmt.Sprintf(":%d", port)
-	server := &http.Server{
+	c.server = &http.Server{
 		Addr:           addr,
-		Handler:        nil,
+		Handler:        mux,
 		ReadTimeout:    10 * time.Second,
 		WriteTimeout:   10 * time.Second,
 		MaxHeaderBytes: 1 << 20,
 	}
 
 	go func() {
-		log.Info("Starting L1 Cache Server on port:", "port", port)
-		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Info("Starting L1 Cache Server on port:", "port", port, "mode", mode)
+		if err := c.server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
 			log.Error("L1 Cache Server stopped", "error", err)
 		}
 	}()
 
 	go func() {
 		<-ctx.Done()
+		if err := c.Close(); err != nil {
+			log.Error("Failed to close L1 Cache Server", "error", err)
+		}
+	}()
+
+	return c, nil
+}
+
+// Close shuts the server down and, in record mode, exports the responses served during the run to the fixture
+func (c *L1Cache) Close() error {
+	c.closeOnce.Do(func() {
 		log.Info("Shutting down L1 Cache Server...")
-		if err := server.Shutdown(context.Background()); err != nil {
+		if err := c.server.Shutdown(context.Background()); err != nil {
 			log.Error("Failed to shutdown L1 Cache Server", "error", err)
 		}
-		db.Close()
-	}()
+		if c.db == nil {
+			return
+		}
+		defer c.db.Close()
+
+		if c.mode == ModeRecord {
+			if c.closeErr = c.ExportFixture(c.fixturePath); c.closeErr == nil {
+				log.Info("Recorded the L1 to a fixture", "fixture", c.fixturePath, "responses", len(c.served))
+			}
+		}
+	})
 
-	return &L1Cache{
-		server: server,
-		db:     db,
-	}, nil
+	return c.closeErr
+}
+
+func (c *L1Cache) markServed(key string, response []byte) {
+	if c.mode != ModeRecord {
+		return
+	}
+	c.servedMtx.Lock()
+	c.served[key] = response
+	c.servedMtx.Unlock()
 }
 
 func fetchFromCache(tx kv.RwTx, key string) ([]byte, bool) {
@@ -157,7 +236,7 @@ func generateCacheKey(chainID string, body []byte) (string, error) {
 	return fmt.Sprintf("%s_%s", chainID, modifiedBody), nil
 }
 
-func handleRequest(db kv.RwDB) http.HandlerFunc {
+func (c *L1Cache) handleRequest() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		endpoint := r.URL.Query().Get("endpoint")
 		chainID := r.URL.Query().Get("chainid")
@@ -191,8 +270,21 @@ func handleRequest(db kv.RwDB) http.HandlerFunc {
 			return
 		}
 
+		if c.mode == ModeReplay {
+			cachedResponse, found := c.fixture[cacheKey]
+			if !found {
+				log.Error("L1 cache replay miss, the request is not in the fixture", "fixture", c.fixturePath, "method", method, "request", cacheKey)
+				http.Error(w, fmt.Sprintf("l1 cache replay miss, %s is not in the fixture %s", cacheKey, c.fixturePath), http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-Cache-Status", "HIT")
+			w.Write(cachedResponse)
+			return
+		}
+
 		if _, ignore := methodsToIgnore[method]; !ignore {
-			tx, err := db.BeginRw(r.Context())
+			tx, err := c.db.BeginRw(r.Context())
 			if err != nil {
 				http.Error(w, "Failed to begin transaction", http.StatusInternalServerError)
 				return
@@ -202,6 +294,7 @@ func handleRequest(db kv.RwDB) http.HandlerFunc {
 				w.Header().Set("X-Cache-Status", "HIT")
 				w.Write(cachedResponse)
 				tx.Commit()
+				c.markServed(cacheKey, cachedResponse)
 				return
 			}
 			tx.Rollback()
@@ -244,7 +337,7 @@ func handleRequest(db kv.RwDB) http.HandlerFunc {
 								cacheDuration = duration
 							}
 						}
-						tx, err := db.BeginRw(r.Context())
+						tx, err := c.db.BeginRw(r.Context())
 						if err != nil {
 							http.Error(w, "Failed to begin transaction", http.StatusInternalServerError)
 							return
@@ -258,12 +351,13 @@ func handleRequest(db kv.RwDB) http.HandlerFunc {
 					}
 				}
 			} else {
-				fmt.Println("Failed to parse upstream response, not caching")
+				log.Warn("Failed to parse upstream response, not caching", "error", err)
 			}
 		}
 
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("X-Cache-Status", "MISS")
 		w.Write(responseBody)
+		c.markServed(cacheKey, responseBody)
 	}
 }
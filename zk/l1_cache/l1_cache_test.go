@@ -0,0 +1,157 @@
+package l1_cache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func freePort(t *testing.T) uint {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return uint(l.Addr().(*net.TCPAddr).Port)
+}
+
+// call posts the JSON-RPC request to the cache in front of the upstream, returning the status and body of the response
+func call(t *testing.T, port uint, upstream, request string) (int, string) {
+	t.Helper()
+
+	cacheUrl := fmt.Sprintf("http://127.0.0.1:%d?endpoint=%s&chainid=1", port, url.QueryEscape(upstream))
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		var err error
+		resp, err = http.Post(cacheUrl, "application/json", bytes.NewBufferString(request))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, strings.TrimSpace(string(body))
+}
+
+func TestL1CacheRecordReplay(t *testing.T) {
+	var upstreamCalls atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamCalls.Add(1)
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		if strings.Contains(string(body), "eth_chainId") {
+			_, err = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0xaa36a7"}`))
+		} else {
+			_, err = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"number":"0x10"}}`))
+		}
+		require.NoError(t, err)
+	}))
+	dir := t.TempDir()
+	fixture := filepath.Join(dir, "l1.fixture")
+	chainId := `{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[]}`
+	block := `{"jsonrpc":"2.0","id":2,"method":"eth_getBlockByNumber","params":["0x10",false]}`
+
+	// a response already cached before the run is not recorded unless it is served during it
+	port := freePort(t)
+	cache, err := NewL1Cache(context.Background(), filepath.Join(dir, "l1cache"), port, ModeCache, "")
+	require.NoError(t, err)
+	call(t, port, upstream.URL, `{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`)
+	require.NoError(t, cache.Close())
+
+	port = freePort(t)
+	cache, err = NewL1Cache(context.Background(), filepath.Join(dir, "l1cache"), port, ModeRecord, fixture)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		status, body := call(t, port, upstream.URL, chainId)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, `{"jsonrpc":"2.0","id":1,"result":"0xaa36a7"}`, body)
+	}
+	status, _ := call(t, port, upstream.URL, block)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, int32(3), upstreamCalls.Load())
+	require.NoError(t, cache.Close())
+
+	recorded, err := os.ReadFile(fixture)
+	require.NoError(t, err)
+	require.Equal(t, `{"key":"1_{\"jsonrpc\":\"2.0\",\"method\":\"eth_chainId\",\"params\":[]}","response":{"jsonrpc":"2.0","id":1,"result":"0xaa36a7"}}
+{"key":"1_{\"jsonrpc\":\"2.0\",\"method\":\"eth_getBlockByNumber\",\"params\":[\"0x10\",false]}","response":{"jsonrpc":"2.0","id":1,"result":{"number":"0x10"}}}
+`, string(recorded))
+
+	// replaying serves the run without reaching upstream, anything else fails
+	upstream.Close()
+	port = freePort(t)
+	cache, err = NewL1Cache(context.Background(), "", port, ModeReplay, fixture)
+	require.NoError(t, err)
+	defer cache.Close()
+	status, body := call(t, port, upstream.URL, chainId)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, `{"jsonrpc":"2.0","id":1,"result":"0xaa36a7"}`, body)
+	status, body = call(t, port, upstream.URL, block)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, `{"jsonrpc":"2.0","id":1,"result":{"number":"0x10"}}`, body)
+	status, body = call(t, port, upstream.URL, `{"jsonrpc":"2.0","id":3,"method":"eth_getBlockByNumber","params":["0x11",false]}`)
+	require.Equal(t, http.StatusNotFound, status)
+	require.Contains(t, body, "l1 cache replay miss")
+	require.Equal(t, int32(3), upstreamCalls.Load())
+
+	_, err = NewL1Cache(context.Background(), "", freePort(t), ModeReplay, "")
+	require.ErrorContains(t, err, "the l1 cache needs a fixture file to replay")
+}
+
+func TestL1CacheRecordsErrors(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		if strings.Contains(string(body), "eth_call") {
+			_, err = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"execution reverted"}}`))
+		} else {
+			w.WriteHeader(http.StatusBadGateway)
+			_, err = w.Write([]byte(`upstream unavailable`))
+		}
+		require.NoError(t, err)
+	}))
+	defer upstream.Close()
+	dir := t.TempDir()
+	fixture := filepath.Join(dir, "l1.fixture")
+	ethCall := `{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[]}`
+	getLogs := `{"jsonrpc":"2.0","id":2,"method":"eth_getLogs","params":[]}`
+
+	// neither response is cached, both are recorded as they were served
+	port := freePort(t)
+	cache, err := NewL1Cache(context.Background(), filepath.Join(dir, "l1cache"), port, ModeRecord, fixture)
+	require.NoError(t, err)
+	_, body := call(t, port, upstream.URL, ethCall)
+	require.Equal(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"execution reverted"}}`, body)
+	_, body = call(t, port, upstream.URL, getLogs)
+	require.Equal(t, `upstream unavailable`, body)
+	require.NoError(t, cache.Close())
+
+	recorded, err := os.ReadFile(fixture)
+	require.NoError(t, err)
+	require.Equal(t, `{"key":"1_{\"jsonrpc\":\"2.0\",\"method\":\"eth_call\",\"params\":[]}","response":{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"execution reverted"}}}
+{"key":"1_{\"jsonrpc\":\"2.0\",\"method\":\"eth_getLogs\",\"params\":[]}","raw":"dXBzdHJlYW0gdW5hdmFpbGFibGU="}
+`, string(recorded))
+
+	port = freePort(t)
+	cache, err = NewL1Cache(context.Background(), "", port, ModeReplay, fixture)
+	require.NoError(t, err)
+	defer cache.Close()
+	status, body := call(t, port, upstream.URL, ethCall)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"execution reverted"}}`, body)
+	status, body = call(t, port, upstream.URL, getLogs)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, `upstream unavailable`, body)
+}
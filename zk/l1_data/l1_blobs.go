@@ -0,0 +1,96 @@
+package l1_data
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
+	"github.com/ledgerwatch/erigon-lib/common"
+)
+
+const (
+	// the data of a blob is carried in the 31 low bytes of each of its field elements, the high byte is always 0 so
+	// that the element is below the modulus
+	blobFieldElementSize = 32
+	blobFieldElementData = 31
+	blobDataSize         = gokzg4844.ScalarsPerBlob * blobFieldElementData
+
+	blobCompressionNone = 0
+)
+
+// DecodeBlobBatches decodes the batch L2 data carried in the blobs of a sequence transaction, in order.  The data of
+// each blob is a compression type byte, of which only no compression is supported, the 4 byte length of its body and
+// the body.  The body is the batches one after the other, each the 4 byte length of its batch L2 data and the data
+func DecodeBlobBatches(blobs [][]byte) ([][]byte, error) {
+	var batches [][]byte
+	for i, blob := range blobs {
+		data, err := blobData(blob)
+		if err != nil {
+			return nil, fmt.Errorf("blob %d: %w", i, err)
+		}
+
+		if data[0] != blobCompressionNone {
+			return nil, fmt.Errorf("blob %d: unsupported compression type %d", i, data[0])
+		}
+		bodyLength := uint64(binary.BigEndian.Uint32(data[1:5]))
+		if bodyLength > uint64(len(data)-5) {
+			return nil, fmt.Errorf("blob %d: body of %d bytes doesn't fit in the blob", i, bodyLength)
+		}
+
+		body := data[5 : 5+bodyLength]
+		for len(body) > 0 {
+			if len(body) < 4 {
+				return nil, fmt.Errorf("blob %d: truncated batch length", i)
+			}
+			batchLength := uint64(binary.BigEndian.Uint32(body[:4]))
+			if batchLength > uint64(len(body)-4) {
+				return nil, fmt.Errorf("blob %d: batch of %d bytes doesn't fit in the body", i, batchLength)
+			}
+			batches = append(batches, common.CopyBytes(body[4:4+batchLength]))
+			body = body[4+batchLength:]
+		}
+	}
+
+	return batches, nil
+}
+
+// blobData strips the high byte of each field element of the blob
+func blobData(blob []byte) ([]byte, error) {
+	if len(blob) != gokzg4844.ScalarsPerBlob*blobFieldElementSize {
+		return nil, fmt.Errorf("blob of %d bytes", len(blob))
+	}
+
+	data := make([]byte, 0, blobDataSize)
+	for i := 0; i < len(blob); i += blobFieldElementSize {
+		if blob[i] != 0 {
+			return nil, fmt.Errorf("field element %d has a non-zero high byte", i/blobFieldElementSize)
+		}
+		data = append(data, blob[i+1:i+blobFieldElementSize]...)
+	}
+
+	return data, nil
+}
+
+// DecodeL1BlobBatchData decodes a sequence transaction carrying its batch L2 data in blobs.  The sequences in the call
+// data are left without transactions, there is one batch in the blobs for each of them
+func DecodeL1BlobBatchData(txData []byte, blobs [][]byte) ([][]byte, common.Address, uint64, error) {
+	sequences, coinbase, limitTimestamp, err := DecodeL1BatchData(txData, nil)
+	if err != nil {
+		return nil, common.Address{}, 0, err
+	}
+	for i, sequence := range sequences {
+		if len(sequence) > 0 {
+			return nil, common.Address{}, 0, fmt.Errorf("sequence %d carries its transactions in both the call data and the blobs", i)
+		}
+	}
+
+	batches, err := DecodeBlobBatches(blobs)
+	if err != nil {
+		return nil, common.Address{}, 0, err
+	}
+	if len(batches) != len(sequences) {
+		return nil, common.Address{}, 0, fmt.Errorf("%d batches in the blobs for %d sequences", len(batches), len(sequences))
+	}
+
+	return batches, coinbase, limitTimestamp, nil
+}
@@ -0,0 +1,84 @@
+package l1_data
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon/accounts/abi"
+	"github.com/ledgerwatch/erigon/zk/contracts"
+	"github.com/stretchr/testify/require"
+)
+
+// encodeBlob packs the batches into an uncompressed blob
+func encodeBlob(t *testing.T, batches ...[]byte) []byte {
+	t.Helper()
+
+	var body []byte
+	for _, batch := range batches {
+		body = binary.BigEndian.AppendUint32(body, uint32(len(batch)))
+		body = append(body, batch...)
+	}
+	data := append([]byte{blobCompressionNone}, binary.BigEndian.AppendUint32(nil, uint32(len(body)))...)
+	data = append(data, body...)
+	require.LessOrEqual(t, len(data), blobDataSize)
+
+	blob := make([]byte, len(gokzg4844.Blob{}))
+	for i := 0; len(data) > 0; i += blobFieldElementSize {
+		data = data[copy(blob[i+1:i+blobFieldElementSize], data):]
+	}
+	return blob
+}
+
+func Test_DecodeBlobBatches(t *testing.T) {
+	long := make([]byte, 100)
+	for i := range long {
+		long[i] = byte(i)
+	}
+
+	batches, err := DecodeBlobBatches([][]byte{encodeBlob(t, []byte{1, 2, 3}, long), encodeBlob(t), encodeBlob(t, []byte{})})
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{{1, 2, 3}, long, {}}, batches)
+
+	blob := encodeBlob(t, []byte{1})
+	blob[32] = 1
+	_, err = DecodeBlobBatches([][]byte{blob})
+	require.ErrorContains(t, err, "field element 1 has a non-zero high byte")
+
+	blob = encodeBlob(t, []byte{1})
+	blob[1] = 1
+	_, err = DecodeBlobBatches([][]byte{blob})
+	require.ErrorContains(t, err, "unsupported compression type 1")
+
+	blob = encodeBlob(t, []byte{1})
+	blob[9] = 2
+	_, err = DecodeBlobBatches([][]byte{blob})
+	require.ErrorContains(t, err, "batch of 2 bytes doesn't fit in the body")
+
+	_, err = DecodeBlobBatches([][]byte{blob[:64]})
+	require.ErrorContains(t, err, "blob of 64 bytes")
+}
+
+func Test_DecodeL1BlobBatchData(t *testing.T) {
+	smcAbi, err := abi.JSON(strings.NewReader(contracts.SequenceBatchesAbiBanana))
+	require.NoError(t, err)
+	coinbase := common.HexToAddress("0x5b06837a43bdc3dd9f114558daf4b26ed49842ed")
+	txData, err := smcAbi.Pack("sequenceBatches", []RollupBaseEtrogBatchData{{}, {}}, uint32(1), uint64(1000), [32]byte{}, coinbase)
+	require.NoError(t, err)
+
+	batches, cb, limitTimestamp, err := DecodeL1BlobBatchData(txData, [][]byte{encodeBlob(t, []byte{1}), encodeBlob(t, []byte{2, 3})})
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{{1}, {2, 3}}, batches)
+	require.Equal(t, coinbase, cb)
+	require.Equal(t, uint64(1000), limitTimestamp)
+
+	_, _, _, err = DecodeL1BlobBatchData(txData, [][]byte{encodeBlob(t, []byte{1})})
+	require.ErrorContains(t, err, "1 batches in the blobs for 2 sequences")
+
+	txData, err = smcAbi.Pack("sequenceBatches", []RollupBaseEtrogBatchData{{Transactions: []byte{1}}}, uint32(1), uint64(1000), [32]byte{}, coinbase)
+	require.NoError(t, err)
+	_, _, _, err = DecodeL1BlobBatchData(txData, [][]byte{encodeBlob(t, []byte{1})})
+	require.ErrorContains(t, err, "carries its transactions in both the call data and the blobs")
+}
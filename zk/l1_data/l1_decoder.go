@@ -11,7 +11,6 @@ import (
 	"github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/common/length"
 	"github.com/ledgerwatch/erigon/accounts/abi"
-	"github.com/ledgerwatch/erigon/crypto"
 	"github.com/ledgerwatch/erigon/zk/contracts"
 	"github.com/ledgerwatch/erigon/zk/da"
 	zktx "github.com/ledgerwatch/erigon/zk/tx"
@@ -37,7 +36,7 @@ func BuildSequencesForRollup(data []byte) ([]RollupBaseEtrogBatchData, error) {
 	return sequences, err
 }
 
-func BuildSequencesForValidium(data []byte, daUrl string) ([]RollupBaseEtrogBatchData, error) {
+func BuildSequencesForValidium(data []byte, daBackend da.DABackend) ([]RollupBaseEtrogBatchData, error) {
 	var sequences []RollupBaseEtrogBatchData
 	var validiumSequences []ValidiumBatchData
 	err := json.Unmarshal(data, &validiumSequences)
@@ -48,16 +47,12 @@ func BuildSequencesForValidium(data []byte, daUrl string) ([]RollupBaseEtrogBatc
 
 	for _, validiumSequence := range validiumSequences {
 		hash := common.BytesToHash(validiumSequence.TransactionsHash[:])
-		data, err := da.GetOffChainData(context.Background(), daUrl, hash)
+		// the backend checks the data against the hash
+		data, err := daBackend.GetOffChainData(context.Background(), hash)
 		if err != nil {
 			return nil, err
 		}
 
-		actualTransactionsHash := crypto.Keccak256Hash(data)
-		if actualTransactionsHash != hash {
-			return nil, fmt.Errorf("unable to fetch off chain data for hash %s, got %s intead", hash.String(), actualTransactionsHash.String())
-		}
-
 		sequences = append(sequences, RollupBaseEtrogBatchData{
 			Transactions:         data,
 			ForcedGlobalExitRoot: validiumSequence.ForcedGlobalExitRoot,
@@ -69,26 +64,36 @@ func BuildSequencesForValidium(data []byte, daUrl string) ([]RollupBaseEtrogBatc
 	return sequences, nil
 }
 
-func DecodeL1BatchData(txData []byte, daUrl string) ([][]byte, common.Address, uint64, error) {
+// unpackSequenceBatches unpacks the inputs of a sequence batches call, returning them with the id of its method
+func unpackSequenceBatches(txData []byte) (string, []interface{}, error) {
 	// we need to know which version of the ABI to use here so lets find it
 	idAsString := fmt.Sprintf("%x", txData[:4])
 	abiMapped, found := contracts.SequenceBatchesMapping[idAsString]
 	if !found {
-		return nil, common.Address{}, 0, fmt.Errorf("unknown l1 call data")
+		return "", nil, fmt.Errorf("unknown l1 call data")
 	}
 
 	smcAbi, err := abi.JSON(strings.NewReader(abiMapped))
 	if err != nil {
-		return nil, common.Address{}, 0, err
+		return "", nil, err
 	}
 
 	method, err := smcAbi.MethodById(txData[:4])
 	if err != nil {
-		return nil, common.Address{}, 0, err
+		return "", nil, err
 	}
 
 	// Unpack method inputs
 	data, err := method.Inputs.Unpack(txData[4:])
+	if err != nil {
+		return "", nil, err
+	}
+
+	return idAsString, data, nil
+}
+
+func DecodeL1BatchData(txData []byte, daBackend da.DABackend) ([][]byte, common.Address, uint64, error) {
+	idAsString, data, err := unpackSequenceBatches(txData)
 	if err != nil {
 		return nil, common.Address{}, 0, err
 	}
@@ -117,7 +122,7 @@ func DecodeL1BatchData(txData []byte, daUrl string) ([][]byte, common.Address, u
 		}
 		limitTimstamp = ts
 	case contracts.SequenceBatchesValidiumElderBerry:
-		if daUrl == "" {
+		if daBackend == nil {
 			return nil, common.Address{}, 0, fmt.Errorf("data availability url is required for validium")
 		}
 		isValidium = true
@@ -143,7 +148,7 @@ func DecodeL1BatchData(txData []byte, daUrl string) ([][]byte, common.Address, u
 		}
 		limitTimstamp = ts
 	case contracts.SequenceBatchesValidiumBanana:
-		if daUrl == "" {
+		if daBackend == nil {
 			return nil, common.Address{}, 0, fmt.Errorf("data availability url is required for validium")
 		}
 		isValidium = true
@@ -169,7 +174,7 @@ func DecodeL1BatchData(txData []byte, daUrl string) ([][]byte, common.Address, u
 	}
 
 	if isValidium {
-		sequences, err = BuildSequencesForValidium(bytedata, daUrl)
+		sequences, err = BuildSequencesForValidium(bytedata, daBackend)
 	} else {
 		sequences, err = BuildSequencesForRollup(bytedata)
 	}
@@ -186,6 +191,43 @@ func DecodeL1BatchData(txData []byte, daUrl string) ([][]byte, common.Address, u
 	return batchL2Datas, coinbase, limitTimstamp, err
 }
 
+// ValidiumDataAvailabilityMessage returns the data availability message of a validium sequence, which the data
+// availability committee has to have signed the returned hash in, so that it can be checked.  It returns false for a
+// sequence that is not a validium one
+func ValidiumDataAvailabilityMessage(txData []byte) (common.Hash, []byte, bool, error) {
+	idAsString, data, err := unpackSequenceBatches(txData)
+	if err != nil {
+		return common.Hash{}, nil, false, err
+	}
+	if idAsString != contracts.SequenceBatchesValidiumElderBerry && idAsString != contracts.SequenceBatchesValidiumBanana {
+		return common.Hash{}, nil, false, nil
+	}
+
+	bytedata, err := json.Marshal(data[0])
+	if err != nil {
+		return common.Hash{}, nil, false, err
+	}
+	var validiumSequences []ValidiumBatchData
+	if err := json.Unmarshal(bytedata, &validiumSequences); err != nil {
+		return common.Hash{}, nil, false, err
+	}
+	// the committee doesn't sign forced batches, their data is on the L1
+	var transactionsHashes []common.Hash
+	for _, validiumSequence := range validiumSequences {
+		if validiumSequence.ForcedTimestamp == 0 {
+			transactionsHashes = append(transactionsHashes, validiumSequence.TransactionsHash)
+		}
+	}
+
+	// the message is the last input of both versions of the validium sequence batches
+	message, ok := data[len(data)-1].([]byte)
+	if !ok {
+		return common.Hash{}, nil, false, fmt.Errorf("expected the last input of the l1 call data to be the data availability message")
+	}
+
+	return da.SignedHash(transactionsHashes), message, true, nil
+}
+
 type DecodedL1Data struct {
 	DecodedData     []zktx.DecodedBatchL2Data
 	Coinbase        common.Address
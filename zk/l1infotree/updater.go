@@ -14,6 +14,7 @@ import (
 	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/erigon/zk/contracts"
 	"github.com/ledgerwatch/erigon/zk/hermez_db"
+	"github.com/ledgerwatch/erigon/zk/syncer"
 	zkTypes "github.com/ledgerwatch/erigon/zk/types"
 	"github.com/ledgerwatch/log/v3"
 )
@@ -29,6 +30,8 @@ type Syncer interface {
 	StopQueryBlocks()
 	ConsumeQueryBlocks()
 	WaitQueryBlocksToFinish()
+	GetLastCheckedL1Header() *types.Header
+	FindL1ForkPoint(hashes map[uint64]common.Hash) (uint64, bool, error)
 }
 
 type Updater struct {
@@ -72,6 +75,15 @@ func (u *Updater) WarmUp(tx kv.RwTx) (err error) {
 		progress = u.cfg.L1FirstBlock - 1
 	}
 
+	// the updates from L1 blocks the L1 has since reorganised past are unwound, and fetched again from the fork point
+	forkBlock, reorged, err := u.unwindL1Reorg(tx, hermezDb)
+	if err != nil {
+		return err
+	}
+	if reorged && forkBlock < progress {
+		progress = forkBlock
+	}
+
 	u.progress = progress
 
 	latestUpdate, err := hermezDb.GetLatestL1InfoTreeUpdate()
@@ -117,6 +129,10 @@ LOOP:
 		}
 	}
 
+	if err = syncer.RecordL1BlockHashes(hermezDb, hermez_db.L1_INFO_TREE_BLOCK_HASHES, allLogs, u.syncer.GetLastCheckedL1Header()); err != nil {
+		return nil, fmt.Errorf("RecordL1BlockHashes: %w", err)
+	}
+
 	// sort the logs by block number - it is important that we process them in order to get the index correct
 	sort.Slice(allLogs, func(i, j int) bool {
 		l1 := allLogs[i]
@@ -224,6 +240,50 @@ LOOP:
 	return allLogs, nil
 }
 
+// unwindL1Reorg checks the L1 blocks the updates were taken from against the L1.  When the L1 has reorganised past
+// them, it stops the syncer and deletes the updates, leaves and roots of the tree made after the fork point
+func (u *Updater) unwindL1Reorg(tx kv.RwTx, hermezDb *hermez_db.HermezDb) (uint64, bool, error) {
+	hashes, err := hermezDb.GetL1BlockHashes(hermez_db.L1_INFO_TREE_BLOCK_HASHES)
+	if err != nil {
+		return 0, false, fmt.Errorf("GetL1BlockHashes: %w", err)
+	}
+	forkBlock, reorged, err := u.syncer.FindL1ForkPoint(hashes)
+	if err != nil {
+		return 0, false, fmt.Errorf("FindL1ForkPoint: %w", err)
+	}
+	if !reorged {
+		return 0, false, nil
+	}
+
+	log.Warn("L1 reorg found, unwinding the L1 info tree", "forkBlock", forkBlock)
+
+	// the logs being fetched may be from either side of the fork
+	if u.syncer.IsSyncStarted() {
+		u.syncer.StopQueryBlocks()
+		u.syncer.ConsumeQueryBlocks()
+		u.syncer.WaitQueryBlocksToFinish()
+	}
+
+	if err = hermezDb.DeleteL1InfoTreeUpdatesFromL1Block(forkBlock + 1); err != nil {
+		return 0, false, fmt.Errorf("DeleteL1InfoTreeUpdatesFromL1Block: %w", err)
+	}
+	if err = hermezDb.TruncateL1BlockHashes(hermez_db.L1_INFO_TREE_BLOCK_HASHES, forkBlock+1); err != nil {
+		return 0, false, fmt.Errorf("TruncateL1BlockHashes: %w", err)
+	}
+
+	progress, err := stages.GetStageProgress(tx, stages.L1InfoTree)
+	if err != nil {
+		return 0, false, fmt.Errorf("GetStageProgress: %w", err)
+	}
+	if forkBlock < progress {
+		if err = stages.SaveStageProgress(tx, stages.L1InfoTree, forkBlock); err != nil {
+			return 0, false, fmt.Errorf("SaveStageProgress: %w", err)
+		}
+	}
+
+	return forkBlock, true, nil
+}
+
 func chunkLogs(slice []types.Log, chunkSize int) [][]types.Log {
 	var chunks [][]types.Log
 	for i := 0; i < len(slice); i += chunkSize {
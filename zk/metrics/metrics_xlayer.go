@@ -14,6 +14,12 @@ const (
 	BatchTimeOut         BatchFinalizeType = "EmptyBatchTimeOut"
 	BatchCounterOverflow BatchFinalizeType = "BatchCounterOverflow"
 	BatchLimboRecovery   BatchFinalizeType = "LimboRecovery"
+	BatchGasOverflow     BatchFinalizeType = "BatchGasOverflow"
+	BatchDataOverflow    BatchFinalizeType = "BatchDataOverflow"
+	BatchL1Recovery      BatchFinalizeType = "L1Recovery"
+	BatchForced          BatchFinalizeType = "ForcedBatch"
+	BatchResequence      BatchFinalizeType = "Resequence"
+	BatchDrained         BatchFinalizeType = "Drained"
 )
 
 var (
@@ -24,6 +30,7 @@ var (
 	SeqTxCountName       = SeqPrefix + "tx_count"
 	SeqZKOverflowBlockCounterName   = SeqPrefix + "zk_overflow_block_count"
 	SeqBlockGasUsedName  = SeqPrefix + "block_gas_used"
+	SeqBlockSealTimeName = SeqPrefix + "block_seal_time"
 
 	RpcPrefix              = "rpc_"
 	RpcDynamicGasPriceName = RpcPrefix + "dynamic_gas_price"
@@ -37,6 +44,7 @@ func Init() {
 	prometheus.MustRegister(SeqTxCount)
 	prometheus.MustRegister(SeqZKOverflowBlockCounter)
 	prometheus.MustRegister(SeqBlockGasUsed)
+	prometheus.MustRegister(SeqBlockSealTime)
 	prometheus.MustRegister(RpcDynamicGasPrice)
 	prometheus.MustRegister(RpcInnerTxExecuted)
 }
@@ -116,3 +124,10 @@ var SeqBlockGasUsed = prometheus.NewGauge(
 		Help: "[SEQUENCER] gas used per block",
 	},
 )
+
+var SeqBlockSealTime = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: SeqBlockSealTimeName,
+		Help: "[SEQUENCER] block seal time in second chosen for the current block",
+	},
+)
@@ -4,22 +4,45 @@ import "github.com/ledgerwatch/erigon-lib/common"
 
 // Batch structure
 type Batch struct {
-	Number              ArgUint64      `json:"number"`
-	ForcedBatchNumber   *ArgUint64     `json:"forcedBatchNumber,omitempty"`
-	Coinbase            common.Address `json:"coinbase"`
-	StateRoot           common.Hash    `json:"stateRoot"`
-	GlobalExitRoot      common.Hash    `json:"globalExitRoot"`
-	MainnetExitRoot     common.Hash    `json:"mainnetExitRoot"`
-	RollupExitRoot      common.Hash    `json:"rollupExitRoot"`
-	LocalExitRoot       common.Hash    `json:"localExitRoot"`
-	AccInputHash        common.Hash    `json:"accInputHash"`
-	Timestamp           ArgUint64      `json:"timestamp"`
-	SendSequencesTxHash *common.Hash   `json:"sendSequencesTxHash"`
-	VerifyBatchTxHash   *common.Hash   `json:"verifyBatchTxHash"`
-	Closed              bool           `json:"closed"`
-	Blocks              []interface{}  `json:"blocks"`
-	Transactions        []interface{}  `json:"transactions"`
-	BatchL2Data         ArgBytes       `json:"batchL2Data"`
+	Number              ArgUint64       `json:"number"`
+	ForcedBatchNumber   *ArgUint64      `json:"forcedBatchNumber,omitempty"`
+	Coinbase            common.Address  `json:"coinbase"`
+	StateRoot           common.Hash     `json:"stateRoot"`
+	GlobalExitRoot      common.Hash     `json:"globalExitRoot"`
+	MainnetExitRoot     common.Hash     `json:"mainnetExitRoot"`
+	RollupExitRoot      common.Hash     `json:"rollupExitRoot"`
+	LocalExitRoot       common.Hash     `json:"localExitRoot"`
+	AccInputHash        common.Hash     `json:"accInputHash"`
+	Timestamp           ArgUint64       `json:"timestamp"`
+	SendSequencesTxHash *common.Hash    `json:"sendSequencesTxHash"`
+	VerifyBatchTxHash   *common.Hash    `json:"verifyBatchTxHash"`
+	Closed              bool            `json:"closed"`
+	Blocks              []interface{}   `json:"blocks"`
+	Transactions        []interface{}   `json:"transactions"`
+	BatchL2Data         ArgBytes        `json:"batchL2Data"`
+	CloseInfo           *BatchCloseInfo `json:"closeInfo,omitempty"`
+}
+
+// BatchCloseInfo structure
+type BatchCloseInfo struct {
+	BatchNumber      ArgUint64 `json:"batchNumber"`
+	Reason           string    `json:"reason"`
+	OverflowCounters []string  `json:"overflowCounters,omitempty"`
+	BlockCount       ArgUint64 `json:"blockCount"`
+	TransactionCount ArgUint64 `json:"transactionCount"`
+}
+
+// ForcedBatch structure
+type ForcedBatch struct {
+	ForcedBatchNumber ArgUint64      `json:"forcedBatchNumber"`
+	L1BlockNumber     ArgUint64      `json:"l1BlockNumber"`
+	L1TxHash          common.Hash    `json:"l1TxHash"`
+	ForcedAt          ArgUint64      `json:"forcedAt"`
+	GlobalExitRoot    common.Hash    `json:"globalExitRoot"`
+	ForcedBlockHashL1 common.Hash    `json:"forcedBlockHashL1"`
+	Sequencer         common.Address `json:"sequencer"`
+	BatchL2Data       ArgBytes       `json:"batchL2Data"`
+	BatchNumber       *ArgUint64     `json:"batchNumber"`
 }
 
 type BatchDataSlim struct {
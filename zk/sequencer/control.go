@@ -0,0 +1,184 @@
+package sequencer
+
+import (
+	"fmt"
+	"sync"
+)
+
+// HaltState describes whether the sequencer is producing blocks
+type HaltState string
+
+const (
+	HaltStateRunning HaltState = "running"
+	// a pause was requested, the sequencer stops once the current block is done
+	HaltStatePausing HaltState = "pausing"
+	HaltStatePaused  HaltState = "paused"
+	// a drain was requested, the sequencer closes the current batch and stops
+	HaltStateDraining HaltState = "draining"
+	HaltStateDrained  HaltState = "drained"
+	// halted on the configured halt batch number, only a restart with a new config resumes it
+	HaltStateHalted HaltState = "halted"
+)
+
+type ControlStatus struct {
+	State HaltState `json:"state"`
+	// the batch and block the sequencer stopped at, zero until it has stopped
+	BatchNumber uint64 `json:"batchNumber"`
+	BlockNumber uint64 `json:"blockNumber"`
+}
+
+// Control lets operators pause, resume and drain the sequencer at runtime.  Requests are only ever acted on at block
+// boundaries by the sequencing loop which reports back once it has stopped.
+//
+// A nil Control never asks the sequencer to stop.
+type Control struct {
+	mu          sync.Mutex
+	state       HaltState
+	batchNumber uint64
+	blockNumber uint64
+	// closed and replaced on every change of state
+	changed chan struct{}
+}
+
+func NewControl() *Control {
+	return &Control{
+		state:   HaltStateRunning,
+		changed: make(chan struct{}),
+	}
+}
+
+// Pause asks the sequencer to stop after the current block, the open batch is kept open
+func (c *Control) Pause() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.state {
+	case HaltStateRunning:
+		c.setStateLocked(HaltStatePausing)
+	case HaltStatePausing, HaltStatePaused:
+	default:
+		return fmt.Errorf("cannot pause the sequencer, it is %s", c.state)
+	}
+
+	return nil
+}
+
+// Drain asks the sequencer to close the current batch cleanly and stop
+func (c *Control) Drain() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.state {
+	case HaltStateRunning, HaltStatePausing, HaltStatePaused:
+		c.setStateLocked(HaltStateDraining)
+	case HaltStateDraining, HaltStateDrained:
+	default:
+		return fmt.Errorf("cannot drain the sequencer, it is %s", c.state)
+	}
+
+	return nil
+}
+
+// Resume lets a paused or drained sequencer carry on producing blocks
+func (c *Control) Resume() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.state {
+	case HaltStateRunning:
+	case HaltStateHalted:
+		return fmt.Errorf("cannot resume the sequencer, it is halted on batch %d by config", c.batchNumber)
+	default:
+		c.batchNumber = 0
+		c.blockNumber = 0
+		c.setStateLocked(HaltStateRunning)
+	}
+
+	return nil
+}
+
+func (c *Control) Status() ControlStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return ControlStatus{
+		State:       c.state,
+		BatchNumber: c.batchNumber,
+		BlockNumber: c.blockNumber,
+	}
+}
+
+// PauseRequested is true while the sequencer is asked to stay paused
+func (c *Control) PauseRequested() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.state == HaltStatePausing || c.state == HaltStatePaused
+}
+
+// DrainRequested is true while the sequencer is asked to close its batch and stay stopped
+func (c *Control) DrainRequested() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.state == HaltStateDraining || c.state == HaltStateDrained
+}
+
+// Changed returns a channel that is closed on the next change of state
+func (c *Control) Changed() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.changed
+}
+
+// SetPaused is called by the sequencer once it stopped for a pause request
+func (c *Control) SetPaused(batchNumber, blockNumber uint64) {
+	c.setStopped(HaltStatePausing, HaltStatePaused, batchNumber, blockNumber)
+}
+
+// SetDrained is called by the sequencer once it closed its batch for a drain request
+func (c *Control) SetDrained(batchNumber, blockNumber uint64) {
+	c.setStopped(HaltStateDraining, HaltStateDrained, batchNumber, blockNumber)
+}
+
+// SetHalted is called by the sequencer when it halts on the configured halt batch number
+func (c *Control) SetHalted(batchNumber, blockNumber uint64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.batchNumber = batchNumber
+	c.blockNumber = blockNumber
+	c.setStateLocked(HaltStateHalted)
+}
+
+func (c *Control) setStopped(requested, stopped HaltState, batchNumber, blockNumber uint64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// the request could have been changed whilst the sequencer was finishing its block
+	if c.state != requested {
+		return
+	}
+	c.batchNumber = batchNumber
+	c.blockNumber = blockNumber
+	c.setStateLocked(stopped)
+}
+
+func (c *Control) setStateLocked(state HaltState) {
+	c.state = state
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
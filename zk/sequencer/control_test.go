@@ -0,0 +1,64 @@
+package sequencer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestControlPauseResume(t *testing.T) {
+	c := NewControl()
+	require.False(t, c.PauseRequested())
+
+	changed := c.Changed()
+	require.NoError(t, c.Pause())
+	require.True(t, c.PauseRequested())
+	require.Equal(t, HaltStatePausing, c.Status().State)
+	select {
+	case <-changed:
+	default:
+		t.Fatal("pausing has to notify the sequencer")
+	}
+
+	c.SetPaused(3, 10)
+	require.Equal(t, ControlStatus{State: HaltStatePaused, BatchNumber: 3, BlockNumber: 10}, c.Status())
+
+	require.NoError(t, c.Resume())
+	require.False(t, c.PauseRequested())
+	require.Equal(t, ControlStatus{State: HaltStateRunning}, c.Status())
+}
+
+func TestControlDrain(t *testing.T) {
+	c := NewControl()
+	require.NoError(t, c.Pause())
+
+	// a drain overrides a pause, the batch gets closed
+	require.NoError(t, c.Drain())
+	require.False(t, c.PauseRequested())
+	require.True(t, c.DrainRequested())
+
+	// reported by the sequencer for the pause it was finishing, no longer of interest
+	c.SetPaused(3, 10)
+	require.Equal(t, HaltStateDraining, c.Status().State)
+
+	c.SetDrained(3, 11)
+	require.Equal(t, ControlStatus{State: HaltStateDrained, BatchNumber: 3, BlockNumber: 11}, c.Status())
+	require.Error(t, c.Pause())
+
+	require.NoError(t, c.Resume())
+	require.False(t, c.DrainRequested())
+}
+
+func TestControlHalted(t *testing.T) {
+	c := NewControl()
+	c.SetHalted(5, 20)
+
+	require.Error(t, c.Resume())
+	require.Error(t, c.Pause())
+	require.Error(t, c.Drain())
+	require.Equal(t, ControlStatus{State: HaltStateHalted, BatchNumber: 5, BlockNumber: 20}, c.Status())
+
+	var nilControl *Control
+	require.False(t, nilControl.PauseRequested())
+	require.False(t, nilControl.DrainRequested())
+}
@@ -0,0 +1,248 @@
+package sequencer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/ledgerwatch/log/v3"
+)
+
+// LeaseLock is the backend the sequencer lease is held on, it only ever has one holder
+type LeaseLock interface {
+	// TryAcquire takes or renews the lease for the holder for ttl, false if somebody else holds it
+	TryAcquire(holder string, ttl time.Duration) (bool, error)
+	// Release gives up the lease if the holder holds it
+	Release(holder string) error
+}
+
+// NewLeaseLock creates the lease lock for either a file:// or a tcp:// url
+func NewLeaseLock(url string) (LeaseLock, error) {
+	switch {
+	case strings.HasPrefix(url, "file://"):
+		path := strings.TrimPrefix(url, "file://")
+		return &fileLeaseLock{path: path, guard: flock.New(path + ".flock")}, nil
+	case strings.HasPrefix(url, "tcp://"):
+		return &tcpLeaseLock{address: strings.TrimPrefix(url, "tcp://")}, nil
+	default:
+		return nil, fmt.Errorf("unsupported sequencer lease lock %q, expected file:// or tcp://", url)
+	}
+}
+
+// fileLeaseLock keeps the holder and the expiry of the lease in a file, a sidecar flock guards its updates.  It works
+// for nodes sharing a filesystem with working flocks.
+type fileLeaseLock struct {
+	path  string
+	guard *flock.Flock
+}
+
+func (l *fileLeaseLock) TryAcquire(holder string, ttl time.Duration) (bool, error) {
+	if err := l.guard.Lock(); err != nil {
+		return false, err
+	}
+	defer l.guard.Unlock()
+
+	current, expiry, err := l.read()
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	if current != "" && current != holder && now.Before(expiry) {
+		return false, nil
+	}
+
+	return true, l.write(holder, now.Add(ttl))
+}
+
+func (l *fileLeaseLock) Release(holder string) error {
+	if err := l.guard.Lock(); err != nil {
+		return err
+	}
+	defer l.guard.Unlock()
+
+	current, _, err := l.read()
+	if err != nil {
+		return err
+	}
+	if current != holder {
+		return nil
+	}
+
+	return os.Remove(l.path)
+}
+
+func (l *fileLeaseLock) read() (string, time.Time, error) {
+	data, err := os.ReadFile(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", time.Time{}, nil
+	}
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	holder, expiry, ok := strings.Cut(strings.TrimSpace(string(data)), "\n")
+	if !ok {
+		return "", time.Time{}, fmt.Errorf("malformed sequencer lease file %s", l.path)
+	}
+	expiryNano, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("malformed sequencer lease file %s: %w", l.path, err)
+	}
+
+	return holder, time.Unix(0, expiryNano), nil
+}
+
+func (l *fileLeaseLock) write(holder string, expiry time.Time) error {
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(fmt.Sprintf("%s\n%d\n", holder, expiry.UnixNano())), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}
+
+// tcpLeaseLock is held by listening on a local address.  The lease is gone the moment its holder exits, so the ttl
+// plays no part, but it only works for nodes running on the same host.
+type tcpLeaseLock struct {
+	address string
+
+	mu       sync.Mutex
+	listener net.Listener
+}
+
+func (l *tcpLeaseLock) TryAcquire(holder string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.listener != nil {
+		return true, nil
+	}
+
+	listener, err := net.Listen("tcp", l.address)
+	if errors.Is(err, syscall.EADDRINUSE) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	l.listener = listener
+
+	return true, nil
+}
+
+func (l *tcpLeaseLock) Release(holder string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.listener == nil {
+		return nil
+	}
+	err := l.listener.Close()
+	l.listener = nil
+	return err
+}
+
+// Lease keeps trying to take or renew the sequencer lease in the background.  A sequencer only produces blocks whilst
+// it holds the lease, a standby takes over as sequencer once it gets it.
+//
+// A nil Lease is always held, there is no other sequencer to hand over to.
+type Lease struct {
+	lock   LeaseLock
+	holder string
+	ttl    time.Duration
+
+	mu sync.Mutex
+	// the lease is only counted as held until the expiry of the last successful renewal, a node that fails to renew
+	// stops sequencing before anyone else can take over
+	heldUntil    time.Time
+	acquired     chan struct{}
+	acquiredOnce sync.Once
+}
+
+func NewLease(lock LeaseLock, holder string, ttl time.Duration) *Lease {
+	return &Lease{
+		lock:     lock,
+		holder:   holder,
+		ttl:      ttl,
+		acquired: make(chan struct{}),
+	}
+}
+
+// Run renews the lease three times per ttl until the context is done
+func (l *Lease) Run(ctx context.Context) {
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		l.renew()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (l *Lease) renew() {
+	start := time.Now()
+	ok, err := l.lock.TryAcquire(l.holder, l.ttl)
+	if err != nil {
+		log.Warn("[sequencer] Failed to renew the sequencer lease", "err", err)
+		return
+	}
+
+	wasHeld := l.Held()
+
+	l.mu.Lock()
+	if ok {
+		l.heldUntil = start.Add(l.ttl)
+	} else {
+		l.heldUntil = time.Time{}
+	}
+	l.mu.Unlock()
+
+	if ok && !wasHeld {
+		log.Info("[sequencer] Sequencer lease acquired", "holder", l.holder)
+		l.acquiredOnce.Do(func() { close(l.acquired) })
+	}
+	if !ok && wasHeld {
+		log.Error("[sequencer] Sequencer lease lost to another node", "holder", l.holder)
+	}
+}
+
+// Held is true whilst the lease is held
+func (l *Lease) Held() bool {
+	if l == nil {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return time.Now().Before(l.heldUntil)
+}
+
+// Acquired returns a channel closed once the lease has been acquired for the first time
+func (l *Lease) Acquired() <-chan struct{} {
+	return l.acquired
+}
+
+// Release gives up the lease so that a standby can take over straight away
+func (l *Lease) Release() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	l.heldUntil = time.Time{}
+	l.mu.Unlock()
+
+	return l.lock.Release(l.holder)
+}
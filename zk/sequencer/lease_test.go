@@ -0,0 +1,101 @@
+package sequencer
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileLeaseLock(t *testing.T) {
+	lock, err := NewLeaseLock("file://" + filepath.Join(t.TempDir(), "sequencer.lease"))
+	require.NoError(t, err)
+
+	ok, err := lock.TryAcquire("primary", 50*time.Millisecond)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// held by the primary until it expires
+	ok, err = lock.TryAcquire("standby", time.Second)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// renewals by the holder always succeed
+	ok, err = lock.TryAcquire("primary", 50*time.Millisecond)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	time.Sleep(60 * time.Millisecond)
+	ok, err = lock.TryAcquire("standby", time.Second)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// the primary has lost it now, releasing it has no effect
+	require.NoError(t, lock.Release("primary"))
+	ok, err = lock.TryAcquire("primary", time.Second)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, lock.Release("standby"))
+	ok, err = lock.TryAcquire("primary", time.Second)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestTcpLeaseLock(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	primary, err := NewLeaseLock("tcp://" + address)
+	require.NoError(t, err)
+	standby, err := NewLeaseLock("tcp://" + address)
+	require.NoError(t, err)
+
+	ok, err := primary.TryAcquire("primary", time.Second)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = standby.TryAcquire("standby", time.Second)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, primary.Release("primary"))
+	ok, err = standby.TryAcquire("standby", time.Second)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, standby.Release("standby"))
+}
+
+func TestLease(t *testing.T) {
+	lock, err := NewLeaseLock("file://" + filepath.Join(t.TempDir(), "sequencer.lease"))
+	require.NoError(t, err)
+
+	primary := NewLease(lock, "primary", time.Minute)
+	standby := NewLease(lock, "standby", time.Minute)
+
+	primary.renew()
+	require.True(t, primary.Held())
+	select {
+	case <-primary.Acquired():
+	default:
+		t.Fatal("acquiring the lease has to be signalled")
+	}
+
+	standby.renew()
+	require.False(t, standby.Held())
+
+	require.NoError(t, primary.Release())
+	require.False(t, primary.Held())
+	standby.renew()
+	require.True(t, standby.Held())
+
+	_, err = NewLeaseLock("etcd://127.0.0.1:2379")
+	require.Error(t, err)
+
+	var nilLease *Lease
+	require.True(t, nilLease.Held())
+}
@@ -0,0 +1,178 @@
+package sequencer
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/hexutil"
+	"github.com/ledgerwatch/erigon/crypto"
+)
+
+// Preconfirmation is the signed promise of the sequencer that a transaction was executed in its open block at the given
+// index.  It is soft, the block is yet to be sealed and an unwind of the sequencer can still drop it.
+//
+// There is no intermediate state root, the sequencer only computes the root of the SMT once the block is finished.
+type Preconfirmation struct {
+	ChainId           hexutil.Uint64 `json:"chainId"`
+	TxHash            common.Hash    `json:"txHash"`
+	BatchNumber       hexutil.Uint64 `json:"batchNumber"`
+	BlockNumber       hexutil.Uint64 `json:"blockNumber"`
+	Index             hexutil.Uint64 `json:"index"`
+	Status            hexutil.Uint64 `json:"status"`
+	CumulativeGasUsed hexutil.Uint64 `json:"cumulativeGasUsed"`
+	Signature         hexutil.Bytes  `json:"signature"`
+}
+
+// SigningHash is the keccak of all the fields but the signature
+func (p *Preconfirmation) SigningHash() common.Hash {
+	buf := make([]byte, 0, 8+32+8*5)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(p.ChainId))
+	buf = append(buf, p.TxHash.Bytes()...)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(p.BatchNumber))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(p.BlockNumber))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(p.Index))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(p.Status))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(p.CumulativeGasUsed))
+	return crypto.Keccak256Hash(buf)
+}
+
+// Signer recovers the address of the sequencer that signed the pre-confirmation
+func (p *Preconfirmation) Signer() (common.Address, error) {
+	hash := p.SigningHash()
+	pub, err := crypto.SigToPub(hash.Bytes(), p.Signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// Preconfirmer signs pre-confirmations for the transactions the sequencer adds to its open block, keeps the most recent
+// ones for lookups and hands them to subscribers.
+//
+// A nil Preconfirmer has pre-confirmations disabled.
+type Preconfirmer struct {
+	key     *ecdsa.PrivateKey
+	chainId uint64
+	limit   int
+
+	mu     sync.RWMutex
+	byHash map[common.Hash]*Preconfirmation
+	// in the order they were issued, the oldest are evicted once the limit is reached
+	order  []common.Hash
+	subs   map[uint64]chan *Preconfirmation
+	nextId uint64
+}
+
+func NewPreconfirmer(key *ecdsa.PrivateKey, chainId uint64, limit int) *Preconfirmer {
+	return &Preconfirmer{
+		key:     key,
+		chainId: chainId,
+		limit:   limit,
+		byHash:  make(map[common.Hash]*Preconfirmation),
+		subs:    make(map[uint64]chan *Preconfirmation),
+	}
+}
+
+// Address of the key the pre-confirmations are signed with
+func (p *Preconfirmer) Address() common.Address {
+	return crypto.PubkeyToAddress(p.key.PublicKey)
+}
+
+// Preconfirm is called by the sequencer right after it added a transaction to its open block
+func (p *Preconfirmer) Preconfirm(txHash common.Hash, batchNumber, blockNumber, index, status, cumulativeGasUsed uint64) error {
+	if p == nil {
+		return nil
+	}
+
+	preconf := &Preconfirmation{
+		ChainId:           hexutil.Uint64(p.chainId),
+		TxHash:            txHash,
+		BatchNumber:       hexutil.Uint64(batchNumber),
+		BlockNumber:       hexutil.Uint64(blockNumber),
+		Index:             hexutil.Uint64(index),
+		Status:            hexutil.Uint64(status),
+		CumulativeGasUsed: hexutil.Uint64(cumulativeGasUsed),
+	}
+	hash := preconf.SigningHash()
+	sig, err := crypto.Sign(hash.Bytes(), p.key)
+	if err != nil {
+		return fmt.Errorf("failed to sign pre-confirmation for %s: %w", txHash, err)
+	}
+	preconf.Signature = sig
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.byHash[txHash]; !ok {
+		p.order = append(p.order, txHash)
+	}
+	p.byHash[txHash] = preconf
+	for len(p.order) > p.limit {
+		delete(p.byHash, p.order[0])
+		p.order = p.order[1:]
+	}
+
+	for _, ch := range p.subs {
+		// a slow subscriber misses pre-confirmations rather than holding up the sequencer
+		select {
+		case ch <- preconf:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Get returns the pre-confirmation of a transaction, nil if there is none
+func (p *Preconfirmer) Get(txHash common.Hash) *Preconfirmation {
+	if p == nil {
+		return nil
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.byHash[txHash]
+}
+
+// DropFromBlock forgets the pre-confirmations of the given block onwards, called when the sequencer unwinds
+func (p *Preconfirmer) DropFromBlock(blockNumber uint64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	kept := p.order[:0]
+	for _, txHash := range p.order {
+		if uint64(p.byHash[txHash].BlockNumber) >= blockNumber {
+			delete(p.byHash, txHash)
+			continue
+		}
+		kept = append(kept, txHash)
+	}
+	p.order = kept
+}
+
+// Subscribe returns a channel receiving every new pre-confirmation and the function to unsubscribe with
+func (p *Preconfirmer) Subscribe(size int) (<-chan *Preconfirmation, func()) {
+	ch := make(chan *Preconfirmation, size)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	id := p.nextId
+	p.nextId++
+	p.subs[id] = ch
+
+	return ch, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if _, ok := p.subs[id]; ok {
+			delete(p.subs, id)
+			close(ch)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package sequencer
+
+import (
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreconfirmerSignsAndStores(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	p := NewPreconfirmer(key, 1101, 2)
+
+	ch, unsubscribe := p.Subscribe(4)
+	defer unsubscribe()
+
+	tx1, tx2, tx3 := common.HexToHash("0x1"), common.HexToHash("0x2"), common.HexToHash("0x3")
+	require.NoError(t, p.Preconfirm(tx1, 3, 10, 0, 1, 21000))
+
+	preconf := p.Get(tx1)
+	require.NotNil(t, preconf)
+	require.Equal(t, preconf, <-ch)
+	require.Equal(t, uint64(10), uint64(preconf.BlockNumber))
+
+	signer, err := preconf.Signer()
+	require.NoError(t, err)
+	require.Equal(t, p.Address(), signer)
+
+	// any change of the content breaks the signature
+	tampered := *preconf
+	tampered.Index = 1
+	signer, err = tampered.Signer()
+	require.NoError(t, err)
+	require.NotEqual(t, p.Address(), signer)
+
+	// the oldest are evicted past the limit
+	require.NoError(t, p.Preconfirm(tx2, 3, 10, 1, 1, 42000))
+	require.NoError(t, p.Preconfirm(tx3, 3, 11, 0, 0, 30000))
+	require.Nil(t, p.Get(tx1))
+	require.NotNil(t, p.Get(tx2))
+	require.NotNil(t, p.Get(tx3))
+
+	// unwinding the sequencer drops the pre-confirmations of the unwound blocks
+	p.DropFromBlock(11)
+	require.NotNil(t, p.Get(tx2))
+	require.Nil(t, p.Get(tx3))
+
+	var nilPreconfirmer *Preconfirmer
+	require.NoError(t, nilPreconfirmer.Preconfirm(tx1, 3, 10, 0, 1, 21000))
+	require.Nil(t, nilPreconfirmer.Get(tx1))
+}
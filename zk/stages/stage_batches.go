@@ -461,6 +461,9 @@ func UnwindBatchesStage(u *stagedsync.UnwindState, tx kv.RwTx, cfg BatchesCfg, c
 		if err := hermezDb.DeleteBatchGlobalExitRoots(fromBatch); err != nil {
 			return fmt.Errorf("DeleteBatchGlobalExitRoots: %w", err)
 		}
+		if err := hermezDb.DeleteBatchForcedBatches(fromBatch, toBatch); err != nil {
+			return fmt.Errorf("DeleteBatchForcedBatches: %w", err)
+		}
 	}
 
 	if highestVerifiedBatch >= fromBatch {
@@ -848,5 +851,19 @@ func getHighestDSL2Block(ctx context.Context, batchCfg BatchesCfg, latestFork ui
 
 func buildNewStreamClient(ctx context.Context, batchesCfg BatchesCfg, latestFork uint16) *client.StreamClient {
 	cfg := batchesCfg.zkCfg
-	return client.NewClient(ctx, cfg.L2DataStreamerUrl, cfg.DatastreamVersion, cfg.L2DataStreamerTimeout, latestFork)
+	c := client.NewClient(ctx, cfg.L2DataStreamerUrl, cfg.DatastreamVersion, cfg.L2DataStreamerTimeout, latestFork)
+	c.SetAuth(DatastreamAuth(cfg))
+	c.SetDecodeWorkers(cfg.L2DataStreamerDecodeWorkers)
+	return c
+}
+
+// DatastreamAuth is how the datastream client connects to the configured stream
+func DatastreamAuth(cfg *ethconfig.Zk) client.AuthConfig {
+	return client.AuthConfig{
+		TLS:      cfg.L2DataStreamerTLS,
+		CAFile:   cfg.L2DataStreamerTLSCA,
+		CertFile: cfg.L2DataStreamerTLSCert,
+		KeyFile:  cfg.L2DataStreamerTLSKey,
+		Token:    cfg.L2DataStreamerToken,
+	}
 }
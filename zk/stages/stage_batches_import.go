@@ -0,0 +1,174 @@
+package stages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/ledgerwatch/erigon-lib/chain"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/zk"
+	"github.com/ledgerwatch/erigon/zk/datastream/archive"
+	"github.com/ledgerwatch/erigon/zk/datastream/client"
+	"github.com/ledgerwatch/erigon/zk/datastream/types"
+	"github.com/ledgerwatch/erigon/zk/erigon_db"
+	"github.com/ledgerwatch/erigon/zk/hermez_db"
+)
+
+// archiveQueryClient answers the block lookups of the batches processor from the archive
+type archiveQueryClient struct {
+	*archive.Reader
+	progress atomic.Uint64
+}
+
+func (c *archiveQueryClient) GetProgressAtomic() *atomic.Uint64 {
+	return &c.progress
+}
+
+// ImportDatastreamArchive feeds the entries of the archive through the batches processor, just as the Batches stage
+// does with the ones streamed from the network, picking up after the Batches stage progress in the db
+func ImportDatastreamArchive(ctx context.Context, db kv.RwDB, reader *archive.Reader, chainConfig *chain.Config, miningConfig *params.MiningConfig) error {
+	logPrefix := "DatastreamImport"
+
+	tx, err := db.BeginRw(ctx)
+	if err != nil {
+		return fmt.Errorf("db.BeginRw: %w", err)
+	}
+	defer tx.Rollback()
+
+	eriDb := erigon_db.NewErigonDb(tx)
+	hermezDb := hermez_db.NewHermezDb(tx)
+
+	stageProgressBlockNo, err := stages.GetStageProgress(tx, stages.Batches)
+	if err != nil {
+		return fmt.Errorf("GetStageProgress: %w", err)
+	}
+	stageProgressBatchNo, err := hermezDb.GetBatchNoByL2Block(stageProgressBlockNo)
+	if err != nil && !errors.Is(err, hermez_db.ErrorNotStored) {
+		return fmt.Errorf("GetBatchNoByL2Block: %w", err)
+	}
+
+	// the first batch with a block after the last processed one, the archive has to continue the chain in the db
+	batches := reader.Batches()
+	start := -1
+	for i, batch := range batches {
+		if batch.HasBlocks() && batch.LastBlock > stageProgressBlockNo {
+			start = i
+			break
+		}
+	}
+	if start == -1 {
+		log.Info(fmt.Sprintf("[%s] Nothing to import, the archive ends before the db", logPrefix), "dbBlock", stageProgressBlockNo)
+		return nil
+	}
+	if batches[start].FirstBlock > stageProgressBlockNo+1 {
+		return fmt.Errorf("the archive continues from block %d but the db is at block %d", batches[start].FirstBlock, stageProgressBlockNo)
+	}
+
+	lastProcessedBlockHash, err := eriDb.ReadCanonicalHash(stageProgressBlockNo)
+	if err != nil {
+		return fmt.Errorf("ReadCanonicalHash %d: %w", stageProgressBlockNo, err)
+	}
+	_, highestL1InfoTreeIndex, err := hermezDb.GetLatestBlockL1InfoTreeIndexProgress()
+	if err != nil {
+		return fmt.Errorf("GetLatestBlockL1InfoTreeIndexProgress: %w", err)
+	}
+
+	// an import never unwinds, a node whose chain differs from the archive has to be unwound beforehand
+	unwindFn := func(unwindBlock uint64) (uint64, error) {
+		return 0, fmt.Errorf("the archive conflicts with the db at block %d", unwindBlock)
+	}
+
+	queryClient := &archiveQueryClient{Reader: reader}
+	queryClient.progress.Store(stageProgressBlockNo)
+
+	progressChan, stopProgressPrinter := zk.ProgressPrinterWithoutTotal(fmt.Sprintf("[%s] Imported blocks progress", logPrefix))
+	defer stopProgressPrinter()
+
+	batchProcessor, err := NewBatchesProcessor(ctx, logPrefix, tx, hermezDb, eriDb, 0, 0, 0, 0, stageProgressBlockNo, stageProgressBatchNo, lastProcessedBlockHash, queryClient, progressChan, chainConfig, miningConfig, unwindFn)
+	if err != nil {
+		return fmt.Errorf("NewBatchesProcessor: %w", err)
+	}
+
+	saveProgress := func() error {
+		if err := saveStageProgress(tx, logPrefix, batchProcessor.HighestHashableL2BlockNo(), batchProcessor.HighestSeenBatchNumber(), batchProcessor.LastBlockHeight(), batchProcessor.LastForkId()); err != nil {
+			return fmt.Errorf("saveStageProgress: %w", err)
+		}
+		if err := hermezDb.WriteBlockL1InfoTreeIndexProgress(batchProcessor.LastBlockHeight(), highestL1InfoTreeIndex); err != nil {
+			return fmt.Errorf("WriteBlockL1InfoTreeIndexProgress: %w", err)
+		}
+		return nil
+	}
+
+	log.Info(fmt.Sprintf("[%s] Importing the archive", logPrefix), "fromBatch", batches[start].Batch, "toBatch", batches[len(batches)-1].Batch, "dbBlock", stageProgressBlockNo)
+
+	iterator := reader.Iterator(start)
+	var currentFork uint64
+	prevAmountBlocksWritten := uint64(0)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		entry, _, err := client.ReadParsedProto(iterator)
+		if err != nil {
+			return fmt.Errorf("ReadParsedProto: %w", err)
+		}
+
+		// the same handling the datastream client gives the entries before they reach the processor
+		switch entry := entry.(type) {
+		case *types.BookmarkProto:
+			continue
+		case *types.BatchStart:
+			currentFork = entry.ForkId
+		case *types.FullL2Block:
+			entry.ForkId = currentFork
+			// the stream would start at the bookmark of the last processed block, the ones before it are not fed
+			if entry.L2BlockNumber < stageProgressBlockNo {
+				continue
+			}
+		}
+
+		endLoop, err := batchProcessor.ProcessEntry(entry)
+		if err != nil {
+			return fmt.Errorf("ProcessEntry: %w", err)
+		}
+		if endLoop {
+			break
+		}
+
+		if batchProcessor.TotalBlocksWritten() != prevAmountBlocksWritten && batchProcessor.TotalBlocksWritten()%STAGE_PROGRESS_SAVE == 0 {
+			if err := saveProgress(); err != nil {
+				return err
+			}
+			if err := tx.Commit(); err != nil {
+				return fmt.Errorf("failed to commit tx, %w", err)
+			}
+			if tx, err = db.BeginRw(ctx); err != nil {
+				return fmt.Errorf("failed to open tx, %w", err)
+			}
+			defer tx.Rollback()
+			hermezDb.SetNewTx(tx)
+			eriDb.SetNewTx(tx)
+			batchProcessor.SetNewTx(tx)
+			prevAmountBlocksWritten = batchProcessor.TotalBlocksWritten()
+		}
+	}
+
+	if batchProcessor.LastBlockHeight() == stageProgressBlockNo {
+		return nil
+	}
+	if err := saveProgress(); err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("[%s] Finished importing the archive", logPrefix), "blocksWritten", batchProcessor.TotalBlocksWritten(), "lastBlock", batchProcessor.LastBlockHeight())
+
+	return tx.Commit()
+}
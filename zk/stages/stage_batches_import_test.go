@@ -0,0 +1,111 @@
+package stages
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/chain"
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/smt/pkg/db"
+	"github.com/ledgerwatch/erigon/zk/datastream/archive"
+	"github.com/ledgerwatch/erigon/zk/datastream/proto/github.com/0xPolygonHermez/zkevm-node/state/datastream"
+	"github.com/ledgerwatch/erigon/zk/datastream/types"
+	"github.com/ledgerwatch/erigon/zk/hermez_db"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestArchive writes batches with two blocks each, starting at the given ones
+func writeTestArchive(t *testing.T, path string, fromBatch, toBatch, fromBlock uint64) {
+	t.Helper()
+
+	w, err := archive.Create(path)
+	require.NoError(t, err)
+
+	entryNum := uint64(0)
+	write := func(entry interface {
+		Marshal() ([]byte, error)
+		Type() types.EntryType
+	}) {
+		data, err := entry.Marshal()
+		require.NoError(t, err)
+		require.NoError(t, w.WriteEntry(&types.FileEntry{EntryType: entry.Type(), EntryNum: entryNum, Data: data}))
+		entryNum++
+	}
+
+	block := fromBlock
+	for batch := fromBatch; batch <= toBatch; batch++ {
+		require.NoError(t, w.StartBatch(batch))
+		write(types.NewBookmarkProto(batch, datastream.BookmarkType_BOOKMARK_TYPE_BATCH))
+		write(&types.BatchStartProto{BatchStart: &datastream.BatchStart{Number: batch, ForkId: 9}})
+		for i := 0; i < 2; i++ {
+			write(types.NewBookmarkProto(block, datastream.BookmarkType_BOOKMARK_TYPE_L2_BLOCK))
+			write(&types.L2BlockProto{L2Block: &datastream.L2Block{
+				Number:      block,
+				BatchNumber: batch,
+				Timestamp:   block * 10,
+				Hash:        common.Hash{byte(block)}.Bytes(),
+				StateRoot:   common.Hash{byte(block)}.Bytes(),
+			}})
+			write(&types.L2BlockEndProto{Number: block})
+			block++
+		}
+		write(&types.BatchEndProto{BatchEnd: &datastream.BatchEnd{Number: batch, StateRoot: common.Hash{byte(block - 1)}.Bytes()}})
+	}
+
+	require.NoError(t, w.Close())
+}
+
+func TestImportDatastreamArchive(t *testing.T) {
+	ctx, db1 := context.Background(), memdb.NewTestDB(t)
+	tx := memdb.BeginRw(t, db1)
+	require.NoError(t, hermez_db.CreateHermezBuckets(tx))
+	require.NoError(t, db.CreateEriDbBuckets(tx))
+	require.NoError(t, hermez_db.NewHermezDb(tx).WriteBlockBatch(0, 0))
+	require.NoError(t, tx.Commit())
+
+	dir := t.TempDir()
+	importArchive := func(name string) error {
+		reader, err := archive.Open(filepath.Join(dir, name))
+		require.NoError(t, err)
+		defer reader.Close()
+		return ImportDatastreamArchive(ctx, db1, reader, &chain.Config{}, &params.MiningConfig{})
+	}
+	progress := func() (block, batch, forkId uint64) {
+		tx := memdb.BeginRw(t, db1)
+		defer tx.Rollback()
+		block, err := stages.GetStageProgress(tx, stages.Batches)
+		require.NoError(t, err)
+		batch, err = hermez_db.NewHermezDb(tx).GetBatchNoByL2Block(block)
+		require.NoError(t, err)
+		forkId, err = stages.GetStageProgress(tx, stages.ForkId)
+		require.NoError(t, err)
+		return block, batch, forkId
+	}
+
+	writeTestArchive(t, filepath.Join(dir, "first.dsar"), 1, 2, 1)
+	require.NoError(t, importArchive("first.dsar"))
+	block, batch, forkId := progress()
+	require.Equal(t, uint64(4), block)
+	require.Equal(t, uint64(2), batch)
+	require.Equal(t, uint64(9), forkId)
+
+	// importing again changes nothing
+	require.NoError(t, importArchive("first.dsar"))
+	block, _, _ = progress()
+	require.Equal(t, uint64(4), block)
+
+	// an archive overlapping the db continues from the last block in it
+	writeTestArchive(t, filepath.Join(dir, "overlap.dsar"), 2, 4, 3)
+	require.NoError(t, importArchive("overlap.dsar"))
+	block, batch, _ = progress()
+	require.Equal(t, uint64(8), block)
+	require.Equal(t, uint64(4), batch)
+
+	// but one leaving a gap is refused
+	writeTestArchive(t, filepath.Join(dir, "gap.dsar"), 6, 6, 11)
+	require.ErrorContains(t, importArchive("gap.dsar"), "continues from block 11 but the db is at block 8")
+}
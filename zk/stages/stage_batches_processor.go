@@ -53,6 +53,9 @@ type ProcessorHermezDb interface {
 	WriteInvalidBatch(batchNumber uint64) error
 	WriteBatchEnd(lastBlockHeight uint64) error
 	GetBatchNoByL2Block(l2BlockNumber uint64) (uint64, error)
+	WriteBatchForcedBatch(batchNo, forcedBatchNo uint64) error
+	GetBatchForcedBatch(batchNo uint64) (uint64, bool, error)
+	GetLatestIncludedForcedBatch() (uint64, error)
 }
 
 type DsQueryClient interface {
@@ -193,6 +196,21 @@ func (p *BatchesProcessor) processBatchStartEntry(batchStart *types.BatchStart)
 		}
 	}
 
+	// forced batches are included in order so the stream only needs to tell us that the batch is a forced one
+	if batchStart.BatchType == types.BatchTypeForced {
+		_, found, err := p.hermezDb.GetBatchForcedBatch(batchStart.Number)
+		if err != nil || found {
+			return err
+		}
+		latestIncluded, err := p.hermezDb.GetLatestIncludedForcedBatch()
+		if err != nil {
+			return err
+		}
+		if err = p.hermezDb.WriteBatchForcedBatch(batchStart.Number, latestIncluded+1); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -361,10 +379,16 @@ func (p *BatchesProcessor) processFullBlock(blockEntry *types.FullL2Block) (endL
 func (p *BatchesProcessor) writeL2Block(l2Block *types.FullL2Block) error {
 	bn := new(big.Int).SetUint64(l2Block.L2BlockNumber)
 	txs := make([]ethTypes.Transaction, 0, len(l2Block.L2Txs))
-	for _, transaction := range l2Block.L2Txs {
-		ltx, _, err := txtype.DecodeTx(transaction.Encoded, transaction.EffectiveGasPricePercentage, l2Block.ForkId)
-		if err != nil {
-			return fmt.Errorf("decode tx error: %w", err)
+	for i, transaction := range l2Block.L2Txs {
+		var ltx ethTypes.Transaction
+		if l2Block.DecodedTxs != nil {
+			// decoded by the datastream client already
+			ltx = l2Block.DecodedTxs[i]
+		} else {
+			var err error
+			if ltx, _, err = txtype.DecodeTx(transaction.Encoded, transaction.EffectiveGasPricePercentage, l2Block.ForkId); err != nil {
+				return fmt.Errorf("decode tx error: %w", err)
+			}
 		}
 		txs = append(txs, ltx)
 
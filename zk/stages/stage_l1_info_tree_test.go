@@ -149,3 +149,72 @@ func TestSpawnL1InfoTreeStage(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, latestBlockNumber.Uint64()+1, progress)
 }
+
+func TestSpawnL1InfoTreeStageReorg(t *testing.T) {
+	ctx, db1 := context.Background(), memdb.NewTestDB(t)
+	tx := memdb.BeginRw(t, db1)
+	require.NoError(t, hermez_db.CreateHermezBuckets(tx))
+	require.NoError(t, db.CreateEriDbBuckets(tx))
+	hDB := hermez_db.NewHermezDb(tx)
+	require.NoError(t, stages.SaveStageProgress(tx, stages.L1InfoTree, 20))
+
+	s := &stagedsync.StageState{ID: stages.L1InfoTree, BlockNumber: 0}
+	u := &stagedsync.Sync{}
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	l1ContractAddresses := []common.Address{common.HexToAddress("0x1")}
+	l1ContractTopics := [][]common.Hash{{contracts.UpdateL1InfoTreeTopic}}
+	latestBlockNumber := big.NewInt(21)
+	filterQuery := ethereum.FilterQuery{
+		FromBlock: latestBlockNumber,
+		ToBlock:   latestBlockNumber,
+		Addresses: l1ContractAddresses,
+		Topics:    l1ContractTopics,
+	}
+
+	// the same L1 block on either side of the fork, with a different update in it
+	spawn := func(parentHash, exitRoot common.Hash) common.Hash {
+		header := &types.Header{ParentHash: parentHash, Number: latestBlockNumber, Time: uint64(time.Now().Unix())}
+		EthermanMock := mocks.NewMockIEtherman(mockCtrl)
+		EthermanMock.EXPECT().HeaderByNumber(gomock.Any(), latestBlockNumber).Return(header, nil).AnyTimes()
+		EthermanMock.EXPECT().BlockByNumber(gomock.Any(), nil).Return(types.NewBlockWithHeader(header), nil).AnyTimes()
+		EthermanMock.EXPECT().FilterLogs(gomock.Any(), filterQuery).Return([]types.Log{{
+			BlockNumber: latestBlockNumber.Uint64(),
+			BlockHash:   header.Hash(),
+			Address:     l1ContractAddresses[0],
+			Topics:      []common.Hash{contracts.UpdateL1InfoTreeTopic, exitRoot, exitRoot},
+		}}, nil).AnyTimes()
+
+		l1Syncer := syncer.NewL1Syncer(ctx, []syncer.IEtherman{EthermanMock}, l1ContractAddresses, l1ContractTopics, 10, 0, "latest")
+		cfg := StageL1InfoTreeCfg(db1, &ethconfig.Zk{}, l1infotree.NewUpdater(&ethconfig.Zk{}, l1Syncer))
+		require.NoError(t, SpawnL1InfoTreeStage(s, u, tx, cfg, ctx, log.New()))
+		l1Syncer.StopQueryBlocks()
+		l1Syncer.ConsumeQueryBlocks()
+		l1Syncer.WaitQueryBlocksToFinish()
+
+		return common.BytesToHash(keccak256.Hash(exitRoot.Bytes(), exitRoot.Bytes()))
+	}
+
+	reorgedGer := spawn(common.HexToHash("0x1"), common.HexToHash("0x111"))
+	ger := spawn(common.HexToHash("0x2"), common.HexToHash("0x222"))
+
+	// the update of the block reorged is replaced
+	update, err := hDB.GetL1InfoTreeUpdate(0)
+	require.NoError(t, err)
+	assert.Equal(t, ger, update.GER)
+	assert.Equal(t, common.HexToHash("0x2"), update.ParentHash)
+	update, err = hDB.GetL1InfoTreeUpdateByGer(reorgedGer)
+	require.NoError(t, err)
+	assert.Nil(t, update)
+	leaves, err := hDB.GetAllL1InfoTreeLeaves()
+	require.NoError(t, err)
+	assert.Len(t, leaves, 1)
+	roots, err := hDB.GetL1InfoTreeIndexToRoots()
+	require.NoError(t, err)
+	assert.Len(t, roots, 1)
+
+	progress, err := stages.GetStageProgress(tx, stages.L1InfoTree)
+	require.NoError(t, err)
+	assert.Equal(t, latestBlockNumber.Uint64()+1, progress)
+}
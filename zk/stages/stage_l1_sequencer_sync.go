@@ -66,6 +66,12 @@ func SpawnL1SequencerSyncStage(
 		progress = cfg.zkCfg.L1FirstBlock - 1
 	}
 
+	// the stage runs once and what it takes from the L1 is never unwound, so it can't take it from blocks an L1 reorg
+	// could drop
+	if cfg.zkCfg.L1HighestBlockType == "latest" {
+		return fmt.Errorf("the initial L1 data can't be synced up to the latest L1 block, it isn't unwound on an L1 reorg: set zkevm.l1-highest-block-type to safe or finalized")
+	}
+
 	// if the flag is set - wait for that block to be finalized on L1 before continuing
 	if progress <= cfg.zkCfg.L1FinalizedBlockRequirement && cfg.zkCfg.L1FinalizedBlockRequirement > 0 {
 		for {
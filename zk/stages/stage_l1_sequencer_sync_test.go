@@ -288,3 +288,20 @@ func TestPruneL1SequencerSyncStage(t *testing.T) {
 	err := PruneL1SequencerSyncStage(nil, nil, L1SequencerSyncCfg{}, context.Background())
 	assert.Nil(t, err)
 }
+
+func TestSpawnL1SequencerSyncStageRefusesLatest(t *testing.T) {
+	ctx, db1 := context.Background(), memdb.NewTestDB(t)
+	tx := memdb.BeginRw(t, db1)
+
+	s := &stagedsync.StageState{ID: stages.L1SequencerSync, BlockNumber: 0}
+	cfg := StageL1SequencerSyncCfg(db1, &ethconfig.Zk{L1FirstBlock: 20, L1HighestBlockType: "latest"}, nil)
+
+	// the initial L1 data isn't unwound on an L1 reorg, the L1 syncer is never started with it
+	err := SpawnL1SequencerSyncStage(s, &stagedsync.Sync{}, tx, cfg, ctx, log.New())
+	require.Error(t, err)
+
+	// once it has been synced the stage has nothing left to take from the L1
+	require.NoError(t, stages.SaveStageProgress(tx, stages.L1SequencerSync, 21))
+	err = SpawnL1SequencerSyncStage(s, &stagedsync.Sync{}, tx, cfg, ctx, log.New())
+	require.NoError(t, err)
+}
@@ -21,7 +21,9 @@ import (
 	"github.com/ledgerwatch/erigon/zk/contracts"
 	"github.com/ledgerwatch/erigon/zk/hermez_db"
 	"github.com/ledgerwatch/erigon/zk/sequencer"
+	"github.com/ledgerwatch/erigon/zk/syncer"
 	"github.com/ledgerwatch/erigon/zk/types"
+	"github.com/ledgerwatch/erigon/zkevm/etherman/smartcontracts/polygonzkevm"
 )
 
 type IL1Syncer interface {
@@ -37,11 +39,14 @@ type IL1Syncer interface {
 	L1QueryHeaders(logs []ethTypes.Log) (map[uint64]*ethTypes.Header, error)
 	GetBlock(number uint64) (*ethTypes.Block, error)
 	GetHeader(number uint64) (*ethTypes.Header, error)
+	GetTransaction(hash common.Hash) (ethTypes.Transaction, bool, error)
 	RunQueryBlocks(lastCheckedBlock uint64)
 	StopQueryBlocks()
 	ConsumeQueryBlocks()
 	WaitQueryBlocksToFinish()
 	CheckL1BlockFinalized(blockNo uint64) (bool, uint64, error)
+	GetLastCheckedL1Header() *ethTypes.Header
+	FindL1ForkPoint(hashes map[uint64]common.Hash) (uint64, bool, error)
 }
 
 var (
@@ -107,6 +112,15 @@ func SpawnStageL1Syncer(
 		return fmt.Errorf("GetStageProgress, %w", err)
 	}
 
+	// the data taken from L1 blocks the L1 has since reorganised past is unwound, and fetched again from the fork point
+	forkBlock, reorged, err := unwindL1SyncerReorg(logPrefix, tx, hermezDb, cfg.syncer)
+	if err != nil {
+		return fmt.Errorf("unwindL1SyncerReorg: %w", err)
+	}
+	if reorged && forkBlock < l1BlockProgress {
+		l1BlockProgress = forkBlock
+	}
+
 	// start syncer if not started
 	if !cfg.syncer.IsSyncStarted() {
 		if l1BlockProgress == 0 {
@@ -130,11 +144,15 @@ func SpawnStageL1Syncer(
 
 	newVerificationsCount := 0
 	newSequencesCount := 0
+	newForcedBatchesCount := 0
 	highestWrittenL1BlockNo := uint64(0)
 Loop:
 	for {
 		select {
 		case logs := <-logsChan:
+			if err := syncer.RecordL1BlockHashes(hermezDb, hermez_db.L1_SYNCER_BLOCK_HASHES, logs, nil); err != nil {
+				return fmt.Errorf("RecordL1BlockHashes: %w", err)
+			}
 			for _, l := range logs {
 				l := l
 				info, batchLogType := parseLogType(cfg.zkCfg.L1RollupId, &l)
@@ -177,6 +195,18 @@ Loop:
 						highestWrittenL1BlockNo = info.L1BlockNo
 					}
 					newVerificationsCount++
+				case logForceBatch:
+					forcedBatch, err := parseForcedBatchLog(cfg.syncer, &l)
+					if err != nil {
+						return fmt.Errorf("parseForcedBatchLog: %w", err)
+					}
+					if err := hermezDb.WriteL1ForcedBatch(forcedBatch); err != nil {
+						return fmt.Errorf("WriteL1ForcedBatch: %w", err)
+					}
+					if info.L1BlockNo > highestWrittenL1BlockNo {
+						highestWrittenL1BlockNo = info.L1BlockNo
+					}
+					newForcedBatchesCount++
 				case logIncompatible:
 					continue
 				default:
@@ -194,11 +224,14 @@ Loop:
 	}
 
 	latestCheckedBlock := cfg.syncer.GetLastCheckedL1Block()
+	if err := syncer.RecordL1BlockHashes(hermezDb, hermez_db.L1_SYNCER_BLOCK_HASHES, nil, cfg.syncer.GetLastCheckedL1Header()); err != nil {
+		return fmt.Errorf("RecordL1BlockHashes: %w", err)
+	}
 
 	lastCheckedL1BlockCounter.Set(float64(latestCheckedBlock))
 
 	if highestWrittenL1BlockNo > l1BlockProgress {
-		log.Info(fmt.Sprintf("[%s] Saving L1 syncer progress", logPrefix), "latestCheckedBlock", latestCheckedBlock, "newVerificationsCount", newVerificationsCount, "newSequencesCount", newSequencesCount, "highestWrittenL1BlockNo", highestWrittenL1BlockNo)
+		log.Info(fmt.Sprintf("[%s] Saving L1 syncer progress", logPrefix), "latestCheckedBlock", latestCheckedBlock, "newVerificationsCount", newVerificationsCount, "newSequencesCount", newSequencesCount, "newForcedBatchesCount", newForcedBatchesCount, "highestWrittenL1BlockNo", highestWrittenL1BlockNo)
 
 		if err := stages.SaveStageProgress(tx, stages.L1Syncer, highestWrittenL1BlockNo); err != nil {
 			return fmt.Errorf("SaveStageProgress: %w", err)
@@ -241,6 +274,7 @@ var (
 	logVerifyEtrog      BatchLogType = 4
 	logL1InfoTreeUpdate BatchLogType = 5
 	logRollbackBatches  BatchLogType = 6
+	logForceBatch       BatchLogType = 7
 
 	logIncompatible BatchLogType = 100
 )
@@ -282,6 +316,9 @@ func parseLogType(l1RollupId uint64, log *ethTypes.Log) (l1BatchInfo types.L1Bat
 	case contracts.RollbackBatchesTopic:
 		batchLogType = logRollbackBatches
 		batchNum = new(big.Int).SetBytes(log.Topics[1].Bytes()).Uint64()
+	case contracts.ForceBatchTopic:
+		batchLogType = logForceBatch
+		batchNum = new(big.Int).SetBytes(log.Topics[1].Bytes()).Uint64()
 	default:
 		batchLogType = logUnknown
 		batchNum = 0
@@ -296,9 +333,146 @@ func parseLogType(l1RollupId uint64, log *ethTypes.Log) (l1BatchInfo types.L1Bat
 	}, batchLogType
 }
 
+var forceBatchFilterer, _ = polygonzkevm.NewPolygonzkevmFilterer(common.Address{}, nil)
+
+// parseForcedBatchLog decodes a ForceBatch event.  The L1 block header is fetched to get the timestamp the batch was
+// forced at and the L1 block hash the batch is bound to.
+func parseForcedBatchLog(syncer IL1Syncer, l *ethTypes.Log) (*types.L1ForcedBatch, error) {
+	event, err := forceBatchFilterer.ParseForceBatch(*l)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := event.Transactions
+	if len(transactions) == 0 {
+		// the contract only emits the transactions when the batch is forced by another contract, when forced
+		// directly from an EOA they have to be taken from the calldata of the forceBatch call
+		if transactions, err = getForceBatchCalldataTransactions(syncer, l); err != nil {
+			return nil, err
+		}
+	}
+
+	header, err := syncer.GetHeader(l.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.L1ForcedBatch{
+		ForcedBatchNumber:  event.ForceBatchNum,
+		L1BlockNumber:      l.BlockNumber,
+		L1TxHash:           l.TxHash,
+		ForcedAt:           header.Time,
+		LastGlobalExitRoot: event.LastGlobalExitRoot,
+		ForcedBlockHashL1:  header.ParentHash,
+		Sequencer:          event.Sequencer,
+		Transactions:       transactions,
+	}, nil
+}
+
+func getForceBatchCalldataTransactions(syncer IL1Syncer, l *ethTypes.Log) ([]byte, error) {
+	tx, _, err := syncer.GetTransaction(l.TxHash)
+	if err != nil {
+		return nil, err
+	}
+
+	// a contract forcing an empty batch also emits no transactions, there is nothing to decode in that case
+	if tx.GetTo() == nil || *tx.GetTo() != l.Address {
+		return nil, nil
+	}
+
+	txData := tx.GetData()
+	if len(txData) < 4 {
+		return nil, fmt.Errorf("force batch tx %s has no calldata", l.TxHash)
+	}
+
+	smcAbi, err := polygonzkevm.PolygonzkevmMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	method, err := smcAbi.MethodById(txData[:4])
+	if err != nil {
+		return nil, err
+	}
+	if method.Name != "forceBatch" {
+		return nil, nil
+	}
+
+	data, err := method.Inputs.Unpack(txData[4:])
+	if err != nil {
+		return nil, err
+	}
+	transactions, ok := data[0].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("force batch tx %s has unexpected calldata", l.TxHash)
+	}
+
+	return transactions, nil
+}
+
+// unwindL1SyncerReorg checks the L1 blocks the data was taken from against the L1.  When the L1 has reorganised past
+// them, it stops the syncer and deletes the sequences, verifications and forced batches found after the fork point
+func unwindL1SyncerReorg(logPrefix string, tx kv.RwTx, hermezDb *hermez_db.HermezDb, l1Syncer IL1Syncer) (uint64, bool, error) {
+	hashes, err := hermezDb.GetL1BlockHashes(hermez_db.L1_SYNCER_BLOCK_HASHES)
+	if err != nil {
+		return 0, false, fmt.Errorf("GetL1BlockHashes: %w", err)
+	}
+	forkBlock, reorged, err := l1Syncer.FindL1ForkPoint(hashes)
+	if err != nil {
+		return 0, false, fmt.Errorf("FindL1ForkPoint: %w", err)
+	}
+	if !reorged {
+		return 0, false, nil
+	}
+
+	log.Warn(fmt.Sprintf("[%s] L1 reorg found, unwinding the L1 data", logPrefix), "forkBlock", forkBlock)
+
+	// the logs being fetched may be from either side of the fork
+	if l1Syncer.IsSyncStarted() {
+		l1Syncer.StopQueryBlocks()
+		l1Syncer.ConsumeQueryBlocks()
+		l1Syncer.WaitQueryBlocksToFinish()
+	}
+
+	// sequences rolled back by a rollback after the fork point stay deleted, they are older than what is fetched again
+	if err = hermezDb.DeleteSequencesFromL1Block(forkBlock + 1); err != nil {
+		return 0, false, fmt.Errorf("DeleteSequencesFromL1Block: %w", err)
+	}
+	if err = hermezDb.DeleteVerificationsFromL1Block(forkBlock + 1); err != nil {
+		return 0, false, fmt.Errorf("DeleteVerificationsFromL1Block: %w", err)
+	}
+	if err = hermezDb.DeleteL1ForcedBatchesFromL1Block(forkBlock + 1); err != nil {
+		return 0, false, fmt.Errorf("DeleteL1ForcedBatchesFromL1Block: %w", err)
+	}
+	if err = hermezDb.TruncateL1BlockHashes(hermez_db.L1_SYNCER_BLOCK_HASHES, forkBlock+1); err != nil {
+		return 0, false, fmt.Errorf("TruncateL1BlockHashes: %w", err)
+	}
+
+	progress, err := stages.GetStageProgress(tx, stages.L1Syncer)
+	if err != nil {
+		return 0, false, fmt.Errorf("GetStageProgress: %w", err)
+	}
+	if forkBlock < progress {
+		if err = stages.SaveStageProgress(tx, stages.L1Syncer, forkBlock); err != nil {
+			return 0, false, fmt.Errorf("SaveStageProgress: %w", err)
+		}
+	}
+	var verifiedBatchNo uint64
+	latestVerification, err := hermezDb.GetLatestVerification()
+	if err != nil {
+		return 0, false, fmt.Errorf("GetLatestVerification: %w", err)
+	}
+	if latestVerification != nil {
+		verifiedBatchNo = latestVerification.BatchNo
+	}
+	if err = stages.SaveStageProgress(tx, stages.L1VerificationsBatchNo, verifiedBatchNo); err != nil {
+		return 0, false, fmt.Errorf("SaveStageProgress: %w", err)
+	}
+
+	return forkBlock, true, nil
+}
+
 func UnwindL1SyncerStage(u *stagedsync.UnwindState, tx kv.RwTx, cfg L1SyncerCfg, ctx context.Context) (err error) {
-	// we want to keep L1 data during an unwind, as we only sync finalised data there should be
-	// no need to unwind here
+	// we want to keep L1 data during an unwind of the L2, reorgs of the L1 itself are unwound when the stage runs
 	return nil
 }
 
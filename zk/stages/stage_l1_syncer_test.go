@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/holiman/uint256"
 	ethereum "github.com/ledgerwatch/erigon"
 	"github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/kv/memdb"
@@ -19,6 +20,7 @@ import (
 	"github.com/ledgerwatch/erigon/zk/hermez_db"
 	"github.com/ledgerwatch/erigon/zk/syncer"
 	"github.com/ledgerwatch/erigon/zk/syncer/mocks"
+	"github.com/ledgerwatch/erigon/zkevm/etherman/smartcontracts/polygonzkevm"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
@@ -102,6 +104,7 @@ func TestSpawnStageL1Syncer(t *testing.T) {
 	latestBlock := types.NewBlockWithHeader(latestBlockHeader)
 
 	EthermanMock.EXPECT().BlockByNumber(gomock.Any(), nil).Return(latestBlock, nil).AnyTimes()
+	EthermanMock.EXPECT().HeaderByNumber(gomock.Any(), latestBlockNumber).Return(latestBlockHeader, nil).AnyTimes()
 
 	filterQuery := ethereum.FilterQuery{
 		FromBlock: l1FirstBlock,
@@ -285,6 +288,87 @@ func TestSpawnStageL1Syncer(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "ForceBatchTopic",
+			getLog: func(hDB *hermez_db.HermezDb) (types.Log, error) {
+				forcedBatchNum := uint64(3)
+				forcedBatchNumHash := common.BytesToHash(big.NewInt(0).SetUint64(forcedBatchNum).Bytes())
+				txHash := common.HexToHash("0x777")
+				ger := common.HexToHash("0x123")
+				sequencer := common.HexToAddress("0x456")
+				transactions := []byte{0x0b, 0x01, 0x02, 0x03}
+
+				data := append([]byte{}, ger.Bytes()...)
+				data = append(data, common.BytesToHash(sequencer.Bytes()).Bytes()...)
+				data = append(data, common.BigToHash(big.NewInt(96)).Bytes()...)
+				data = append(data, common.BigToHash(big.NewInt(int64(len(transactions)))).Bytes()...)
+				data = append(data, transactions...)
+				data = append(data, make([]byte, 32-len(transactions))...)
+
+				return types.Log{
+					BlockNumber: latestBlockNumber.Uint64(),
+					Address:     l1ContractAddresses[0],
+					Topics:      []common.Hash{contracts.ForceBatchTopic, forcedBatchNumHash},
+					Data:        data,
+					TxHash:      txHash,
+				}, nil
+			},
+			assert: func(t *testing.T, hDB *hermez_db.HermezDb) {
+				forcedBatch, err := hDB.GetL1ForcedBatch(3)
+				require.NoError(t, err)
+				require.NotNil(t, forcedBatch)
+
+				require.Equal(t, uint64(3), forcedBatch.ForcedBatchNumber)
+				require.Equal(t, latestBlockNumber.Uint64(), forcedBatch.L1BlockNumber)
+				require.Equal(t, latestBlockTime, forcedBatch.ForcedAt)
+				require.Equal(t, common.HexToHash("0x777"), forcedBatch.L1TxHash)
+				require.Equal(t, common.HexToHash("0x123"), forcedBatch.LastGlobalExitRoot)
+				require.Equal(t, latestBlockParentHash, forcedBatch.ForcedBlockHashL1)
+				require.Equal(t, common.HexToAddress("0x456"), forcedBatch.Sequencer)
+				require.Equal(t, []byte{0x0b, 0x01, 0x02, 0x03}, forcedBatch.Transactions)
+			},
+		},
+		{
+			name: "ForceBatchTopicFromEOA",
+			getLog: func(hDB *hermez_db.HermezDb) (types.Log, error) {
+				forcedBatchNum := uint64(4)
+				forcedBatchNumHash := common.BytesToHash(big.NewInt(0).SetUint64(forcedBatchNum).Bytes())
+				txHash := common.HexToHash("0x778")
+				ger := common.HexToHash("0x124")
+				sequencer := common.HexToAddress("0x457")
+				transactions := []byte{0x0b, 0x04, 0x05, 0x06}
+
+				smcAbi, err := polygonzkevm.PolygonzkevmMetaData.GetAbi()
+				require.NoError(t, err)
+
+				// forced from an EOA the event is emitted without the transactions
+				data, err := smcAbi.Events["ForceBatch"].Inputs.NonIndexed().Pack(ger, sequencer, []byte{})
+				require.NoError(t, err)
+
+				calldata, err := smcAbi.Pack("forceBatch", transactions, big.NewInt(0))
+				require.NoError(t, err)
+				l1Tx := types.NewTransaction(0, l1ContractAddresses[0], uint256.NewInt(0), 0, uint256.NewInt(0), calldata)
+				EthermanMock.EXPECT().TransactionByHash(gomock.Any(), txHash).Return(l1Tx, false, nil).AnyTimes()
+
+				return types.Log{
+					BlockNumber: latestBlockNumber.Uint64(),
+					Address:     l1ContractAddresses[0],
+					Topics:      []common.Hash{contracts.ForceBatchTopic, forcedBatchNumHash},
+					Data:        data,
+					TxHash:      txHash,
+				}, nil
+			},
+			assert: func(t *testing.T, hDB *hermez_db.HermezDb) {
+				forcedBatch, err := hDB.GetL1ForcedBatch(4)
+				require.NoError(t, err)
+				require.NotNil(t, forcedBatch)
+
+				require.Equal(t, uint64(4), forcedBatch.ForcedBatchNumber)
+				require.Equal(t, common.HexToHash("0x124"), forcedBatch.LastGlobalExitRoot)
+				require.Equal(t, common.HexToAddress("0x457"), forcedBatch.Sequencer)
+				require.Equal(t, []byte{0x0b, 0x04, 0x05, 0x06}, forcedBatch.Transactions)
+			},
+		},
 	}
 
 	filteredLogs := []types.Log{}
@@ -124,7 +124,6 @@ func sequencingBatchStep(
 	runLoopBlocks := true
 	batchContext := newBatchContext(ctx, &cfg, &historyCfg, s, sdb)
 	batchState := newBatchState(forkId, batchNumberForStateInitialization, executionAt+1, cfg.zk.HasExecutors(), cfg.zk.L1SyncStartBlock > 0, cfg.txPool, resequenceBatchJob)
-	blockDataSizeChecker := NewBlockDataChecker(cfg.zk.ShouldCountersBeUnlimited(batchState.isL1Recovery()))
 	streamWriter := newSequencerBatchStreamWriter(batchContext, batchState)
 
 	// injected batch
@@ -174,14 +173,29 @@ func sequencingBatchStep(
 		return err
 	}
 
+	if needsUnwind, err = tryPauseSequencer(batchContext, batchState, streamWriter, u, executionAt); needsUnwind || err != nil {
+		return err
+	}
+
+	if needsUnwind, err = waitForSequencerLease(batchContext, batchState, streamWriter, u, lastBatch, executionAt); needsUnwind || err != nil {
+		return err
+	}
+
 	if err := utils.UpdateZkEVMBlockCfg(cfg.chainConfig, sdb.hermezDb, logPrefix); err != nil {
 		return err
 	}
 
+	if !batchState.isAnyRecovery() {
+		if err = prepareForcedBatch(batchContext, batchState); err != nil {
+			return err
+		}
+	}
+
 	batchCounters, err := prepareBatchCounters(batchContext, batchState)
 	if err != nil {
 		return err
 	}
+	blockDataSizeChecker := NewBlockDataChecker(cfg.zk.ShouldCountersBeUnlimited(batchState.isL1DataBatch()))
 
 	if batchState.isL1Recovery() {
 		if cfg.zk.L1SyncStopBatch > 0 && batchState.batchNumber > cfg.zk.L1SyncStopBatch {
@@ -234,6 +248,7 @@ func sequencingBatchStep(
 
 	// For X Layer
 	var batchCloseReason metrics.BatchFinalizeType
+	var overflowedCounters []string
 	batchStart := time.Now()
 
 	// once the batch ticker has ticked we need a signal to close the batch after the next block is done
@@ -244,21 +259,29 @@ func sequencingBatchStep(
 		metrics.GetLogStatistics().CumulativeCounting(metrics.BlockCounter)
 		if batchTimedOut {
 			log.Debug(fmt.Sprintf("[%s] Closing batch due to timeout", logPrefix))
+			batchCloseReason = metrics.BatchTimeOut
 			break
 		}
 		log.Info(fmt.Sprintf("[%s] Starting block %d (forkid %v)...", logPrefix, blockNumber, batchState.forkId))
 		logTicker.Reset(10 * time.Second)
-		blockTicker.Reset(cfg.zk.SequencerBlockSealTime)
+		blockTicker.Reset(nextBlockSealTime(&cfg))
 
-		if batchState.isL1Recovery() {
+		if batchState.isL1DataBatch() {
 			blockNumbersInBatchSoFar, err := batchContext.sdb.hermezDb.GetL2BlockNosByBatch(batchState.batchNumber)
 			if err != nil {
 				return err
 			}
 
-			didLoadedAnyDataForRecovery := batchState.loadBlockL1RecoveryData(uint64(len(blockNumbersInBatchSoFar)))
-			if !didLoadedAnyDataForRecovery {
-				log.Info(fmt.Sprintf("[%s] Block %d is not part of batch %d. Stopping blocks loop", logPrefix, blockNumber, batchState.batchNumber))
+			if batchState.isL1Recovery() {
+				didLoadedAnyDataForRecovery := batchState.loadBlockL1RecoveryData(uint64(len(blockNumbersInBatchSoFar)))
+				if !didLoadedAnyDataForRecovery {
+					log.Info(fmt.Sprintf("[%s] Block %d is not part of batch %d. Stopping blocks loop", logPrefix, blockNumber, batchState.batchNumber))
+					batchCloseReason = metrics.BatchL1Recovery
+					break
+				}
+			} else if !batchState.loadBlockForcedBatchData(uint64(len(blockNumbersInBatchSoFar))) {
+				log.Info(fmt.Sprintf("[%s] Forced batch %d fully included. Stopping blocks loop", logPrefix, batchState.forcedBatchData.forcedBatch.ForcedBatchNumber))
+				batchCloseReason = metrics.BatchForced
 				break
 			}
 		}
@@ -274,6 +297,7 @@ func sequencingBatchStep(
 					}
 				}
 
+				batchCloseReason = metrics.BatchResequence
 				runLoopBlocks = false
 				break
 			}
@@ -286,6 +310,7 @@ func sequencingBatchStep(
 
 		if batchDataOverflow := blockDataSizeChecker.AddBlockStartData(); batchDataOverflow {
 			log.Info(fmt.Sprintf("[%s] BatchL2Data limit reached. Stopping.", logPrefix), "blockNumber", blockNumber)
+			batchCloseReason = metrics.BatchDataOverflow
 			break
 		}
 
@@ -304,6 +329,9 @@ func sequencingBatchStep(
 		if (!batchState.isAnyRecovery() || batchState.isResequence()) && overflowOnNewBlock {
 			// For X Layer
 			batchCloseReason = metrics.BatchCounterOverflow
+			if overflowedCounters, err = batchCounters.OverflowingCounters(l1TreeUpdateIndex != 0); err != nil {
+				return err
+			}
 			break
 		}
 
@@ -314,7 +342,7 @@ func sequencingBatchStep(
 		batchState.blockState.builtBlockElements.resetBlockBuildingArrays()
 
 		parentRoot := parentBlock.Root()
-		if err = handleStateForNewBlockStarting(batchContext, ibs, blockNumber, batchState.batchNumber, header.Time, &parentRoot, l1TreeUpdate, shouldWriteGerToContract); err != nil {
+		if err = handleStateForNewBlockStarting(batchContext, ibs, blockNumber, batchState.batchNumber, header.Time, &parentRoot, l1TreeUpdate, shouldWriteGerToContract, batchState.isForcedBatch()); err != nil {
 			return err
 		}
 
@@ -367,7 +395,7 @@ func sequencingBatchStep(
 					if err != nil {
 						return err
 					}
-				} else if !batchState.isL1Recovery() {
+				} else if !batchState.isL1DataBatch() {
 
 					var allConditionsOK bool
 					var newTransactions []types.Transaction
@@ -404,24 +432,110 @@ func sequencingBatchStep(
 				badTxHashes := make([]common.Hash, 0)
 				minedTxHashes := make([]common.Hash, 0)
 
+				// the block is never closed between the transactions of a bundle
+				bundleEnd := 0
+
 			InnerLoopTransactions:
 				for i, transaction := range batchState.blockState.transactionsForInclusion {
 					// quick check if we should stop handling transactions
-					select {
-					case <-blockTicker.C:
-						if !batchState.isAnyRecovery() {
-							innerBreak = true
-							break InnerLoopTransactions
+					if i >= bundleEnd {
+						select {
+						case <-blockTicker.C:
+							if !batchState.isAnyRecovery() {
+								innerBreak = true
+								break InnerLoopTransactions
+							}
+						default:
 						}
-					default:
 					}
 
 					txHash := transaction.Hash()
 					effectiveGas := batchState.blockState.getL1EffectiveGases(cfg, i)
 
+					if i >= bundleEnd && !batchState.isAnyRecovery() {
+						if bundle, startsHere := getBundleStartingAt(cfg, batchState.blockState, i); bundle != nil {
+							if !startsHere {
+								// only ever included together with the first transaction of its bundle
+								continue
+							}
+
+							bundleTransactions := batchState.blockState.transactionsForInclusion[i : i+len(bundle.TxHashes)]
+							if deadlinePassed(cfg, blockNumber, header.Time, bundleTransactions...) {
+								log.Info(fmt.Sprintf("[%s] bundle %s past its deadline, skipping it", logPrefix, bundle.Hash))
+								for _, bundleTx := range bundleTransactions {
+									badTxHashes = append(badTxHashes, bundleTx.Hash())
+								}
+								continue
+							}
+
+							bundleEffectiveGases := make([]uint8, len(bundleTransactions))
+							for j := range bundleTransactions {
+								bundleEffectiveGases[j] = batchState.blockState.getL1EffectiveGases(cfg, i+j)
+							}
+
+							anyOverflow, failedTxHash, err := tryBundle(cfg, sdb, ibs, batchCounters, &blockContext, header, bundleTransactions, bundleEffectiveGases, batchState.forkId, l1TreeUpdateIndex, blockDataSizeChecker, priorityReserve(ctx, cfg, batchState, bundleTransactions...), batchState.gasUsed)
+							if err != nil {
+								if isOkKnownError(err) {
+									log.Warn(fmt.Sprintf("[%s] known error adding bundle to block, skipping for now: %v", logPrefix, err), "bundle", bundle.Hash, "hash", failedTxHash)
+								} else {
+									log.Warn(fmt.Sprintf("[%s] error adding bundle to batch, discarding from pool", logPrefix), "bundle", bundle.Hash, "hash", failedTxHash, "err", err)
+								}
+								for _, bundleTx := range bundleTransactions {
+									badTxHashes = append(badTxHashes, bundleTx.Hash())
+									if !isOkKnownError(err) {
+										batchState.blockState.transactionsToDiscard = append(batchState.blockState.transactionsToDiscard, batchState.blockState.transactionHashesToSlots[bundleTx.Hash()])
+									}
+								}
+								continue
+							}
+
+							switch anyOverflow {
+							case overflowCounters:
+								if !batchState.hasAnyTransactionsInThisBatch && len(batchState.builtBlocks) == 0 {
+									for _, bundleTxHash := range bundle.TxHashes {
+										cfg.txPool.MarkForDiscardFromPendingBest(bundleTxHash)
+									}
+									log.Info(fmt.Sprintf("[%s] bundle %s cannot fit into batch", logPrefix, bundle.Hash))
+									continue
+								}
+
+								batchState.newOverflowTransaction()
+								log.Info(fmt.Sprintf("[%s] bundle %s was not included in this batch because it overflowed.", logPrefix, bundle.Hash), "overflow transactions", batchState.overflowTransactions)
+								if batchState.reachedOverflowTransactionLimit() || cfg.zk.SealBatchImmediatelyOnOverflow {
+									log.Info(fmt.Sprintf("[%s] closing batch due to counters", logPrefix), "counters: ", batchState.overflowTransactions, "immediate", cfg.zk.SealBatchImmediatelyOnOverflow)
+									batchCloseReason = metrics.BatchCounterOverflow
+									runLoopBlocks = false
+									if len(batchState.blockState.builtBlockElements.transactions) == 0 {
+										emptyBlockOverflow = true
+									}
+									break OuterLoopTransactions
+								}
+								continue
+							case overflowReserved:
+								// stays up for inclusion, there may be room for it once the reserve is released
+								continue
+							case overflowGas:
+								log.Info(fmt.Sprintf("[%s] gas overflowed adding bundle to block", logPrefix), "block", blockNumber, "bundle", bundle.Hash)
+								batchCloseReason = metrics.BatchGasOverflow
+								runLoopBlocks = false
+								break OuterLoopTransactions
+							case overflowNone:
+							}
+
+							bundleEnd = i + len(bundle.TxHashes)
+						}
+					}
+
+					if i >= bundleEnd && !batchState.isAnyRecovery() && deadlinePassed(cfg, blockNumber, header.Time, transaction) {
+						// the pool discards it as soon as it yields again
+						log.Info(fmt.Sprintf("[%s] transaction %s past its deadline, skipping it", logPrefix, txHash))
+						badTxHashes = append(badTxHashes, txHash)
+						continue
+					}
+
 					// The copying of this structure is intentional
 					backupDataSizeChecker := *blockDataSizeChecker
-					receipt, execResult, anyOverflow, err := attemptAddTransaction(cfg, sdb, ibs, batchCounters, &blockContext, header, transaction, effectiveGas, batchState.isL1Recovery(), batchState.forkId, l1TreeUpdateIndex, &backupDataSizeChecker)
+					receipt, execResult, anyOverflow, err := attemptAddTransaction(cfg, sdb, ibs, batchCounters, &blockContext, header, transaction, effectiveGas, batchState.isL1DataBatch(), batchState.forkId, l1TreeUpdateIndex, &backupDataSizeChecker, priorityReserve(ctx, cfg, batchState, transaction), batchState.gasUsed)
 					if err != nil {
 						if batchState.isLimboRecovery() {
 							panic("limbo transaction has already been executed once so they must not fail while re-executing")
@@ -449,6 +563,15 @@ func sequencingBatchStep(
 							continue
 						}
 
+						// the same applies to forced batches, the transactions are already on the L1 so we can only skip the failing ones
+						if batchState.isForcedBatch() {
+							log.Warn(fmt.Sprintf("[%s] error adding transaction to forced batch: %v", logPrefix, err),
+								"hash", txHash,
+								"to", transaction.GetTo(),
+							)
+							continue
+						}
+
 						if isOkKnownError(err) {
 							// if this is a known error that could be caused by some edge case coming from the pool we want to warn
 							// about it and continue on as normal but ensure we don't continue to keep trying to add this transaction
@@ -468,6 +591,12 @@ func sequencingBatchStep(
 
 					switch anyOverflow {
 					case overflowCounters:
+						// keep hold of what overflowed in case this closes the batch
+						txOverflowedCounters, err := batchCounters.OverflowingCounters(l1TreeUpdateIndex != 0)
+						if err != nil {
+							return err
+						}
+
 						// remove the last attempted counters as we may want to continue processing this batch with other transactions
 						batchCounters.RemovePreviousTransactionCounters()
 
@@ -475,7 +604,7 @@ func sequencingBatchStep(
 							panic("limbo transaction has already been executed once so they must not overflow counters while re-executing")
 						}
 
-						if !batchState.isL1Recovery() {
+						if !batchState.isL1DataBatch() {
 							/*
 								There are two cases when overflow could occur.
 								1. The block DOES not contain any transactions.
@@ -503,6 +632,8 @@ func sequencingBatchStep(
 									metrics.GetLogStatistics().CumulativeCounting(metrics.ZKOverflowBlockCounter)
 									metrics.SeqZKOverflowBlockCounter.Inc()
 									log.Info(fmt.Sprintf("[%s] closing batch due to counters", logPrefix), "counters: ", batchState.overflowTransactions, "immediate", cfg.zk.SealBatchImmediatelyOnOverflow)
+									batchCloseReason = metrics.BatchCounterOverflow
+									overflowedCounters = txOverflowedCounters
 									runLoopBlocks = false
 									if len(batchState.blockState.builtBlockElements.transactions) == 0 {
 										emptyBlockOverflow = true
@@ -519,12 +650,24 @@ func sequencingBatchStep(
 							return fmt.Errorf("strict mode enabled, but resequenced batch %d overflowed counters on block %d", batchState.batchNumber, blockNumber)
 						}
 					case overflowGas:
+						if batchState.isForcedBatch() {
+							// a forced batch has to be included as a whole so the transaction is treated as invalid and skipped
+							log.Warn(fmt.Sprintf("[%s] gas overflowed adding transaction to forced batch, skipping it", logPrefix), "block", blockNumber, "tx-hash", txHash)
+							continue
+						}
 						if batchState.isAnyRecovery() {
 							panic(fmt.Sprintf("block gas limit overflow in recovery block: %d", blockNumber))
 						}
 						log.Info(fmt.Sprintf("[%s] gas overflowed adding transaction to block", logPrefix), "block", blockNumber, "tx-hash", txHash)
+						batchCloseReason = metrics.BatchGasOverflow
 						runLoopBlocks = false
 						break OuterLoopTransactions
+					case overflowReserved:
+						// only the priority transactions may use what is left of the batch, this one stays up for
+						// inclusion in case the reserve is released before the batch is closed
+						batchCounters.RemovePreviousTransactionCounters()
+						log.Debug(fmt.Sprintf("[%s] transaction %s left out of the share reserved for priority transactions", logPrefix, txHash))
+						continue
 					case overflowNone:
 					}
 
@@ -534,6 +677,13 @@ func sequencingBatchStep(
 						blockDataSizeChecker = &backupDataSizeChecker
 						batchState.onAddedTransaction(transaction, receipt, execResult, effectiveGas)
 						minedTxHashes = append(minedTxHashes, txHash)
+
+						if !batchState.isAnyRecovery() {
+							index := uint64(len(batchState.blockState.builtBlockElements.transactions) - 1)
+							if err := cfg.preconfirmer.Preconfirm(txHash, batchState.batchNumber, blockNumber, index, receipt.Status, receipt.CumulativeGasUsed); err != nil {
+								log.Warn(fmt.Sprintf("[%s] %v", logPrefix, err))
+							}
+						}
 					}
 
 					// We will only update the processed index in resequence job if there isn't overflow
@@ -592,6 +742,11 @@ func sequencingBatchStep(
 					break OuterLoopTransactions
 				}
 
+				if batchState.isForcedBatch() {
+					// every decoded block of the forced batch becomes a single L2 block
+					break OuterLoopTransactions
+				}
+
 				if batchState.isLimboRecovery() {
 					batchCloseReason = metrics.BatchLimboRecovery
 					runLoopBlocks = false
@@ -610,6 +765,14 @@ func sequencingBatchStep(
 		if block, err = doFinishBlockAndUpdateState(batchContext, ibs, header, parentBlock, batchState, ger, l1BlockHash, l1TreeUpdateIndex, infoTreeIndexProgress, batchCounters); err != nil {
 			return err
 		}
+		batchState.gasUsed += block.GasUsed()
+
+		// the lease may have run out while the block was built, only the lease holder commits it.  The block is rolled
+		// back along with the rest of the db transaction and the stage waits for the lease on its next run
+		if !cfg.sequencerLease.Held() {
+			log.Error(fmt.Sprintf("[%s] Sequencer lease lost while building block %d, discarding it", logPrefix, blockNumber))
+			return nil
+		}
 
 		if err := cfg.txPool.RemoveMinedTransactions(ctx, sdb.tx, header.GasLimit, batchState.blockState.builtBlockElements.txSlots); err != nil {
 			return err
@@ -656,9 +819,9 @@ func sequencingBatchStep(
 		}
 		metrics.GetLogStatistics().CumulativeTiming(metrics.BatchCommitDBTiming, time.Since(start))
 
-		// do not use remote executor in l1recovery mode
+		// do not use remote executor in l1recovery mode or for forced batches as their counters are unlimited
 		// if we need remote executor in l1 recovery then we must allow commit/start DB transactions
-		useExecutorForVerification := !batchState.isL1Recovery() && batchState.hasExecutorForThisBatch
+		useExecutorForVerification := !batchState.isL1DataBatch() && batchState.hasExecutorForThisBatch
 		counters, err := batchCounters.CombineCollectors(l1TreeUpdateIndex != 0)
 		if err != nil {
 			return err
@@ -684,6 +847,27 @@ func sequencingBatchStep(
 		if err != nil || needsUnwind {
 			return err
 		}
+
+		// another node may be taking over as the sequencer, no more blocks until the lease is back
+		if !cfg.sequencerLease.Held() {
+			if needsUnwind, err = waitWhileSequencerLeaseLost(batchContext, batchState, streamWriter, u, blockNumber); needsUnwind || err != nil {
+				return err
+			}
+		}
+
+		// requests from the admin api are only acted on between blocks and never in the middle of a recovery
+		if !batchState.isAnyRecovery() {
+			if cfg.sequencerControl.PauseRequested() {
+				if needsUnwind, err = waitWhilePaused(batchContext, batchState, streamWriter, u, blockNumber); needsUnwind || err != nil {
+					return err
+				}
+			}
+			if cfg.sequencerControl.DrainRequested() {
+				log.Info(fmt.Sprintf("[%s] Closing batch, the sequencer is draining", logPrefix))
+				batchCloseReason = metrics.BatchDrained
+				break
+			}
+		}
 	}
 
 	/*
@@ -700,7 +884,12 @@ func sequencingBatchStep(
 		return fmt.Errorf("writing plain state version: %w", err)
 	}
 
-	log.Info(fmt.Sprintf("[%s] Finish batch %d...", batchContext.s.LogPrefix(), batchState.batchNumber))
+	// the unwind of this value is handled by UnwindSequenceExecutionStageDbWrites
+	if err := writeBatchCloseInfo(batchContext, batchState, batchCloseReason, overflowedCounters); err != nil {
+		return err
+	}
+
+	log.Info(fmt.Sprintf("[%s] Finish batch %d...", batchContext.s.LogPrefix(), batchState.batchNumber), "reason", batchCloseReason)
 
 	// For X Layer
 	metrics.GetLogStatistics().SetTag(metrics.BatchCloseReason, string(batchCloseReason))
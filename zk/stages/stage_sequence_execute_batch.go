@@ -2,6 +2,7 @@ package stages
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/ledgerwatch/erigon/core/rawdb"
@@ -10,6 +11,8 @@ import (
 	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/erigon/zk/l1_data"
 	verifier "github.com/ledgerwatch/erigon/zk/legacy_executor_verifier"
+	"github.com/ledgerwatch/erigon/zk/metrics"
+	zktypes "github.com/ledgerwatch/erigon/zk/types"
 	"github.com/ledgerwatch/log/v3"
 )
 
@@ -36,13 +39,22 @@ func prepareBatchNumber(sdb *stageDb, forkId, lastBatch uint64, isL1Recovery boo
 				return lastBatch, nil
 			}
 		}
+	} else {
+		// a forced batch interrupted by a restart has to be completed before moving on to the next batch
+		isIncomplete, err := isForcedBatchIncomplete(sdb, forkId, lastBatch)
+		if err != nil {
+			return 0, err
+		}
+		if isIncomplete {
+			return lastBatch, nil
+		}
 	}
 
 	return lastBatch + 1, nil
 }
 
 func prepareBatchCounters(batchContext *BatchContext, batchState *BatchState) (*vm.BatchCounterCollector, error) {
-	return vm.NewBatchCounterCollector(batchContext.sdb.smt.GetDepth(), uint16(batchState.forkId), batchContext.cfg.zk.VirtualCountersSmtReduction, batchContext.cfg.zk.ShouldCountersBeUnlimited(batchState.isL1Recovery()), nil), nil
+	return vm.NewBatchCounterCollector(batchContext.sdb.smt.GetDepth(), uint16(batchState.forkId), batchContext.cfg.zk.VirtualCountersSmtReduction, batchContext.cfg.zk.ShouldCountersBeUnlimited(batchState.isL1DataBatch()), nil), nil
 }
 
 func doCheckForBadBatch(batchContext *BatchContext, batchState *BatchState, thisBlock uint64) (bool, error) {
@@ -86,6 +98,30 @@ func writeBadBatchDetails(batchContext *BatchContext, batchState *BatchState, bl
 	return nil
 }
 
+// writeBatchCloseInfo records why the batch was closed along with how many blocks and transactions made it into it
+func writeBatchCloseInfo(batchContext *BatchContext, batchState *BatchState, reason metrics.BatchFinalizeType, overflowedCounters []string) error {
+	blockNumbers, err := batchContext.sdb.hermezDb.GetL2BlockNosByBatch(batchState.batchNumber)
+	if err != nil {
+		return err
+	}
+
+	var transactionCount uint64
+	for _, blockNumber := range blockNumbers {
+		_, _, txAmount, err := rawdb.ReadBodyByNumber(batchContext.sdb.tx, blockNumber)
+		if err != nil {
+			return err
+		}
+		transactionCount += uint64(txAmount)
+	}
+
+	return batchContext.sdb.hermezDb.WriteBatchCloseInfo(batchState.batchNumber, &zktypes.BatchCloseInfo{
+		Reason:           string(reason),
+		OverflowCounters: strings.Join(overflowedCounters, ","),
+		BlockCount:       uint64(len(blockNumbers)),
+		TransactionCount: transactionCount,
+	})
+}
+
 func updateStreamAndCheckRollback(
 	batchContext *BatchContext,
 	batchState *BatchState,
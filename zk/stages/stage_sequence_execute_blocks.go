@@ -30,6 +30,7 @@ func handleStateForNewBlockStarting(
 	stateRoot *common.Hash,
 	l1info *zktypes.L1InfoTreeUpdate,
 	shouldWriteGerToContract bool,
+	isForcedBatchStart bool,
 ) error {
 	chainConfig := batchContext.cfg.chainConfig
 	hermezDb := batchContext.sdb.hermezDb
@@ -38,8 +39,9 @@ func handleStateForNewBlockStarting(
 
 	// handle writing to the ger manager contract but only if the index is above 0
 	// block 1 is a special case as it's the injected batch, so we always need to check the GER/L1 block hash
-	// as these will be force-fed from the event from L1
-	if l1info != nil && l1info.Index > 0 || blockNumber == 1 {
+	// as these will be force-fed from the event from L1. The same applies to the first block of a forced batch
+	// which carries the GER and L1 block hash the batch was forced with
+	if l1info != nil && (l1info.Index > 0 || isForcedBatchStart) || blockNumber == 1 {
 		// store it so we can retrieve for the data stream
 		if err := hermezDb.WriteBlockGlobalExitRoot(blockNumber, l1info.GER); err != nil {
 			return err
@@ -0,0 +1,115 @@
+package stages
+
+import (
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/common"
+
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/core/vm/evmtypes"
+	"github.com/ledgerwatch/erigon/zk/txpool"
+)
+
+// getBundleStartingAt returns the bundle the transaction at index i belongs to, nil if it isn't part of one.
+// startsHere is false when the bundle doesn't start at i or its transactions don't follow in order, the transaction
+// is then only ever included together with the first transaction of its bundle.
+func getBundleStartingAt(cfg SequenceBlockCfg, blockState *BlockState, i int) (bundle *txpool.Bundle, startsHere bool) {
+	txs := blockState.transactionsForInclusion
+
+	bundle, ok := cfg.txPool.GetBundleByTx(blockState.transactionHashesToSlots[txs[i].Hash()])
+	if !ok {
+		return nil, false
+	}
+	if i+len(bundle.TxHashes) > len(txs) {
+		return bundle, false
+	}
+	for j, txHash := range bundle.TxHashes {
+		if blockState.transactionHashesToSlots[txs[i+j].Hash()] != txHash {
+			return bundle, false
+		}
+	}
+
+	return bundle, true
+}
+
+// tryBundle executes the transactions of a bundle on top of the block built so far without changing it, the state,
+// counters and header used are throwaway copies.  As execution is deterministic the bundle can be added for real
+// afterwards if no transaction errored or overflowed.
+func tryBundle(
+	cfg SequenceBlockCfg,
+	sdb *stageDb,
+	ibs *state.IntraBlockState,
+	batchCounters *vm.BatchCounterCollector,
+	blockContext *evmtypes.BlockContext,
+	header *types.Header,
+	transactions []types.Transaction,
+	effectiveGases []uint8,
+	forkId, l1InfoIndex uint64,
+	blockDataSizeChecker *BlockDataChecker,
+	reserve float64,
+	batchGasUsed uint64,
+) (overflowType, common.Hash, error) {
+	bundleIbs := state.New(&ibsStateReader{ibs: ibs})
+	bundleCounters := batchCounters.Clone()
+	bundleHeader := types.CopyHeader(header)
+	bundleDataSizeChecker := *blockDataSizeChecker
+
+	var anyOverflow overflowType
+	var failedTx common.Hash
+	var err error
+	tried := make([]common.Hash, 0, len(transactions))
+	for i, transaction := range transactions {
+		if _, _, anyOverflow, err = attemptAddTransaction(cfg, sdb, bundleIbs, bundleCounters, blockContext, bundleHeader, transaction, effectiveGases[i], false, forkId, l1InfoIndex, &bundleDataSizeChecker, reserve, batchGasUsed); err != nil || anyOverflow != overflowNone {
+			failedTx = transaction.Hash()
+			break
+		}
+		tried = append(tried, transaction.Hash())
+	}
+
+	// nothing of the try may stay behind, the real run writes the effective gas price percentages again
+	if deleteErr := sdb.hermezDb.DeleteEffectiveGasPricePercentages(&tried); deleteErr != nil {
+		return overflowNone, common.Hash{}, deleteErr
+	}
+
+	return anyOverflow, failedTx, err
+}
+
+// ibsStateReader reads the state of the block being built from its IntraBlockState
+type ibsStateReader struct {
+	ibs *state.IntraBlockState
+}
+
+func (r *ibsStateReader) ReadAccountData(address common.Address) (*accounts.Account, error) {
+	if !r.ibs.Exist(address) {
+		return nil, nil
+	}
+
+	account := accounts.NewAccount()
+	account.Initialised = true
+	account.Nonce = r.ibs.GetNonce(address)
+	account.Balance = *r.ibs.GetBalance(address)
+	account.CodeHash = r.ibs.GetCodeHash(address)
+	account.Incarnation = r.ibs.GetIncarnation(address)
+
+	return &account, nil
+}
+
+func (r *ibsStateReader) ReadAccountStorage(address common.Address, _ uint64, key *common.Hash) ([]byte, error) {
+	var value uint256.Int
+	r.ibs.GetState(address, key, &value)
+	return value.Bytes(), nil
+}
+
+func (r *ibsStateReader) ReadAccountCode(address common.Address, _ uint64, _ common.Hash) ([]byte, error) {
+	return r.ibs.GetCode(address), nil
+}
+
+func (r *ibsStateReader) ReadAccountCodeSize(address common.Address, _ uint64, _ common.Hash) (int, error) {
+	return r.ibs.GetCodeSize(address), nil
+}
+
+func (r *ibsStateReader) ReadAccountIncarnation(address common.Address) (uint64, error) {
+	return r.ibs.GetIncarnation(address), nil
+}
@@ -0,0 +1,48 @@
+package stages
+
+import (
+	"context"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIbsStateReader(t *testing.T) {
+	db := memdb.NewTestDB(t)
+	tx, err := db.BeginRw(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	account := common.HexToAddress("0x1")
+	contract := common.HexToAddress("0x2")
+	slot := common.HexToHash("0x3")
+
+	ibs := state.New(state.NewPlainStateReader(tx))
+	ibs.SetNonce(account, 5)
+	ibs.AddBalance(account, uint256.NewInt(100))
+	ibs.SetCode(contract, []byte{0x60, 0x00})
+	ibs.SetState(contract, &slot, *uint256.NewInt(7))
+
+	// the try of a bundle sees the block built so far
+	bundleIbs := state.New(&ibsStateReader{ibs: ibs})
+	require.Equal(t, uint64(5), bundleIbs.GetNonce(account))
+	require.Equal(t, uint256.NewInt(100), bundleIbs.GetBalance(account))
+	require.Equal(t, []byte{0x60, 0x00}, bundleIbs.GetCode(contract))
+	require.Equal(t, ibs.GetCodeHash(contract), bundleIbs.GetCodeHash(contract))
+
+	var value uint256.Int
+	bundleIbs.GetState(contract, &slot, &value)
+	require.Equal(t, uint64(7), value.Uint64())
+	require.False(t, bundleIbs.Exist(common.HexToAddress("0x4")))
+
+	// whilst changes made by it stay out of the block
+	bundleIbs.SetNonce(account, 6)
+	bundleIbs.SetState(contract, &slot, *uint256.NewInt(8))
+	require.Equal(t, uint64(5), ibs.GetNonce(account))
+	ibs.GetState(contract, &slot, &value)
+	require.Equal(t, uint64(7), value.Uint64())
+}
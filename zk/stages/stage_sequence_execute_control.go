@@ -0,0 +1,138 @@
+package stages
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ledgerwatch/erigon/eth/stagedsync"
+	"github.com/ledgerwatch/log/v3"
+)
+
+// tryPauseSequencer holds the sequencer before it starts on a batch for as long as it is paused or drained.  A drain
+// requested at this point only has to wait for the previous batch to be sealed in the datastream, unless the batch is
+// one left open by a restart that has to be completed first.
+func tryPauseSequencer(batchContext *BatchContext, batchState *BatchState, streamWriter *SequencerBatchStreamWriter, u stagedsync.Unwinder, latestBlock uint64) (bool, error) {
+	control := batchContext.cfg.sequencerControl
+
+	if control.PauseRequested() {
+		needsUnwind, err := waitWhilePaused(batchContext, batchState, streamWriter, u, latestBlock)
+		if needsUnwind || err != nil {
+			return needsUnwind, err
+		}
+	}
+
+	if !control.DrainRequested() {
+		return false, nil
+	}
+
+	// the latest block is in the last closed batch unless the batch it is in is resumed
+	lastClosedBatch, err := batchContext.sdb.hermezDb.GetBatchNoByL2Block(latestBlock)
+	if err != nil {
+		return false, err
+	}
+	if lastClosedBatch >= batchState.batchNumber {
+		log.Info(fmt.Sprintf("[%s] Draining sequencer once the resumed batch is closed", batchContext.s.LogPrefix()), "batch", batchState.batchNumber)
+		return false, nil
+	}
+
+	log.Info(fmt.Sprintf("[%s] Draining sequencer, checking for pending verifications", batchContext.s.LogPrefix()), "batch", lastClosedBatch)
+
+	needsUnwind, err := waitForPendingVerifications(batchContext, batchState, streamWriter, u)
+	if needsUnwind || err != nil {
+		return needsUnwind, err
+	}
+
+	if err := finalizeLastBatchInDatastreamIfNotFinalized(batchContext, lastClosedBatch, latestBlock); err != nil {
+		return false, err
+	}
+
+	control.SetDrained(lastClosedBatch, latestBlock)
+	log.Info(fmt.Sprintf("[%s] Sequencer drained", batchContext.s.LogPrefix()), "batch", lastClosedBatch, "block", latestBlock)
+
+	return waitWhileStopped(batchContext, batchState, streamWriter, u, control.DrainRequested)
+}
+
+// waitWhilePaused holds the sequencer after the given block for as long as it is paused, the open batch stays open
+func waitWhilePaused(batchContext *BatchContext, batchState *BatchState, streamWriter *SequencerBatchStreamWriter, u stagedsync.Unwinder, blockNumber uint64) (bool, error) {
+	control := batchContext.cfg.sequencerControl
+
+	control.SetPaused(batchState.batchNumber, blockNumber)
+	log.Info(fmt.Sprintf("[%s] Sequencer paused", batchContext.s.LogPrefix()), "batch", batchState.batchNumber, "block", blockNumber)
+
+	return waitWhileStopped(batchContext, batchState, streamWriter, u, control.PauseRequested)
+}
+
+// waitForSequencerLease holds the sequencer before it starts on a batch for as long as it does not hold the sequencer
+// lease.  Once it holds it for the first time, the batch the sequencer it took over from was streaming is closed.
+func waitForSequencerLease(batchContext *BatchContext, batchState *BatchState, streamWriter *SequencerBatchStreamWriter, u stagedsync.Unwinder, lastBatch, latestBlock uint64) (bool, error) {
+	lease := batchContext.cfg.sequencerLease
+	if lease == nil {
+		return false, nil
+	}
+
+	if !lease.Held() {
+		log.Info(fmt.Sprintf("[%s] Waiting for the sequencer lease", batchContext.s.LogPrefix()))
+		needsUnwind, err := waitWhileStopped(batchContext, batchState, streamWriter, u, func() bool { return !lease.Held() })
+		if needsUnwind || err != nil {
+			return needsUnwind, err
+		}
+	}
+
+	if batchContext.cfg.takenOverBatchClosed.Load() {
+		return false, nil
+	}
+
+	// an interrupted batch that is going to be completed is left open
+	if batchState.batchNumber > lastBatch {
+		needsUnwind, err := waitForPendingVerifications(batchContext, batchState, streamWriter, u)
+		if needsUnwind || err != nil {
+			return needsUnwind, err
+		}
+		if err := finalizeLastBatchInDatastreamIfNotFinalized(batchContext, lastBatch, latestBlock); err != nil {
+			return false, err
+		}
+	}
+	batchContext.cfg.takenOverBatchClosed.Store(true)
+
+	return false, nil
+}
+
+// waitWhileSequencerLeaseLost holds the sequencer after the given block once it lost its lease.  The node that got it
+// carries on from the datastream so the open batch is left as it is.
+func waitWhileSequencerLeaseLost(batchContext *BatchContext, batchState *BatchState, streamWriter *SequencerBatchStreamWriter, u stagedsync.Unwinder, blockNumber uint64) (bool, error) {
+	lease := batchContext.cfg.sequencerLease
+
+	log.Error(fmt.Sprintf("[%s] Sequencer lease lost, stopped producing blocks", batchContext.s.LogPrefix()), "batch", batchState.batchNumber, "block", blockNumber)
+
+	return waitWhileStopped(batchContext, batchState, streamWriter, u, func() bool { return !lease.Held() })
+}
+
+// waitWhileStopped blocks until stopped returns false.  Verifier responses for the blocks built so far keep being
+// written to the datastream in the meantime.
+func waitWhileStopped(batchContext *BatchContext, batchState *BatchState, streamWriter *SequencerBatchStreamWriter, u stagedsync.Unwinder, stopped func() bool) (bool, error) {
+	control := batchContext.cfg.sequencerControl
+
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		changed := control.Changed()
+		if !stopped() {
+			break
+		}
+
+		select {
+		case <-batchContext.ctx.Done():
+			return false, batchContext.ctx.Err()
+		case <-changed:
+		case <-ticker.C:
+			needsUnwind, err := updateStreamAndCheckRollback(batchContext, batchState, streamWriter, u)
+			if needsUnwind || err != nil {
+				return needsUnwind, err
+			}
+		}
+	}
+
+	log.Info(fmt.Sprintf("[%s] Sequencer halt state changed", batchContext.s.LogPrefix()), "state", control.Status().State)
+	return false, nil
+}
@@ -0,0 +1,54 @@
+package stages
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/stagedsync"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/zk/hermez_db"
+	"github.com/ledgerwatch/erigon/zk/sequencer"
+	zktypes "github.com/ledgerwatch/erigon/zk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DrainResumedBatch(t *testing.T) {
+	// the sequencer stopped after building the first block of a forced batch of three
+	ft := setupForcedBatchSequencingTest(t, 21, 101, func(tx kv.RwTx, hDB *hermez_db.HermezDb) {
+		require.NoError(t, hDB.WriteL1ForcedBatch(&zktypes.L1ForcedBatch{
+			ForcedBatchNumber: 1,
+			Transactions:      generateForcedBatchL2Data(t, [][]types.Transaction{{}, {}, {}}),
+		}))
+		require.NoError(t, hDB.WriteBatchForcedBatch(21, 1))
+		require.NoError(t, hDB.WriteBlockBatch(101, 21))
+	})
+	ft.dataStreamServer.EXPECT().IsLastEntryBatchEnd().Return(true, nil).AnyTimes()
+	control := sequencer.NewControl()
+	ft.cfg.sequencerControl = control
+	require.NoError(t, control.Drain())
+
+	// the resumed batch is completed before the sequencer drains
+	ft.run(t, 21)
+	require.Equal(t, sequencer.HaltStateDraining, control.Status().State)
+
+	tx := memdb.BeginRw(t, ft.db)
+	blocks, err := hermez_db.NewHermezDb(tx).GetL2BlockNosByBatch(21)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{101, 102, 103}, blocks)
+	tx.Rollback()
+
+	// then drains on the batch it closed
+	ctx, cancel := context.WithCancel(ft.ctx)
+	changed := control.Changed()
+	go func() {
+		<-changed
+		cancel()
+	}()
+	s := &stagedsync.StageState{ID: stages.HighestSeenBatchNumber, BlockNumber: 21}
+	require.ErrorIs(t, sequencingBatchStep(s, &stagedsync.Sync{}, ctx, ft.cfg, ft.historyCfg, nil), context.Canceled)
+
+	require.Equal(t, sequencer.ControlStatus{State: sequencer.HaltStateDrained, BatchNumber: 21, BlockNumber: 103}, control.Status())
+}
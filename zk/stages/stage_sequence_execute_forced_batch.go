@@ -0,0 +1,155 @@
+package stages
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/zk/hermez_db"
+	zktx "github.com/ledgerwatch/erigon/zk/tx"
+	zktypes "github.com/ledgerwatch/erigon/zk/types"
+	"github.com/ledgerwatch/log/v3"
+)
+
+// TYPE FORCED BATCH DATA
+type ForcedBatchData struct {
+	forcedBatch   *zktypes.L1ForcedBatch
+	decodedBlocks []zktx.DecodedBatchL2Data
+	blockIndex    uint64
+}
+
+func newForcedBatchData(forcedBatch *zktypes.L1ForcedBatch, forkId uint64) *ForcedBatchData {
+	decodedBlocks, err := zktx.DecodeBatchL2Blocks(forcedBatch.Transactions, forkId)
+	if err != nil {
+		// the data posted to the L1 is not under our control, so if it cannot be decoded we still have to
+		// consume the forced batch, it simply results in an empty block
+		log.Warn("[forcedBatch] could not decode forced batch data, including it as an empty block", "forcedBatch", forcedBatch.ForcedBatchNumber, "err", err)
+		decodedBlocks = nil
+	}
+
+	if len(decodedBlocks) == 0 {
+		decodedBlocks = []zktx.DecodedBatchL2Data{{Transactions: []types.Transaction{}}}
+	}
+
+	return &ForcedBatchData{
+		forcedBatch:   forcedBatch,
+		decodedBlocks: decodedBlocks,
+	}
+}
+
+func (forcedBatchData *ForcedBatchData) getDecodedBlockByIndex(decodedBlocksIndex uint64) (*zktx.DecodedBatchL2Data, bool) {
+	if decodedBlocksIndex >= uint64(len(forcedBatchData.decodedBlocks)) {
+		return nil, false
+	}
+
+	return &forcedBatchData.decodedBlocks[decodedBlocksIndex], true
+}
+
+// getL1InfoTreeUpdate returns the GER and L1 block hash the forced batch was bound to on the L1.  They are only applied
+// to the first block of the batch as forced batches do not reference an info tree index
+func (forcedBatchData *ForcedBatchData) getL1InfoTreeUpdate() *zktypes.L1InfoTreeUpdate {
+	if forcedBatchData.blockIndex != 0 || forcedBatchData.forcedBatch.LastGlobalExitRoot == (common.Hash{}) {
+		return nil
+	}
+
+	return &zktypes.L1InfoTreeUpdate{
+		GER:        forcedBatchData.forcedBatch.LastGlobalExitRoot,
+		ParentHash: forcedBatchData.forcedBatch.ForcedBlockHashL1,
+		Timestamp:  forcedBatchData.forcedBatch.ForcedAt,
+	}
+}
+
+// getNextForcedBatchForInclusion returns the next forced batch in sequence if it has passed the forced batch timeout, nil otherwise
+func getNextForcedBatchForInclusion(hermezDb *hermez_db.HermezDbReader, timeout time.Duration, now time.Time) (*zktypes.L1ForcedBatch, error) {
+	latestIncluded, err := hermezDb.GetLatestIncludedForcedBatch()
+	if err != nil {
+		return nil, err
+	}
+
+	forcedBatch, err := hermezDb.GetL1ForcedBatch(latestIncluded + 1)
+	if err != nil {
+		return nil, err
+	}
+	if forcedBatch == nil {
+		return nil, nil
+	}
+
+	includeAfter := time.Unix(int64(forcedBatch.ForcedAt), 0).Add(timeout)
+	if now.Before(includeAfter) {
+		return nil, nil
+	}
+
+	return forcedBatch, nil
+}
+
+// isForcedBatchIncomplete checks if the batch includes a forced batch of which not all blocks have been built yet
+func isForcedBatchIncomplete(sdb *stageDb, forkId, batchNo uint64) (bool, error) {
+	forcedBatch, err := getBatchForcedBatch(sdb.hermezDb.HermezDbReader, batchNo)
+	if err != nil || forcedBatch == nil {
+		return false, err
+	}
+
+	blockNumbersInBatchSoFar, err := sdb.hermezDb.GetL2BlockNosByBatch(batchNo)
+	if err != nil {
+		return false, err
+	}
+
+	return len(blockNumbersInBatchSoFar) < len(newForcedBatchData(forcedBatch, forkId).decodedBlocks), nil
+}
+
+func getBatchForcedBatch(hermezDb *hermez_db.HermezDbReader, batchNo uint64) (*zktypes.L1ForcedBatch, error) {
+	forcedBatchNo, found, err := hermezDb.GetBatchForcedBatch(batchNo)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	forcedBatch, err := hermezDb.GetL1ForcedBatch(forcedBatchNo)
+	if err != nil {
+		return nil, err
+	}
+	if forcedBatch == nil {
+		return nil, fmt.Errorf("forced batch %d included in batch %d not found", forcedBatchNo, batchNo)
+	}
+
+	return forcedBatch, nil
+}
+
+// prepareForcedBatch checks for a forced batch that is due for inclusion and, if found, turns the current batch into it.
+// A forced batch that was already started in this batch before a restart is resumed regardless of the config.
+func prepareForcedBatch(batchContext *BatchContext, batchState *BatchState) error {
+	hermezDb := batchContext.sdb.hermezDb
+
+	forcedBatch, err := getBatchForcedBatch(hermezDb.HermezDbReader, batchState.batchNumber)
+	if err != nil {
+		return err
+	}
+	if forcedBatch != nil {
+		batchState.forcedBatchData = newForcedBatchData(forcedBatch, batchState.forkId)
+		log.Info(fmt.Sprintf("[%s] Resuming forced batch", batchContext.s.LogPrefix()), "batch", batchState.batchNumber, "forcedBatch", forcedBatch.ForcedBatchNumber, "blocks", len(batchState.forcedBatchData.decodedBlocks))
+		return nil
+	}
+
+	if !batchContext.cfg.zk.SequencerForcedBatches {
+		return nil
+	}
+
+	if forcedBatch, err = getNextForcedBatchForInclusion(hermezDb.HermezDbReader, batchContext.cfg.zk.SequencerForcedBatchTimeout, time.Now()); err != nil {
+		return err
+	}
+	if forcedBatch == nil {
+		return nil
+	}
+
+	// committed together with the first block of the batch, a restart after that resumes the forced batch
+	// in prepareBatchNumber until all of its blocks are built
+	if err = hermezDb.WriteBatchForcedBatch(batchState.batchNumber, forcedBatch.ForcedBatchNumber); err != nil {
+		return err
+	}
+
+	batchState.forcedBatchData = newForcedBatchData(forcedBatch, batchState.forkId)
+
+	log.Info(fmt.Sprintf("[%s] Including forced batch", batchContext.s.LogPrefix()), "batch", batchState.batchNumber, "forcedBatch", forcedBatch.ForcedBatchNumber, "blocks", len(batchState.forcedBatchData.decodedBlocks))
+
+	return nil
+}
@@ -0,0 +1,435 @@
+package stages
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/chain"
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	cMocks "github.com/ledgerwatch/erigon-lib/kv/kvcache/mocks"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon-lib/txpool/txpoolcfg"
+	"github.com/ledgerwatch/erigon/consensus"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/eth/stagedsync"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/ethdb/prune"
+	"github.com/ledgerwatch/erigon/smt/pkg/db"
+	dsMocks "github.com/ledgerwatch/erigon/zk/datastream/mocks"
+	"github.com/ledgerwatch/erigon/zk/hermez_db"
+	"github.com/ledgerwatch/erigon/zk/l1infotree"
+	verifier "github.com/ledgerwatch/erigon/zk/legacy_executor_verifier"
+	"github.com/ledgerwatch/erigon/zk/syncer"
+	"github.com/ledgerwatch/erigon/zk/syncer/mocks"
+	zktx "github.com/ledgerwatch/erigon/zk/tx"
+	"github.com/ledgerwatch/erigon/zk/txpool"
+	zktypes "github.com/ledgerwatch/erigon/zk/types"
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_GetNextForcedBatchForInclusion(t *testing.T) {
+	db := memdb.NewTestDB(t)
+	tx := memdb.BeginRw(t, db)
+	require.NoError(t, hermez_db.CreateHermezBuckets(tx))
+	hermezDb := hermez_db.NewHermezDb(tx)
+
+	timeout := 5 * time.Minute
+	forcedAt := time.Unix(1_700_000_000, 0)
+
+	// nothing stored yet
+	forcedBatch, err := getNextForcedBatchForInclusion(hermezDb.HermezDbReader, timeout, forcedAt)
+	require.NoError(t, err)
+	require.Nil(t, forcedBatch)
+
+	require.NoError(t, hermezDb.WriteL1ForcedBatch(&zktypes.L1ForcedBatch{ForcedBatchNumber: 1, ForcedAt: uint64(forcedAt.Unix())}))
+	require.NoError(t, hermezDb.WriteL1ForcedBatch(&zktypes.L1ForcedBatch{ForcedBatchNumber: 2, ForcedAt: uint64(forcedAt.Unix())}))
+
+	// timeout not yet reached
+	forcedBatch, err = getNextForcedBatchForInclusion(hermezDb.HermezDbReader, timeout, forcedAt.Add(timeout-time.Second))
+	require.NoError(t, err)
+	require.Nil(t, forcedBatch)
+
+	forcedBatch, err = getNextForcedBatchForInclusion(hermezDb.HermezDbReader, timeout, forcedAt.Add(timeout))
+	require.NoError(t, err)
+	require.NotNil(t, forcedBatch)
+	require.Equal(t, uint64(1), forcedBatch.ForcedBatchNumber)
+
+	// once included the next one in sequence is returned
+	require.NoError(t, hermezDb.WriteBatchForcedBatch(10, 1))
+	forcedBatch, err = getNextForcedBatchForInclusion(hermezDb.HermezDbReader, timeout, forcedAt.Add(timeout))
+	require.NoError(t, err)
+	require.NotNil(t, forcedBatch)
+	require.Equal(t, uint64(2), forcedBatch.ForcedBatchNumber)
+
+	require.NoError(t, hermezDb.WriteBatchForcedBatch(11, 2))
+	forcedBatch, err = getNextForcedBatchForInclusion(hermezDb.HermezDbReader, timeout, forcedAt.Add(timeout))
+	require.NoError(t, err)
+	require.Nil(t, forcedBatch)
+}
+
+func Test_NewForcedBatchDataUndecodable(t *testing.T) {
+	for name, transactions := range map[string][]byte{
+		"invalid transaction":           {0xff, 0x01},
+		"change l2 block without delta": {0x0b},
+		"truncated change l2 block":     {0x0b, 0, 0},
+		"truncated second block":        {0x0b, 0, 0, 0, 1, 0, 0, 0, 0, 0x0b, 0},
+	} {
+		t.Run(name, func(t *testing.T) {
+			forcedBatchData := newForcedBatchData(&zktypes.L1ForcedBatch{ForcedBatchNumber: 1, Transactions: transactions}, 9)
+
+			block, found := forcedBatchData.getDecodedBlockByIndex(0)
+			require.True(t, found)
+			require.Empty(t, block.Transactions)
+
+			_, found = forcedBatchData.getDecodedBlockByIndex(1)
+			require.False(t, found)
+		})
+	}
+}
+
+type forcedBatchSequencingTest struct {
+	ctx              context.Context
+	db               kv.RwDB
+	cfg              SequenceBlockCfg
+	historyCfg       stagedsync.HistoryCfg
+	dataStreamServer *dsMocks.MockDataStreamServer
+}
+
+// setupForcedBatchSequencingTest prepares a sequencer at batch 20 / block 100 with forced batches enabled.  prepare is
+// called before the setup is committed to add the forced batch data and any progress made before a restart.
+func setupForcedBatchSequencingTest(t *testing.T, lastBatch, executionAt uint64, prepare func(tx kv.RwTx, hDB *hermez_db.HermezDb)) *forcedBatchSequencingTest {
+	ctx, db1, txPoolDb := context.Background(), memdb.NewTestDB(t), memdb.NewTestDB(t)
+	tx := memdb.BeginRw(t, db1)
+	require.NoError(t, hermez_db.CreateHermezBuckets(tx))
+	require.NoError(t, db.CreateEriDbBuckets(tx))
+
+	chainID := *uint256.NewInt(1)
+	forkID := uint64(11)
+
+	hDB := hermez_db.NewHermezDb(tx)
+	require.NoError(t, hDB.WriteForkId(20, forkID))
+	require.NoError(t, hDB.WriteNewForkHistory(forkID, 20))
+	require.NoError(t, hDB.WriteForkIdBlockOnce(forkID, 1))
+	require.NoError(t, stages.SaveStageProgress(tx, stages.HighestSeenBatchNumber, lastBatch))
+	require.NoError(t, stages.SaveStageProgress(tx, stages.Execution, executionAt))
+
+	parentHash := common.HexToHash("0x123456789")
+	for blockNumber := uint64(100); blockNumber <= executionAt; blockNumber++ {
+		block := types.NewBlockWithHeader(&types.Header{ParentHash: parentHash, Number: new(big.Int).SetUint64(blockNumber), Time: uint64(time.Now().Unix())})
+		require.NoError(t, rawdb.WriteBlock(tx, block))
+		require.NoError(t, rawdb.WriteCanonicalHash(tx, block.Hash(), blockNumber))
+		parentHash = block.Hash()
+	}
+
+	prepare(tx, hDB)
+	require.NoError(t, tx.Commit())
+
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	dataStreamServerMock := dsMocks.NewMockDataStreamServer(mockCtrl)
+	ethermanMock := mocks.NewMockIEtherman(mockCtrl)
+	engineMock := consensus.NewMockEngine(mockCtrl)
+
+	dataStreamServerMock.EXPECT().GetHighestBatchNumber().Return(lastBatch, nil).AnyTimes()
+	dataStreamServerMock.EXPECT().GetHighestClosedBatch().Return(uint64(20), nil).AnyTimes()
+	dataStreamServerMock.EXPECT().GetHighestBlockNumber().Return(executionAt, nil).AnyTimes()
+	dataStreamServerMock.EXPECT().
+		WriteBlockWithBatchStartToStream(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+	dataStreamServerMock.EXPECT().WriteBatchEnd(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	latestL1Block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(100), Time: uint64(time.Now().Unix())})
+	ethermanMock.EXPECT().BlockByNumber(gomock.Any(), nil).Return(latestL1Block, nil).AnyTimes()
+
+	l1Syncer := syncer.NewL1Syncer(ctx, []syncer.IEtherman{ethermanMock}, []common.Address{common.HexToAddress("0x1")}, [][]common.Hash{{common.HexToHash("0x1")}}, 10, 0, "latest")
+	updater := l1infotree.NewUpdater(&ethconfig.Zk{}, l1Syncer)
+
+	cacheMock := cMocks.NewMockCache(mockCtrl)
+	cacheMock.EXPECT().View(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+
+	txPool, err := txpool.New(nil, txPoolDb, txpoolcfg.Config{}, &ethconfig.Config{}, cacheMock, chainID, nil, nil, nil)
+	require.NoError(t, err)
+
+	engineMock.EXPECT().Type().Return(chain.CliqueConsensus).AnyTimes()
+	engineMock.EXPECT().IsServiceTransaction(gomock.Any(), gomock.Any()).Return(false).AnyTimes()
+	engineMock.EXPECT().
+		FinalizeAndAssemble(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(config *chain.Config, header *types.Header, state *state.IntraBlockState, txs types.Transactions, uncles []*types.Header, receipts types.Receipts, withdrawals []*types.Withdrawal, chain consensus.ChainReader, syscall consensus.SystemCall, call consensus.Call, logger log.Logger) (*types.Block, types.Transactions, types.Receipts, error) {
+			return types.NewBlockWithHeader(header), txs, receipts, nil
+		}).
+		AnyTimes()
+
+	zkCfg := &ethconfig.Zk{
+		SequencerBatchSealTime:      10 * time.Second,
+		SequencerBlockSealTime:      10 * time.Second,
+		InfoTreeUpdateInterval:      10 * time.Second,
+		SequencerForcedBatches:      true,
+		SequencerForcedBatchTimeout: time.Second,
+	}
+
+	return &forcedBatchSequencingTest{
+		ctx: ctx,
+		db:  db1,
+		cfg: SequenceBlockCfg{
+			dataStreamServer: dataStreamServerMock,
+			db:               db1,
+			zk:               zkCfg,
+			infoTreeUpdater:  updater,
+			txPool:           txPool,
+			chainConfig:      &chain.Config{ChainID: chainID.ToBig()},
+			txPoolDb:         txPoolDb,
+			engine:           engineMock,
+			legacyVerifier:   verifier.NewLegacyExecutorVerifier(*zkCfg, nil, db1, nil, nil),
+			zkVmConfig:       &vm.ZkConfig{},
+		},
+		historyCfg:       stagedsync.StageHistoryCfg(db1, prune.DefaultMode, ""),
+		dataStreamServer: dataStreamServerMock,
+	}
+}
+
+func (ft *forcedBatchSequencingTest) run(t *testing.T, lastBatch uint64) {
+	s := &stagedsync.StageState{ID: stages.HighestSeenBatchNumber, BlockNumber: lastBatch}
+	require.NoError(t, sequencingBatchStep(s, &stagedsync.Sync{}, ft.ctx, ft.cfg, ft.historyCfg, nil))
+}
+
+func generateForcedBatchL2Data(t *testing.T, blocksTransactions [][]types.Transaction) []byte {
+	var batchL2Data []byte
+	for _, transactions := range blocksTransactions {
+		batchL2Data = append(batchL2Data, zktx.GenerateStartBlockBatchL2Data(0, 0)...)
+		for _, transaction := range transactions {
+			txData, err := zktx.TransactionToL2Data(transaction, 11, zktx.MaxEffectivePercentage)
+			require.NoError(t, err)
+			batchL2Data = append(batchL2Data, txData...)
+		}
+	}
+	return batchL2Data
+}
+
+func Test_SequencingForcedBatchMultipleBlocks(t *testing.T) {
+	forcedGer := common.HexToHash("0xf1")
+	forcedBlockHashL1 := common.HexToHash("0xf2")
+
+	ft := setupForcedBatchSequencingTest(t, 20, 100, func(tx kv.RwTx, hDB *hermez_db.HermezDb) {
+		require.NoError(t, hDB.WriteL1ForcedBatch(&zktypes.L1ForcedBatch{
+			ForcedBatchNumber:  1,
+			LastGlobalExitRoot: forcedGer,
+			ForcedBlockHashL1:  forcedBlockHashL1,
+			Transactions:       generateForcedBatchL2Data(t, [][]types.Transaction{{}, {}, {}}),
+		}))
+	})
+
+	ft.run(t, 20)
+
+	tx := memdb.BeginRw(t, ft.db)
+	defer tx.Rollback()
+	hDB := hermez_db.NewHermezDb(tx)
+
+	blocks, err := hDB.GetL2BlockNosByBatch(21)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{101, 102, 103}, blocks)
+
+	forcedBatchNo, found, err := hDB.GetBatchForcedBatch(21)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, uint64(1), forcedBatchNo)
+
+	// the forced GER and L1 block hash are applied to the first block only
+	ger, err := hDB.GetBlockGlobalExitRoot(101)
+	require.NoError(t, err)
+	require.Equal(t, forcedGer, ger)
+	l1BlockHash, err := hDB.GetBlockL1BlockHash(101)
+	require.NoError(t, err)
+	require.Equal(t, forcedBlockHashL1, l1BlockHash)
+
+	ger, err = hDB.GetBlockGlobalExitRoot(102)
+	require.NoError(t, err)
+	require.Equal(t, common.Hash{}, ger)
+
+	executionAt, err := stages.GetStageProgress(tx, stages.Execution)
+	require.NoError(t, err)
+	require.Equal(t, uint64(103), executionAt)
+}
+
+func Test_SequencingForcedBatchOverflow(t *testing.T) {
+	chainConfig := &chain.Config{ChainID: big.NewInt(1)}
+	signer := types.MakeSigner(chainConfig, 101, 0)
+
+	// every block carries a transaction with 40KB of data so the batch goes over the 120KB batch data limit
+	blocksTransactions := make([][]types.Transaction, 4)
+	for i := range blocksTransactions {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		data := make([]byte, 40*1024)
+		transaction, err := types.SignTx(types.NewTransaction(0, common.HexToAddress("0x1234"), uint256.NewInt(0), 1_000_000, uint256.NewInt(0), data), *signer, key)
+		require.NoError(t, err)
+		blocksTransactions[i] = []types.Transaction{transaction}
+	}
+
+	ft := setupForcedBatchSequencingTest(t, 20, 100, func(tx kv.RwTx, hDB *hermez_db.HermezDb) {
+		require.NoError(t, hDB.WriteL1ForcedBatch(&zktypes.L1ForcedBatch{
+			ForcedBatchNumber: 1,
+			Transactions:      generateForcedBatchL2Data(t, blocksTransactions),
+		}))
+	})
+
+	ft.run(t, 20)
+
+	tx := memdb.BeginRw(t, ft.db)
+	defer tx.Rollback()
+	hDB := hermez_db.NewHermezDb(tx)
+
+	// the forced batch is included as a whole
+	blocks, err := hDB.GetL2BlockNosByBatch(21)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{101, 102, 103, 104}, blocks)
+
+	for i, blockNumber := range blocks {
+		block, err := rawdb.ReadBlockByNumber(tx, blockNumber)
+		require.NoError(t, err)
+		require.Len(t, block.Transactions(), 1)
+		require.Equal(t, blocksTransactions[i][0].Hash(), block.Transactions()[0].Hash())
+	}
+}
+
+func Test_SequencingForcedBatchRestart(t *testing.T) {
+	forcedGer := common.HexToHash("0xf1")
+
+	// the sequencer stopped after building the first block of the forced batch
+	ft := setupForcedBatchSequencingTest(t, 21, 101, func(tx kv.RwTx, hDB *hermez_db.HermezDb) {
+		require.NoError(t, hDB.WriteL1ForcedBatch(&zktypes.L1ForcedBatch{
+			ForcedBatchNumber:  1,
+			LastGlobalExitRoot: forcedGer,
+			Transactions:       generateForcedBatchL2Data(t, [][]types.Transaction{{}, {}, {}}),
+		}))
+		require.NoError(t, hDB.WriteL1ForcedBatch(&zktypes.L1ForcedBatch{
+			ForcedBatchNumber: 2,
+			Transactions:      generateForcedBatchL2Data(t, [][]types.Transaction{{}}),
+		}))
+		require.NoError(t, hDB.WriteBatchForcedBatch(21, 1))
+		require.NoError(t, hDB.WriteBlockBatch(101, 21))
+		require.NoError(t, hDB.WriteBlockGlobalExitRoot(101, forcedGer))
+	})
+
+	ft.run(t, 21)
+
+	tx := memdb.BeginRw(t, ft.db)
+	hDB := hermez_db.NewHermezDb(tx)
+
+	blocks, err := hDB.GetL2BlockNosByBatch(21)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{101, 102, 103}, blocks)
+
+	// the resumed blocks do not apply the forced GER again
+	ger, err := hDB.GetBlockGlobalExitRoot(102)
+	require.NoError(t, err)
+	require.Equal(t, common.Hash{}, ger)
+
+	latestIncluded, err := hDB.GetLatestIncludedForcedBatch()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), latestIncluded)
+	tx.Rollback()
+
+	// once complete the next forced batch goes into a new batch
+	ft.run(t, 21)
+
+	tx = memdb.BeginRw(t, ft.db)
+	defer tx.Rollback()
+	hDB = hermez_db.NewHermezDb(tx)
+
+	blocks, err = hDB.GetL2BlockNosByBatch(22)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{104}, blocks)
+
+	forcedBatchNo, found, err := hDB.GetBatchForcedBatch(22)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, uint64(2), forcedBatchNo)
+}
+
+func Test_PrepareBatchNumberResumesForcedBatch(t *testing.T) {
+	db1 := memdb.NewTestDB(t)
+	tx := memdb.BeginRw(t, db1)
+	defer tx.Rollback()
+	require.NoError(t, hermez_db.CreateHermezBuckets(tx))
+	hDB := hermez_db.NewHermezDb(tx)
+	sdb := &stageDb{tx: tx, hermezDb: hDB}
+
+	require.NoError(t, hDB.WriteL1ForcedBatch(&zktypes.L1ForcedBatch{
+		ForcedBatchNumber: 1,
+		Transactions:      generateForcedBatchL2Data(t, [][]types.Transaction{{}, {}}),
+	}))
+
+	// a regular batch is never resumed
+	require.NoError(t, hDB.WriteBlockBatch(101, 21))
+	batchNo, err := prepareBatchNumber(sdb, 11, 21, false)
+	require.NoError(t, err)
+	require.Equal(t, uint64(22), batchNo)
+
+	// a forced batch with blocks left to build is resumed
+	require.NoError(t, hDB.WriteBatchForcedBatch(21, 1))
+	batchNo, err = prepareBatchNumber(sdb, 11, 21, false)
+	require.NoError(t, err)
+	require.Equal(t, uint64(21), batchNo)
+
+	require.NoError(t, hDB.WriteBlockBatch(102, 21))
+	batchNo, err = prepareBatchNumber(sdb, 11, 21, false)
+	require.NoError(t, err)
+	require.Equal(t, uint64(22), batchNo)
+}
+
+func Test_SequencingForcedBatchTruncated(t *testing.T) {
+	// anyone can force a batch, so the data can be cut short in the middle of a change l2 block
+	truncated := []byte{0x0b, 0, 0}
+
+	ft := setupForcedBatchSequencingTest(t, 20, 100, func(tx kv.RwTx, hDB *hermez_db.HermezDb) {
+		require.NoError(t, hDB.WriteL1ForcedBatch(&zktypes.L1ForcedBatch{ForcedBatchNumber: 1, Transactions: truncated}))
+	})
+
+	ft.run(t, 20)
+
+	tx := memdb.BeginRw(t, ft.db)
+	hDB := hermez_db.NewHermezDb(tx)
+
+	// the forced batch is consumed as an empty block
+	blocks, err := hDB.GetL2BlockNosByBatch(21)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{101}, blocks)
+
+	block, err := rawdb.ReadBlockByNumber(tx, 101)
+	require.NoError(t, err)
+	require.Empty(t, block.Transactions())
+
+	latestIncluded, err := hDB.GetLatestIncludedForcedBatch()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), latestIncluded)
+	tx.Rollback()
+
+	// and a restart with it in the last batch resumes instead of failing on it again
+	ft = setupForcedBatchSequencingTest(t, 21, 101, func(tx kv.RwTx, hDB *hermez_db.HermezDb) {
+		require.NoError(t, hDB.WriteL1ForcedBatch(&zktypes.L1ForcedBatch{ForcedBatchNumber: 1, Transactions: truncated}))
+		require.NoError(t, hDB.WriteBatchForcedBatch(21, 1))
+		require.NoError(t, hDB.WriteBlockBatch(101, 21))
+	})
+
+	ft.run(t, 21)
+
+	tx = memdb.BeginRw(t, ft.db)
+	defer tx.Rollback()
+	hDB = hermez_db.NewHermezDb(tx)
+
+	blocks, err = hDB.GetL2BlockNosByBatch(21)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{101}, blocks)
+}
@@ -88,7 +88,7 @@ func processInjectedInitialBatch(
 
 	parentRoot := parentBlock.Root()
 	if err = handleStateForNewBlockStarting(batchContext, ibs, injectedBatchBlockNumber,
-		injectedBatchBatchNumber, injectedBatch.Timestamp, &parentRoot, fakeL1TreeUpdate, true); err != nil {
+		injectedBatchBatchNumber, injectedBatch.Timestamp, &parentRoot, fakeL1TreeUpdate, true, false); err != nil {
 		return err
 	}
 
@@ -138,7 +138,7 @@ func handleInjectedBatch(
 
 	// process the tx and we can ignore the counters as an overflow at this stage means no network anyway
 	effectiveGas := DeriveEffectiveGasPrice(*batchContext.cfg, decodedBlocks[0].Transactions[0])
-	receipt, execResult, _, err := attemptAddTransaction(*batchContext.cfg, batchContext.sdb, ibs, batchCounters, blockContext, header, decodedBlocks[0].Transactions[0], effectiveGas, false, forkId, 0 /* use 0 for l1InfoIndex in injected batch */, nil)
+	receipt, execResult, _, err := attemptAddTransaction(*batchContext.cfg, batchContext.sdb, ibs, batchCounters, blockContext, header, decodedBlocks[0].Transactions[0], effectiveGas, false, forkId, 0 /* use 0 for l1InfoIndex in injected batch */, nil, 0, 0)
 	if err != nil {
 		return nil, nil, nil, 0, err
 	}
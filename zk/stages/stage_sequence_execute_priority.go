@@ -0,0 +1,62 @@
+package stages
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/log/v3"
+)
+
+// priorityReserve returns the share of the batch counters and gas the transactions may not use.  That is nothing when
+// they are all priority transactions, once the reserve has been released or when the batch is rebuilt from data that
+// has to be included as it is.
+func priorityReserve(ctx context.Context, cfg SequenceBlockCfg, batchState *BatchState, transactions ...types.Transaction) float64 {
+	reserve := cfg.zk.SequencerPriorityReserve
+	if reserve == 0 || batchState.isAnyRecovery() {
+		return 0
+	}
+	if release := cfg.zk.SequencerPriorityReserveRelease; release > 0 && time.Since(batchState.startedAt) >= release {
+		return 0
+	}
+
+	for _, transaction := range transactions {
+		if !isPriorityTransaction(ctx, cfg, transaction) {
+			return reserve
+		}
+	}
+
+	return 0
+}
+
+// isPriorityTransaction is true for the transactions from or to a priority address, calling a priority method or sent
+// by an address with the priority ACL policy
+func isPriorityTransaction(ctx context.Context, cfg SequenceBlockCfg, transaction types.Transaction) bool {
+	sender, _ := transaction.GetSender()
+	to := transaction.GetTo()
+	for _, address := range cfg.zk.SequencerPriorityAddresses {
+		if address == sender || (to != nil && address == *to) {
+			return true
+		}
+	}
+
+	if data := transaction.GetData(); len(data) >= 4 {
+		for _, selector := range cfg.zk.SequencerPrioritySelectors {
+			if bytes.Equal(data[:4], selector[:]) {
+				return true
+			}
+		}
+	}
+
+	if cfg.zk.SequencerPriorityAcl {
+		hasPriority, err := cfg.txPool.HasPriority(ctx, sender)
+		if err != nil {
+			log.Warn("[sequencer] Failed to read the priority policy of the sender", "sender", sender, "err", err)
+			return false
+		}
+		return hasPriority
+	}
+
+	return false
+}
@@ -0,0 +1,134 @@
+package stages
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/chain"
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon/consensus"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	eridb "github.com/ledgerwatch/erigon/smt/pkg/db"
+	"github.com/ledgerwatch/erigon/zk/hermez_db"
+	zktx "github.com/ledgerwatch/erigon/zk/tx"
+	"github.com/ledgerwatch/erigon/zk/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPriorityReserve(t *testing.T) {
+	ctx := context.Background()
+	bridge := common.HexToAddress("0x2a3dd3eb832af982ec71669e178424b10dca2ede")
+	system := common.HexToAddress("0x1000")
+	user := common.HexToAddress("0x2000")
+
+	cfg := SequenceBlockCfg{zk: &ethconfig.Zk{
+		SequencerPriorityReserve:        0.2,
+		SequencerPriorityAddresses:      []common.Address{bridge, system},
+		SequencerPrioritySelectors:      [][4]byte{{0xcc, 0xaa, 0x2d, 0x11}},
+		SequencerPriorityReserveRelease: time.Minute,
+	}}
+	batchState := &BatchState{startedAt: time.Now()}
+
+	newTx := func(from, to common.Address, data []byte) types.Transaction {
+		tx := types.NewTransaction(0, to, uint256.NewInt(0), 21000, uint256.NewInt(1), data)
+		tx.SetSender(from)
+		return tx
+	}
+	toBridge := newTx(user, bridge, nil)
+	fromSystem := newTx(system, user, nil)
+	claim := newTx(user, common.HexToAddress("0x3000"), []byte{0xcc, 0xaa, 0x2d, 0x11, 0x01})
+	transfer := newTx(user, common.HexToAddress("0x3000"), []byte{0xa9, 0x05, 0x9c, 0xbb})
+
+	require.Equal(t, 0.0, priorityReserve(ctx, cfg, batchState, toBridge))
+	require.Equal(t, 0.0, priorityReserve(ctx, cfg, batchState, fromSystem))
+	require.Equal(t, 0.0, priorityReserve(ctx, cfg, batchState, claim))
+	require.Equal(t, 0.2, priorityReserve(ctx, cfg, batchState, transfer))
+
+	// a bundle is only a priority one as a whole
+	require.Equal(t, 0.0, priorityReserve(ctx, cfg, batchState, toBridge, claim))
+	require.Equal(t, 0.2, priorityReserve(ctx, cfg, batchState, toBridge, transfer))
+
+	// the reserve is released a while into the batch
+	batchState.startedAt = time.Now().Add(-2 * time.Minute)
+	require.Equal(t, 0.0, priorityReserve(ctx, cfg, batchState, transfer))
+
+	// nothing is reserved when rebuilding a batch
+	batchState.startedAt = time.Now()
+	batchState.resequenceBatchJob = &ResequenceBatchJob{}
+	require.Equal(t, 0.0, priorityReserve(ctx, cfg, batchState, transfer))
+}
+
+func TestPriorityReserveGas(t *testing.T) {
+	ctx := context.Background()
+	db := memdb.NewTestDB(t)
+	sdb, err := newStageDb(ctx, db)
+	require.NoError(t, err)
+	defer sdb.tx.Rollback()
+	require.NoError(t, hermez_db.CreateHermezBuckets(sdb.tx))
+	require.NoError(t, eridb.CreateEriDbBuckets(sdb.tx))
+
+	mockCtrl := gomock.NewController(t)
+	engineMock := consensus.NewMockEngine(mockCtrl)
+	engineMock.EXPECT().Type().Return(chain.CliqueConsensus).AnyTimes()
+	engineMock.EXPECT().IsServiceTransaction(gomock.Any(), gomock.Any()).Return(false).AnyTimes()
+
+	chainConfig := &chain.Config{
+		ChainID:                 big.NewInt(1),
+		HomesteadBlock:          common.Big0,
+		TangerineWhistleBlock:   common.Big0,
+		SpuriousDragonBlock:     common.Big0,
+		ByzantiumBlock:          common.Big0,
+		ConstantinopleBlock:     common.Big0,
+		PetersburgBlock:         common.Big0,
+		IstanbulBlock:           common.Big0,
+		BerlinBlock:             common.Big0,
+		ForkID4Block:            common.Big0,
+		ForkID5DragonfruitBlock: common.Big0,
+		ForkID6IncaBerryBlock:   common.Big0,
+	}
+	cfg := SequenceBlockCfg{
+		chainConfig: chainConfig,
+		engine:      engineMock,
+		zk:          &ethconfig.Zk{},
+		zkVmConfig:  &vm.ZkConfig{},
+	}
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := types.MakeSigner(chainConfig, 1, 0)
+	transfer, err := types.SignTx(types.NewTransaction(0, common.HexToAddress("0x3000"), uint256.NewInt(0), 21000, uint256.NewInt(0), nil), *signer, key)
+	require.NoError(t, err)
+
+	// before fork 7 the batch gas budget is 30M, a fifth of it is reserved
+	forkId := uint64(6)
+	reserve := 0.2
+	attempt := func(batchGasUsed uint64) overflowType {
+		header := &types.Header{Number: big.NewInt(1), GasLimit: utils.GetBlockGasLimitForFork(forkId), Difficulty: big.NewInt(0)}
+		blockContext := core.NewEVMBlockContext(header, func(uint64) common.Hash { return common.Hash{} }, engineMock, &common.Address{})
+		ibs := state.New(sdb.stateReader)
+		batchCounters := vm.NewBatchCounterCollector(sdb.smt.GetDepth(), uint16(forkId), 0.6, false, nil)
+
+		_, _, overflow, err := attemptAddTransaction(cfg, sdb, ibs, batchCounters, &blockContext, header, transfer, zktx.MaxEffectivePercentage, false, forkId, 0, nil, reserve, batchGasUsed)
+		require.NoError(t, err)
+
+		// the counters have plenty of room left, it is the gas of the batch that runs into the reserve
+		overflowed, err := batchCounters.CheckForReservedOverflow(false, reserve)
+		require.NoError(t, err)
+		require.False(t, overflowed)
+		return overflow
+	}
+
+	require.Equal(t, overflowNone, attempt(0))
+	require.Equal(t, overflowNone, attempt(24_000_000-21_000))
+	require.Equal(t, overflowReserved, attempt(24_000_000-20_999))
+}
@@ -0,0 +1,41 @@
+package stages
+
+import (
+	"time"
+
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/zk/metrics"
+)
+
+// nextBlockSealTime picks the seal time of the block about to be started from the number of executable transactions
+// in the pool
+func nextBlockSealTime(cfg *SequenceBlockCfg) time.Duration {
+	sealTime := cfg.zk.SequencerBlockSealTime
+	if cfg.zk.SequencerAdaptiveSealTime {
+		pending, _, _ := cfg.txPool.CountContent()
+		sealTime = adaptiveBlockSealTime(cfg.zk, uint64(pending))
+	}
+
+	metrics.SeqBlockSealTime.Set(sealTime.Seconds())
+	return sealTime
+}
+
+// adaptiveBlockSealTime goes down linearly from the max block seal time for an idle pool to the min block seal time once
+// the pending target is reached.  Busy pools get blocks sealed quicker whilst idle ones don't get a run of empty blocks.
+func adaptiveBlockSealTime(zk *ethconfig.Zk, pending uint64) time.Duration {
+	target := zk.SequencerAdaptiveSealPendingTarget
+	if pending >= target {
+		return zk.SequencerBlockSealTimeMin
+	}
+
+	span := zk.SequencerBlockSealTimeMax - zk.SequencerBlockSealTimeMin
+	return zk.SequencerBlockSealTimeMax - time.Duration(uint64(span)*pending/target)
+}
+
+// batchSealTime never lets a batch time out before its first block could be sealed
+func batchSealTime(zk *ethconfig.Zk, blockSealTime time.Duration) time.Duration {
+	if blockSealTime > zk.SequencerBatchSealTime {
+		return blockSealTime
+	}
+	return zk.SequencerBatchSealTime
+}
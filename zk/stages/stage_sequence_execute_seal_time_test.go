@@ -0,0 +1,27 @@
+package stages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveBlockSealTime(t *testing.T) {
+	zk := &ethconfig.Zk{
+		SequencerBatchSealTime:             12 * time.Second,
+		SequencerBlockSealTimeMin:          time.Second,
+		SequencerBlockSealTimeMax:          21 * time.Second,
+		SequencerAdaptiveSealPendingTarget: 100,
+	}
+
+	require.Equal(t, 21*time.Second, adaptiveBlockSealTime(zk, 0))
+	require.Equal(t, 11*time.Second, adaptiveBlockSealTime(zk, 50))
+	require.Equal(t, time.Second, adaptiveBlockSealTime(zk, 100))
+	require.Equal(t, time.Second, adaptiveBlockSealTime(zk, 5000))
+
+	// an idle pool stretches the batch along with its block
+	require.Equal(t, 21*time.Second, batchSealTime(zk, adaptiveBlockSealTime(zk, 0)))
+	require.Equal(t, 12*time.Second, batchSealTime(zk, adaptiveBlockSealTime(zk, 100)))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"time"
 
 	mapset "github.com/deckarep/golang-set/v2"
 
@@ -50,7 +51,10 @@ type BatchState struct {
 	batchL1RecoveryData           *BatchL1RecoveryData
 	limboRecoveryData             *LimboRecoveryData
 	resequenceBatchJob            *ResequenceBatchJob
+	forcedBatchData               *ForcedBatchData
 	overflowTransactions          int
+	startedAt                     time.Time // the priority reserve is released a while into the batch
+	gasUsed                       uint64    // by the blocks of the batch finished so far, the priority reserve is a share of the batch gas
 }
 
 func newBatchState(forkId, batchNumber, blockNumber uint64, hasExecutorForThisBatch, l1Recovery bool, txPool *txpool.TxPool, resequenceBatchJob *ResequenceBatchJob) *BatchState {
@@ -65,6 +69,7 @@ func newBatchState(forkId, batchNumber, blockNumber uint64, hasExecutorForThisBa
 		batchL1RecoveryData:           nil,
 		limboRecoveryData:             nil,
 		resequenceBatchJob:            resequenceBatchJob,
+		startedAt:                     time.Now(),
 	}
 
 	if batchNumber != injectedBatchBatchNumber { // process injected batch regularly, no matter if it is in any recovery
@@ -107,8 +112,17 @@ func (bs *BatchState) isResequence() bool {
 	return bs.resequenceBatchJob != nil
 }
 
+func (bs *BatchState) isForcedBatch() bool {
+	return bs.forcedBatchData != nil
+}
+
+// the content of L1 recovery and forced batches is fixed on the L1 so they have to be included as a whole
+func (bs *BatchState) isL1DataBatch() bool {
+	return bs.isL1Recovery() || bs.isForcedBatch()
+}
+
 func (bs *BatchState) isAnyRecovery() bool {
-	return bs.isL1Recovery() || bs.isLimboRecovery() || bs.isResequence()
+	return bs.isL1Recovery() || bs.isLimboRecovery() || bs.isResequence() || bs.isForcedBatch()
 }
 
 func (bs *BatchState) isMoreThanSingleRecovery() bool {
@@ -143,6 +157,13 @@ func (bs *BatchState) loadBlockL1RecoveryData(decodedBlocksIndex uint64) bool {
 	return found
 }
 
+func (bs *BatchState) loadBlockForcedBatchData(decodedBlocksIndex uint64) bool {
+	decodedBatchL2Data, found := bs.forcedBatchData.getDecodedBlockByIndex(decodedBlocksIndex)
+	bs.forcedBatchData.blockIndex = decodedBlocksIndex
+	bs.blockState.setBlockForcedBatchData(decodedBatchL2Data)
+	return found
+}
+
 // if not limbo set the limboHeaderTimestamp to the "default" value for "prepareHeader" function
 func (bs *BatchState) getBlockHeaderForcedTimestamp() uint64 {
 	if bs.isLimboRecovery() {
@@ -257,6 +278,7 @@ type BlockState struct {
 	transactionHashesToSlots map[common.Hash]common.Hash
 	builtBlockElements       BuiltBlockElements
 	blockL1RecoveryData      *zktx.DecodedBatchL2Data
+	blockForcedBatchData     *zktx.DecodedBatchL2Data
 	transactionsToDiscard    []common.Hash
 }
 
@@ -280,6 +302,17 @@ func (bs *BlockState) setBlockL1RecoveryData(blockL1RecoveryData *zktx.DecodedBa
 	}
 }
 
+// the forced batch block keeps the timestamp of the sequencer, only the transactions come from the L1
+func (bs *BlockState) setBlockForcedBatchData(blockForcedBatchData *zktx.DecodedBatchL2Data) {
+	bs.blockForcedBatchData = blockForcedBatchData
+
+	if bs.blockForcedBatchData != nil {
+		bs.transactionsForInclusion = bs.blockForcedBatchData.Transactions
+	} else {
+		bs.transactionsForInclusion = []types.Transaction{}
+	}
+}
+
 func (bs *BlockState) getDeltaTimestamp() uint64 {
 	if bs.blockL1RecoveryData != nil {
 		return uint64(bs.blockL1RecoveryData.DeltaTimestamp)
@@ -293,6 +326,10 @@ func (bs *BlockState) getL1EffectiveGases(cfg SequenceBlockCfg, i int) uint8 {
 		return bs.blockL1RecoveryData.EffectiveGasPricePercentages[i]
 	}
 
+	if bs.blockForcedBatchData != nil && i < len(bs.blockForcedBatchData.EffectiveGasPricePercentages) {
+		return bs.blockForcedBatchData.EffectiveGasPricePercentages[i]
+	}
+
 	return DeriveEffectiveGasPrice(cfg, bs.transactionsForInclusion[i])
 }
 
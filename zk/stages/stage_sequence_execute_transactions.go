@@ -123,12 +123,30 @@ func extractTransactionsFromSlot(slot *types2.TxsRlp, currentHeight uint64, cfg
 	return transactions, ids, toRemove, nil
 }
 
+// deadlinePassed is true if any of the transactions was sent with a deadline before the given block
+func deadlinePassed(cfg SequenceBlockCfg, blockNumber, timestamp uint64, transactions ...types.Transaction) bool {
+	for _, transaction := range transactions {
+		if deadline, ok := cfg.txPool.GetDeadline(transaction.Hash()); ok && deadline.Passed(blockNumber, timestamp) {
+			return true
+		}
+	}
+	return false
+}
+
+// reservedGasOverflow is true if the gas used by the batch goes into the share of the batch gas budget that is reserved
+func reservedGasOverflow(forkId, batchGasUsed uint64, reserve float64) bool {
+	budget := utils.GetBlockGasLimitForFork(forkId)
+	return batchGasUsed > budget-uint64(float64(budget)*reserve)
+}
+
 type overflowType uint8
 
 const (
 	overflowNone overflowType = iota
 	overflowCounters
 	overflowGas
+	// the transaction would use the share of the batch reserved for priority transactions
+	overflowReserved
 )
 
 func attemptAddTransaction(
@@ -143,6 +161,8 @@ func attemptAddTransaction(
 	l1Recovery bool,
 	forkId, l1InfoIndex uint64,
 	blockDataSizeChecker *BlockDataChecker,
+	reserve float64,
+	batchGasUsed uint64,
 ) (*types.Receipt, *core.ExecutionResult, overflowType, error) {
 	var batchDataOverflow, overflow bool
 	var err error
@@ -223,6 +243,16 @@ func attemptAddTransaction(
 		ibs.RevertToSnapshot(snapshot)
 		return nil, nil, overflowGas, nil
 	}
+	if reserve > 0 {
+		if overflow, err = batchCounters.CheckForReservedOverflow(l1InfoIndex != 0, reserve); err != nil {
+			return nil, nil, overflowNone, err
+		}
+		if overflow || reservedGasOverflow(forkId, batchGasUsed+gasUsed, reserve) {
+			log.Debug("Transaction reaches the priority reserve", "txHash", transaction.Hash(), "reserve", reserve, "batchGasUsed", batchGasUsed, "blockGasUsed", header.GasUsed)
+			ibs.RevertToSnapshot(snapshot)
+			return nil, nil, overflowReserved, nil
+		}
+	}
 	log.Debug("Transaction added", "txHash", transaction.Hash(), "coutners", counters)
 
 	// add the gas only if not reverted. This should not be moved above the overflow check
@@ -32,6 +32,9 @@ func UnwindSequenceExecutionStage(u *stagedsync.UnwindState, s *stagedsync.Stage
 		return err
 	}
 
+	// the transactions of the unwound blocks are no longer where they were pre-confirmed
+	cfg.preconfirmer.DropFromBlock(u.UnwindPoint + 1)
+
 	//Do not invoke u.Done, because its effect is handled by updateSequencerProgress
 
 	if !useExternalTx {
@@ -135,6 +138,14 @@ func UnwindSequenceExecutionStageDbWrites(ctx context.Context, u *stagedsync.Unw
 		return fmt.Errorf("truncate fork id error: %v", err)
 	}
 	// only seq
+	if err = hermezDb.DeleteBatchForcedBatches(fromBatchForForkIdDeletion, toBatch); err != nil {
+		return fmt.Errorf("truncate batch forced batches error: %v", err)
+	}
+	// only seq - a batch unwound part way through is open again so its close info goes as well
+	if err = hermezDb.DeleteBatchCloseInfos(fromBatch, toBatch); err != nil {
+		return fmt.Errorf("truncate batch close info error: %v", err)
+	}
+	// only seq
 	if err = hermezDb.DeleteBatchCounters(u.UnwindPoint+1, s.BlockNumber); err != nil {
 		return fmt.Errorf("truncate block batches error: %v", err)
 	}
@@ -2,6 +2,7 @@ package stages
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/c2h5oh/datasize"
@@ -34,6 +35,7 @@ import (
 	"github.com/ledgerwatch/erigon/zk/hermez_db"
 	"github.com/ledgerwatch/erigon/zk/l1infotree"
 	verifier "github.com/ledgerwatch/erigon/zk/legacy_executor_verifier"
+	"github.com/ledgerwatch/erigon/zk/sequencer"
 	zktx "github.com/ledgerwatch/erigon/zk/tx"
 	"github.com/ledgerwatch/erigon/zk/txpool"
 	zktypes "github.com/ledgerwatch/erigon/zk/types"
@@ -85,6 +87,12 @@ type SequenceBlockCfg struct {
 	yieldSize      uint16
 
 	infoTreeUpdater *l1infotree.Updater
+
+	sequencerControl *sequencer.Control
+	preconfirmer     *sequencer.Preconfirmer
+	sequencerLease   *sequencer.Lease
+	// set once the batch a previous sequencer left open in the datastream is closed, after first getting the lease
+	takenOverBatchClosed *atomic.Bool
 }
 
 func StageSequenceBlocksCfg(
@@ -114,6 +122,9 @@ func StageSequenceBlocksCfg(
 	legacyVerifier *verifier.LegacyExecutorVerifier,
 	yieldSize uint16,
 	infoTreeUpdater *l1infotree.Updater,
+	sequencerControl *sequencer.Control,
+	preconfirmer *sequencer.Preconfirmer,
+	sequencerLease *sequencer.Lease,
 ) SequenceBlockCfg {
 
 	return SequenceBlockCfg{
@@ -141,6 +152,11 @@ func StageSequenceBlocksCfg(
 		legacyVerifier:   legacyVerifier,
 		yieldSize:        yieldSize,
 		infoTreeUpdater:  infoTreeUpdater,
+		sequencerControl: sequencerControl,
+		preconfirmer:     preconfirmer,
+		sequencerLease:   sequencerLease,
+
+		takenOverBatchClosed: &atomic.Bool{},
 	}
 }
 
@@ -319,6 +335,13 @@ func prepareL1AndInfoTreeRelatedStuff(sdb *stageDb, batchState *BatchState, prop
 		if infoTreeIndexProgress >= l1TreeUpdateIndex {
 			shouldWriteGerToContract = false
 		}
+	} else if batchState.isForcedBatch() {
+		// forced batches do not reference an info tree index so we leave it at 0 and use the GER and L1 block hash
+		// the batch was forced with instead
+		if l1TreeUpdate = batchState.forcedBatchData.getL1InfoTreeUpdate(); l1TreeUpdate != nil {
+			l1BlockHash = l1TreeUpdate.ParentHash
+			ger = l1TreeUpdate.GER
+		}
 	} else {
 		if l1TreeUpdateIndex, l1TreeUpdate, err = calculateNextL1TreeUpdateToUse(infoTreeIndexProgress, sdb.hermezDb, proposedTimestamp); err != nil {
 			return
@@ -339,9 +362,10 @@ func prepareL1AndInfoTreeRelatedStuff(sdb *stageDb, batchState *BatchState, prop
 }
 
 func prepareTickers(cfg *SequenceBlockCfg) (*time.Ticker, *time.Ticker, *time.Ticker, *time.Ticker) {
-	batchTicker := time.NewTicker(cfg.zk.SequencerBatchSealTime)
+	blockSealTime := nextBlockSealTime(cfg)
+	batchTicker := time.NewTicker(batchSealTime(cfg.zk, blockSealTime))
 	logTicker := time.NewTicker(10 * time.Second)
-	blockTicker := time.NewTicker(cfg.zk.SequencerBlockSealTime)
+	blockTicker := time.NewTicker(blockSealTime)
 	infoTreeTicker := time.NewTicker(cfg.zk.InfoTreeUpdateInterval)
 
 	return batchTicker, logTicker, blockTicker, infoTreeTicker
@@ -431,20 +455,9 @@ func tryHaltSequencer(batchContext *BatchContext, batchState *BatchState, stream
 	if batchContext.cfg.zk.SequencerHaltOnBatchNumber != 0 && batchContext.cfg.zk.SequencerHaltOnBatchNumber == batchState.batchNumber {
 		log.Info(fmt.Sprintf("[%s] Attempting to halt on batch %v, checking for pending verifications", batchContext.s.LogPrefix(), batchState.batchNumber))
 
-		// we first need to ensure there are no ongoing executor requests at this point before we halt as
-		// these blocks won't have been committed to the datastream
-		for {
-			if pending, count := batchContext.cfg.legacyVerifier.HasPendingVerifications(); pending {
-				log.Info(fmt.Sprintf("[%s] Waiting for pending verifications to complete before halting sequencer...", batchContext.s.LogPrefix()), "count", count)
-				time.Sleep(2 * time.Second)
-				needsUnwind, err := updateStreamAndCheckRollback(batchContext, batchState, streamWriter, u)
-				if needsUnwind || err != nil {
-					return needsUnwind, err
-				}
-			} else {
-				log.Info(fmt.Sprintf("[%s] No pending verifications, halting sequencer...", batchContext.s.LogPrefix()))
-				break
-			}
+		needsUnwind, err := waitForPendingVerifications(batchContext, batchState, streamWriter, u)
+		if needsUnwind || err != nil {
+			return needsUnwind, err
 		}
 
 		// we need to ensure the batch is also sealed in the datastream at this point
@@ -452,6 +465,7 @@ func tryHaltSequencer(batchContext *BatchContext, batchState *BatchState, stream
 			return false, err
 		}
 
+		batchContext.cfg.sequencerControl.SetHalted(batchState.batchNumber, latestBlock)
 		for {
 			log.Info(fmt.Sprintf("[%s] Halt sequencer on batch %d...", batchContext.s.LogPrefix(), batchState.batchNumber))
 			time.Sleep(5 * time.Second) //nolint:gomnd
@@ -461,6 +475,24 @@ func tryHaltSequencer(batchContext *BatchContext, batchState *BatchState, stream
 	return false, nil
 }
 
+// waitForPendingVerifications blocks until there are no ongoing executor requests, the blocks they are for won't have
+// been committed to the datastream until then
+func waitForPendingVerifications(batchContext *BatchContext, batchState *BatchState, streamWriter *SequencerBatchStreamWriter, u stagedsync.Unwinder) (bool, error) {
+	for {
+		if pending, count := batchContext.cfg.legacyVerifier.HasPendingVerifications(); pending {
+			log.Info(fmt.Sprintf("[%s] Waiting for pending verifications to complete before halting sequencer...", batchContext.s.LogPrefix()), "count", count)
+			time.Sleep(2 * time.Second)
+			needsUnwind, err := updateStreamAndCheckRollback(batchContext, batchState, streamWriter, u)
+			if needsUnwind || err != nil {
+				return needsUnwind, err
+			}
+		} else {
+			log.Info(fmt.Sprintf("[%s] No pending verifications, halting sequencer...", batchContext.s.LogPrefix()))
+			return false, nil
+		}
+	}
+}
+
 type batchChecker interface {
 	GetL1InfoTreeUpdate(idx uint64) (*zktypes.L1InfoTreeUpdate, error)
 }
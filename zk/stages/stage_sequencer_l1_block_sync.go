@@ -8,11 +8,13 @@ import (
 
 	"encoding/binary"
 
+	"github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/eth/ethconfig"
 	"github.com/ledgerwatch/erigon/eth/stagedsync"
 	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/zk/da"
 	"github.com/ledgerwatch/erigon/zk/hermez_db"
 	"github.com/ledgerwatch/erigon/zk/l1_data"
 	"github.com/ledgerwatch/erigon/zk/syncer"
@@ -23,13 +25,37 @@ type SequencerL1BlockSyncCfg struct {
 	db     kv.RwDB
 	zkCfg  *ethconfig.Zk
 	syncer *syncer.L1Syncer
+	// fetches the blobs of the sequences carrying their batch data in blobs, nil when no beacon API is configured
+	beacon *da.BeaconClient
+	// serves the batch data of validium sequences, nil when no data availability URL is configured
+	daBackend da.DABackend
+	// the data availability committees validium sequences were signed by
+	committees map[committeeKey]*da.Committee
+}
+
+// committeeKey identifies a data availability committee, its hash only covers the addresses of its members
+type committeeKey struct {
+	hash               common.Hash
+	requiredSignatures uint64
 }
 
 func StageSequencerL1BlockSyncCfg(db kv.RwDB, zkCfg *ethconfig.Zk, syncer *syncer.L1Syncer) SequencerL1BlockSyncCfg {
+	var beacon *da.BeaconClient
+	if zkCfg.L1BeaconUrl != "" {
+		beacon = da.NewBeaconClient(zkCfg.L1BeaconUrl)
+	}
+	var daBackend da.DABackend
+	if zkCfg.DAUrl != "" {
+		daBackend = da.NewBackend(zkCfg.DAUrl)
+	}
+
 	return SequencerL1BlockSyncCfg{
-		db:     db,
-		zkCfg:  zkCfg,
-		syncer: syncer,
+		db:         db,
+		zkCfg:      zkCfg,
+		syncer:     syncer,
+		beacon:     beacon,
+		daBackend:  daBackend,
+		committees: make(map[committeeKey]*da.Committee),
 	}
 }
 
@@ -166,7 +192,7 @@ LOOP:
 					return funcErr
 				}
 
-				batches, coinbase, limitTimestamp, err := l1_data.DecodeL1BatchData(transaction.GetData(), cfg.zkCfg.DAUrl)
+				batches, coinbase, limitTimestamp, err := decodeL1SequenceTransaction(ctx, cfg, transaction, l.BlockNumber)
 				if err != nil {
 					funcErr = err
 					return funcErr
@@ -239,6 +265,73 @@ LOOP:
 	return nil
 }
 
+// decodeL1SequenceTransaction decodes the batch L2 data of a sequence transaction, from its call data or, when it carries
+// blobs, from its blobs fetched from the beacon API
+func decodeL1SequenceTransaction(ctx context.Context, cfg SequencerL1BlockSyncCfg, transaction types.Transaction, l1BlockNo uint64) ([][]byte, common.Address, uint64, error) {
+	blobHashes := transaction.GetBlobHashes()
+	if len(blobHashes) == 0 {
+		batches, coinbase, limitTimestamp, err := l1_data.DecodeL1BatchData(transaction.GetData(), cfg.daBackend)
+		if err != nil {
+			return nil, common.Address{}, 0, err
+		}
+		if cfg.zkCfg.DAVerifySignatures {
+			if err := verifyDACSignatures(ctx, cfg, transaction, l1BlockNo); err != nil {
+				return nil, common.Address{}, 0, err
+			}
+		}
+		return batches, coinbase, limitTimestamp, nil
+	}
+
+	if cfg.beacon == nil {
+		return nil, common.Address{}, 0, fmt.Errorf("sequence transaction %s carries its batches in blobs, a beacon api url is required to fetch them", transaction.Hash())
+	}
+	header, err := cfg.syncer.GetHeader(l1BlockNo)
+	if err != nil {
+		return nil, common.Address{}, 0, fmt.Errorf("GetHeader: %w", err)
+	}
+	blobs, err := cfg.beacon.GetBlobs(ctx, header.Time, blobHashes)
+	if err != nil {
+		return nil, common.Address{}, 0, fmt.Errorf("GetBlobs: %w", err)
+	}
+
+	return l1_data.DecodeL1BlobBatchData(transaction.GetData(), blobs)
+}
+
+// verifyDACSignatures checks the signatures of the data availability committee over a validium sequence against the
+// members of the committee as of the L1 block of the sequence
+func verifyDACSignatures(ctx context.Context, cfg SequencerL1BlockSyncCfg, transaction types.Transaction, l1BlockNo uint64) error {
+	signedHash, message, isValidium, err := l1_data.ValidiumDataAvailabilityMessage(transaction.GetData())
+	if err != nil || !isValidium {
+		return err
+	}
+
+	dac, err := cfg.syncer.CallDataAvailabilityProtocol(ctx, &cfg.zkCfg.AddressZkevm, l1BlockNo)
+	if err != nil {
+		return fmt.Errorf("CallDataAvailabilityProtocol: %w", err)
+	}
+	committeeHash, err := cfg.syncer.CallCommitteeHash(ctx, &dac, l1BlockNo)
+	if err != nil {
+		return fmt.Errorf("CallCommitteeHash: %w", err)
+	}
+	requiredSignatures, err := cfg.syncer.CallRequiredAmountOfSignatures(ctx, &dac, l1BlockNo)
+	if err != nil {
+		return fmt.Errorf("CallRequiredAmountOfSignatures: %w", err)
+	}
+	key := committeeKey{hash: committeeHash, requiredSignatures: requiredSignatures}
+	committee, ok := cfg.committees[key]
+	if !ok {
+		if committee, err = cfg.syncer.CallDataCommittee(ctx, &dac, l1BlockNo); err != nil {
+			return fmt.Errorf("CallDataCommittee: %w", err)
+		}
+		cfg.committees[key] = committee
+	}
+
+	if err := committee.VerifySignatures(signedHash, message); err != nil {
+		return fmt.Errorf("sequence transaction %s: %w", transaction.Hash(), err)
+	}
+	return nil
+}
+
 func haveAllBatchesInDb(highestBatch uint64, cfg SequencerL1BlockSyncCfg, hermezDb *hermez_db.HermezDb) (bool, error) {
 	hasEverything := true
 	for i := highestBatch; i <= cfg.zkCfg.L1SyncStopBatch; i++ {
@@ -0,0 +1,126 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	ethereum "github.com/ledgerwatch/erigon"
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon/zk/da"
+)
+
+const (
+	dataAvailabilityProtocol   = "0xe57a0b4c"
+	committeeHash              = "0x609d4544"
+	requiredAmountOfSignatures = "0x6beedd39"
+	getAmountOfMembers         = "0xdce1e2b6"
+	membersSignature           = "0x5daf08ca"
+)
+
+// CallDataAvailabilityProtocol returns the data committee contract of the validium at addr, as of the L1 block
+func (s *L1Syncer) CallDataAvailabilityProtocol(ctx context.Context, addr *common.Address, blockNo uint64) (common.Address, error) {
+	resp, err := s.callAt(ctx, addr, common.FromHex(dataAvailabilityProtocol), blockNo)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(resp) < 32 {
+		return common.Address{}, errorShortResponseLT32
+	}
+
+	return common.BytesToAddress(resp[12:32]), nil
+}
+
+// CallCommitteeHash returns the hash of the member addresses of the data committee contract at addr, as of the L1
+// block.  It changes whenever the members do
+func (s *L1Syncer) CallCommitteeHash(ctx context.Context, addr *common.Address, blockNo uint64) (common.Hash, error) {
+	resp, err := s.callAt(ctx, addr, common.FromHex(committeeHash), blockNo)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(resp) < 32 {
+		return common.Hash{}, errorShortResponseLT32
+	}
+
+	return common.BytesToHash(resp[:32]), nil
+}
+
+// CallRequiredAmountOfSignatures returns how many of the members of the data committee contract at addr have to sign a
+// sequence, as of the L1 block
+func (s *L1Syncer) CallRequiredAmountOfSignatures(ctx context.Context, addr *common.Address, blockNo uint64) (uint64, error) {
+	return s.callUint64At(ctx, addr, requiredAmountOfSignatures, blockNo)
+}
+
+// CallDataCommittee returns the members and the required signatures of the data committee contract at addr, as of the
+// L1 block
+func (s *L1Syncer) CallDataCommittee(ctx context.Context, addr *common.Address, blockNo uint64) (*da.Committee, error) {
+	required, err := s.CallRequiredAmountOfSignatures(ctx, addr, blockNo)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := s.callUint64At(ctx, addr, getAmountOfMembers, blockNo)
+	if err != nil {
+		return nil, err
+	}
+
+	// both come from the L1, the members are only allocated for a committee that can be checked
+	if amount > da.MaxCommitteeMembers {
+		return nil, fmt.Errorf("data committee has %d members, more than the %d supported", amount, da.MaxCommitteeMembers)
+	}
+	if required > amount {
+		return nil, fmt.Errorf("data committee requires %d signatures of its %d members", required, amount)
+	}
+
+	committee := &da.Committee{
+		Members:            make([]da.CommitteeMember, amount),
+		RequiredSignatures: required,
+	}
+	for i := range committee.Members {
+		// members(i) returns the url and the address of the member
+		resp, err := s.callAt(ctx, addr, append(common.FromHex(membersSignature), common.BigToHash(big.NewInt(int64(i))).Bytes()...), blockNo)
+		if err != nil {
+			return nil, err
+		}
+		if len(resp) < 96 {
+			return nil, errorShortResponseLT96
+		}
+		offset := new(big.Int).SetBytes(resp[:32])
+		if !offset.IsUint64() || offset.Uint64() > uint64(len(resp)-32) {
+			return nil, fmt.Errorf("invalid url offset of data committee member %d", i)
+		}
+		urlLength := new(big.Int).SetBytes(resp[offset.Uint64() : offset.Uint64()+32])
+		if !urlLength.IsUint64() || urlLength.Uint64() > uint64(len(resp))-offset.Uint64()-32 {
+			return nil, fmt.Errorf("invalid url length of data committee member %d", i)
+		}
+		committee.Members[i] = da.CommitteeMember{
+			Url:  string(resp[offset.Uint64()+32 : offset.Uint64()+32+urlLength.Uint64()]),
+			Addr: common.BytesToAddress(resp[44:64]),
+		}
+	}
+
+	return committee, nil
+}
+
+func (s *L1Syncer) callUint64At(ctx context.Context, addr *common.Address, data string, blockNo uint64) (uint64, error) {
+	resp, err := s.callAt(ctx, addr, common.FromHex(data), blockNo)
+	if err != nil {
+		return 0, err
+	}
+	if len(resp) < 32 {
+		return 0, errorShortResponseLT32
+	}
+	value := new(big.Int).SetBytes(resp[:32])
+	if !value.IsUint64() {
+		return 0, fmt.Errorf("%s returned %s, more than a uint64", data, value)
+	}
+
+	return value.Uint64(), nil
+}
+
+func (s *L1Syncer) callAt(ctx context.Context, addr *common.Address, data []byte, blockNo uint64) ([]byte, error) {
+	em := s.getNextEtherman()
+	return em.CallContract(ctx, ethereum.CallMsg{
+		To:   addr,
+		Data: data,
+	}, new(big.Int).SetUint64(blockNo))
+}
@@ -0,0 +1,64 @@
+package syncer
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	ethereum "github.com/ledgerwatch/erigon"
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon/zk/da"
+	"github.com/ledgerwatch/erigon/zk/syncer/mocks"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCallDataCommittee(t *testing.T) {
+	dac := common.HexToAddress("0xdac")
+	members := []da.CommitteeMember{
+		{Url: "http://dac-0", Addr: common.HexToAddress("0x10")},
+		{Url: "http://a-data-availability-committee-member-with-a-long-url", Addr: common.HexToAddress("0x11")},
+	}
+	word := func(n int64) []byte {
+		return common.BigToHash(big.NewInt(n)).Bytes()
+	}
+	required, amount := word(1), word(int64(len(members)))
+
+	mockCtrl := gomock.NewController(t)
+	etherman := mocks.NewMockIEtherman(mockCtrl)
+	etherman.EXPECT().CallContract(gomock.Any(), gomock.Any(), big.NewInt(100)).DoAndReturn(func(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+		require.Equal(t, dac, *msg.To)
+		switch hex.EncodeToString(msg.Data[:4]) {
+		case requiredAmountOfSignatures[2:]:
+			return required, nil
+		case getAmountOfMembers[2:]:
+			return amount, nil
+		case membersSignature[2:]:
+			member := members[new(big.Int).SetBytes(msg.Data[4:]).Int64()]
+			// the offset of the url, the address, then the length of the url and the url padded to a word
+			resp := append(word(64), common.BytesToHash(member.Addr[:]).Bytes()...)
+			resp = append(resp, word(int64(len(member.Url)))...)
+			resp = append(resp, []byte(member.Url)...)
+			return append(resp, make([]byte, (32-len(member.Url)%32)%32)...), nil
+		}
+		t.Fatalf("unexpected call %x", msg.Data)
+		return nil, nil
+	}).AnyTimes()
+	s := NewL1Syncer(context.Background(), []IEtherman{etherman}, nil, nil, 10, 0, "latest")
+
+	committee, err := s.CallDataCommittee(context.Background(), &dac, 100)
+	require.NoError(t, err)
+	require.Equal(t, &da.Committee{Members: members, RequiredSignatures: 1}, committee)
+
+	// the committee is bounded before its members are read
+	required = word(3)
+	_, err = s.CallDataCommittee(context.Background(), &dac, 100)
+	require.ErrorContains(t, err, "data committee requires 3 signatures of its 2 members")
+	required, amount = word(1), word(da.MaxCommitteeMembers+1)
+	_, err = s.CallDataCommittee(context.Background(), &dac, 100)
+	require.ErrorContains(t, err, "data committee has 257 members")
+	amount = common.BigToHash(new(big.Int).Lsh(big.NewInt(1), 64)).Bytes()
+	_, err = s.CallDataCommittee(context.Background(), &dac, 100)
+	require.ErrorContains(t, err, "more than a uint64")
+}
@@ -0,0 +1,205 @@
+package syncer
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	ethereum "github.com/ledgerwatch/erigon"
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+	ethTypes "github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/log/v3"
+)
+
+// ErrNoL1Quorum is returned by a read checked by the quorum when not enough of the providers agree on its result
+var ErrNoL1Quorum = errors.New("no quorum of l1 providers")
+
+// the fingerprint providers agreeing that what was read doesn't exist vote for
+var notFoundFingerprint = crypto.Keccak256Hash([]byte("not found"))
+
+type quorumResponse[T any] struct {
+	provider    int
+	result      T
+	err         error
+	fingerprint common.Hash
+}
+
+// SetQuorum has the sequence and verification logs, the headers and the acc input hashes read from the L1 sent to
+// providers of the etherMans at once, and only accepts them when quorum of them agree on them.  That way a single
+// misbehaving or lagging provider can't feed the node a wrong view of the L1.  With providers 0 they are sent to all of
+// the etherMans, with quorum below 2 they are read from whichever etherMan is next, as everything else is
+func (s *L1Syncer) SetQuorum(providers, quorum int) error {
+	if quorum < 2 {
+		s.quorumProviders, s.quorum = 0, 0
+		return nil
+	}
+	if providers == 0 {
+		providers = len(s.etherMans)
+	}
+	if providers > len(s.etherMans) {
+		return fmt.Errorf("l1 quorum reads sent to %d providers with %d l1 rpc urls", providers, len(s.etherMans))
+	}
+	if quorum > providers {
+		return fmt.Errorf("l1 quorum of %d out of %d providers", quorum, providers)
+	}
+
+	s.quorumProviders, s.quorum = providers, quorum
+	return nil
+}
+
+func (s *L1Syncer) quorumEnabled() bool {
+	return s.quorum > 1
+}
+
+// getQuorumProviders returns the indexes of the etherMans the next read checked by the quorum goes to, rotating through
+// them as getNextEtherman does
+func (s *L1Syncer) getQuorumProviders() []int {
+	s.ethermanMtx.Lock()
+	defer s.ethermanMtx.Unlock()
+
+	providers := make([]int, s.quorumProviders)
+	for i := range providers {
+		if s.ethermanIndex >= uint8(len(s.etherMans)) {
+			s.ethermanIndex = 0
+		}
+		providers[i] = int(s.ethermanIndex)
+		s.ethermanIndex++
+	}
+
+	return providers
+}
+
+// quorumRead sends the read to the quorum providers at once and returns the first result quorum of them agree on, by
+// its fingerprint.  Agreeing that what was read is not found counts, the ethereum.NotFound is returned then.  The
+// providers disagreeing with the result, including the ones answering after it is accepted, are counted by method and
+// provider
+func quorumRead[T any](ctx context.Context, s *L1Syncer, method string, read func(ctx context.Context, em IEtherman) (T, error), fingerprint func(T) common.Hash) (T, error) {
+	providers := s.getQuorumProviders()
+	responses := make(chan quorumResponse[T], len(providers))
+	for _, provider := range providers {
+		go func(provider int) {
+			result, err := read(ctx, s.etherMans[provider])
+			response := quorumResponse[T]{provider: provider, result: result, err: err}
+			switch {
+			case err == nil:
+				response.fingerprint = fingerprint(result)
+			case errors.Is(err, ethereum.NotFound):
+				response.fingerprint = notFoundFingerprint
+			}
+			responses <- response
+		}(provider)
+	}
+
+	votes := make(map[common.Hash]int)
+	received := make([]quorumResponse[T], 0, len(providers))
+	for range providers {
+		response := <-responses
+		received = append(received, response)
+		if response.fingerprint == (common.Hash{}) {
+			log.Debug("L1 provider failed a quorum read", "method", method, "provider", response.provider, "err", response.err)
+			metrics.GetOrCreateCounter(fmt.Sprintf(`l1_quorum_provider_errors_total{method="%s",provider="%d"}`, method, response.provider)).Inc()
+			continue
+		}
+
+		votes[response.fingerprint]++
+		if votes[response.fingerprint] < s.quorum {
+			continue
+		}
+
+		accepted := response.fingerprint
+		for _, r := range received {
+			recordQuorumDisagreement(method, accepted, r)
+		}
+		go func(pending int) {
+			for i := 0; i < pending; i++ {
+				recordQuorumDisagreement(method, accepted, <-responses)
+			}
+		}(len(providers) - len(received))
+
+		return response.result, response.err
+	}
+
+	metrics.GetOrCreateCounter(fmt.Sprintf(`l1_quorum_failures_total{method="%s"}`, method)).Inc()
+	var zero T
+	return zero, fmt.Errorf("%w agrees on %s: %d of %d providers needed, %d distinct answers", ErrNoL1Quorum, method, s.quorum, len(providers), len(votes))
+}
+
+func recordQuorumDisagreement[T any](method string, accepted common.Hash, response quorumResponse[T]) {
+	if response.fingerprint == (common.Hash{}) || response.fingerprint == accepted {
+		return
+	}
+	log.Warn("L1 provider disagrees with the quorum", "method", method, "provider", response.provider)
+	metrics.GetOrCreateCounter(fmt.Sprintf(`l1_quorum_disagreements_total{method="%s",provider="%d"}`, method, response.provider)).Inc()
+}
+
+// filterLogs reads the logs of the query, checked by the quorum when enabled
+func (s *L1Syncer) filterLogs(ctx context.Context, query ethereum.FilterQuery) ([]ethTypes.Log, error) {
+	if !s.quorumEnabled() {
+		return s.getNextEtherman().FilterLogs(ctx, query)
+	}
+
+	return quorumRead(ctx, s, "FilterLogs", func(ctx context.Context, em IEtherman) ([]ethTypes.Log, error) {
+		return em.FilterLogs(ctx, query)
+	}, logsFingerprint)
+}
+
+// headerByNumber reads the header from the etherMan or, when enabled, from the quorum providers instead
+func (s *L1Syncer) headerByNumber(ctx context.Context, em IEtherman, number uint64) (*ethTypes.Header, error) {
+	if !s.quorumEnabled() {
+		return em.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	}
+
+	return quorumRead(ctx, s, "HeaderByNumber", func(ctx context.Context, em IEtherman) (*ethTypes.Header, error) {
+		header, err := em.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+		if err == nil && header == nil {
+			err = ethereum.NotFound
+		}
+		return header, err
+	}, func(header *ethTypes.Header) common.Hash {
+		return header.Hash()
+	})
+}
+
+// callContract makes the call at the latest block, checked by the quorum under the method name when enabled
+func (s *L1Syncer) callContract(ctx context.Context, method string, msg ethereum.CallMsg) ([]byte, error) {
+	if !s.quorumEnabled() {
+		return s.getNextEtherman().CallContract(ctx, msg, nil)
+	}
+
+	return quorumRead(ctx, s, method, func(ctx context.Context, em IEtherman) ([]byte, error) {
+		return em.CallContract(ctx, msg, nil)
+	}, func(resp []byte) common.Hash {
+		return crypto.Keccak256Hash(resp)
+	})
+}
+
+// logsFingerprint covers everything about the logs, the blocks they are in included, so that providers on different
+// forks of the L1 disagree
+func logsFingerprint(logs []ethTypes.Log) common.Hash {
+	var buf []byte
+	for _, l := range logs {
+		buf = append(buf, l.Address[:]...)
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(l.Topics)))
+		for _, topic := range l.Topics {
+			buf = append(buf, topic[:]...)
+		}
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(l.Data)))
+		buf = append(buf, l.Data...)
+		buf = binary.BigEndian.AppendUint64(buf, l.BlockNumber)
+		buf = append(buf, l.BlockHash[:]...)
+		buf = append(buf, l.TxHash[:]...)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(l.TxIndex))
+		buf = binary.BigEndian.AppendUint64(buf, uint64(l.Index))
+		if l.Removed {
+			buf = append(buf, 1)
+		} else {
+			buf = append(buf, 0)
+		}
+	}
+
+	return crypto.Keccak256Hash(buf)
+}
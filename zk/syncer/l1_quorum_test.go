@@ -0,0 +1,88 @@
+package syncer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	ethereum "github.com/ledgerwatch/erigon"
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/metrics"
+	ethTypes "github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/zk/syncer/mocks"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestL1SyncerQuorum(t *testing.T) {
+	header := func(number int64, fork byte) *ethTypes.Header {
+		return &ethTypes.Header{Number: big.NewInt(number), Extra: []byte{fork}}
+	}
+	addr := common.HexToAddress("0x1")
+	logs := func(blockHash byte) []ethTypes.Log {
+		return []ethTypes.Log{{Address: addr, BlockNumber: 10, BlockHash: common.Hash{blockHash}}}
+	}
+
+	// provider 2 is on another fork of the L1 from block 20 and its calls fail
+	mockCtrl := gomock.NewController(t)
+	etherMans := []IEtherman{mocks.NewMockIEtherman(mockCtrl), mocks.NewMockIEtherman(mockCtrl), mocks.NewMockIEtherman(mockCtrl)}
+	for i, em := range etherMans {
+		fork := byte(0)
+		if i == 2 {
+			fork = 1
+		}
+		mock := em.(*mocks.MockIEtherman)
+		mock.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(10)).Return(header(10, 0), nil).AnyTimes()
+		mock.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(20)).Return(header(20, fork), nil).AnyTimes()
+		mock.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(30)).Return(nil, ethereum.NotFound).AnyTimes()
+		mock.EXPECT().FilterLogs(gomock.Any(), gomock.Any()).Return(logs(fork), nil).AnyTimes()
+		if i == 2 {
+			mock.EXPECT().CallContract(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("call failed")).AnyTimes()
+		} else {
+			mock.EXPECT().CallContract(gomock.Any(), gomock.Any(), gomock.Any()).Return(make([]byte, 96), nil).AnyTimes()
+		}
+	}
+	s := NewL1Syncer(context.Background(), etherMans, nil, nil, 10, 0, "latest")
+
+	require.ErrorContains(t, s.SetQuorum(4, 2), "l1 quorum reads sent to 4 providers with 3 l1 rpc urls")
+	require.ErrorContains(t, s.SetQuorum(2, 3), "l1 quorum of 3 out of 2 providers")
+
+	// everyone agrees on block 10, two out of three on block 20 and the logs
+	require.NoError(t, s.SetQuorum(0, 2))
+	got, err := s.GetHeader(10)
+	require.NoError(t, err)
+	require.Equal(t, header(10, 0).Hash(), got.Hash())
+	for i := 0; i < 3; i++ {
+		got, err = s.GetHeader(20)
+		require.NoError(t, err)
+		require.Equal(t, header(20, 0).Hash(), got.Hash())
+	}
+	gotLogs, err := s.filterLogs(context.Background(), ethereum.FilterQuery{})
+	require.NoError(t, err)
+	require.Equal(t, logs(0), gotLogs)
+	disagreements := metrics.GetOrCreateCounter(`l1_quorum_disagreements_total{method="HeaderByNumber",provider="2"}`)
+	require.Eventually(t, func() bool {
+		return disagreements.GetValue() == 3
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// agreeing that a block is not found stands, failing calls don't vote
+	_, err = s.GetHeader(30)
+	require.ErrorIs(t, err, ethereum.NotFound)
+	_, err = s.GetElderberryAccInputHash(context.Background(), &addr, 1, 1)
+	require.NoError(t, err)
+
+	// with all three needed nothing stands once one disagrees or fails
+	require.NoError(t, s.SetQuorum(3, 3))
+	_, err = s.GetHeader(10)
+	require.NoError(t, err)
+	_, err = s.GetHeader(20)
+	require.ErrorIs(t, err, ErrNoL1Quorum)
+	_, err = s.GetElderberryAccInputHash(context.Background(), &addr, 1, 1)
+	require.ErrorIs(t, err, ErrNoL1Quorum)
+
+	// a quorum of 1 trusts whichever provider is next
+	require.NoError(t, s.SetQuorum(0, 1))
+	require.False(t, s.quorumEnabled())
+}
@@ -0,0 +1,76 @@
+package syncer
+
+import (
+	"errors"
+	"sort"
+
+	ethereum "github.com/ledgerwatch/erigon"
+	"github.com/ledgerwatch/erigon-lib/common"
+	ethTypes "github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/zk/hermez_db"
+)
+
+// L1ReorgDepth is how many blocks back from the last checked block the hashes of the L1 blocks data was taken from are
+// kept.  A reorg of the L1 deeper than that unwinds all the data taken from the blocks recorded
+const L1ReorgDepth = 1024
+
+// RecordL1BlockHashes records in the L1 block hash table the hashes of the blocks the logs are in and the hash of the
+// last checked block, if any, dropping the ones older than L1ReorgDepth blocks
+func RecordL1BlockHashes(hermezDb *hermez_db.HermezDb, table string, logs []ethTypes.Log, lastChecked *ethTypes.Header) error {
+	for _, l := range logs {
+		// a log without a block hash can't be checked against the chain later
+		if l.BlockHash == (common.Hash{}) {
+			continue
+		}
+		if err := hermezDb.WriteL1BlockHash(table, l.BlockNumber, l.BlockHash); err != nil {
+			return err
+		}
+	}
+
+	if lastChecked == nil {
+		return nil
+	}
+	if err := hermezDb.WriteL1BlockHash(table, lastChecked.Number.Uint64(), lastChecked.Hash()); err != nil {
+		return err
+	}
+	if lastChecked.Number.Uint64() > L1ReorgDepth {
+		return hermezDb.PruneL1BlockHashes(table, lastChecked.Number.Uint64()-L1ReorgDepth)
+	}
+
+	return nil
+}
+
+// FindL1ForkPoint compares the hashes recorded for the L1 blocks data was taken from with the hashes of the blocks on
+// the L1 now, from the highest block down.  When the highest one still matches, the L1 hasn't reorganised past any of
+// them.  Otherwise the fork point is the highest block that still matches, all the data taken up to it stands.  When
+// none of them match it is the block before the lowest one, or an error when the lowest one is the L1 genesis
+func (s *L1Syncer) FindL1ForkPoint(hashes map[uint64]common.Hash) (forkBlock uint64, reorged bool, err error) {
+	blockNos := make([]uint64, 0, len(hashes))
+	for blockNo := range hashes {
+		blockNos = append(blockNos, blockNo)
+	}
+	sort.Slice(blockNos, func(i, j int) bool {
+		return blockNos[i] > blockNos[j]
+	})
+
+	for i, blockNo := range blockNos {
+		header, err := s.GetHeader(blockNo)
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return 0, false, err
+		}
+		// a block no longer found is past the head of a shorter chain
+		if header != nil && header.Hash() == hashes[blockNo] {
+			return blockNo, i > 0, nil
+		}
+	}
+
+	if len(blockNos) == 0 {
+		return 0, false, nil
+	}
+	lowest := blockNos[len(blockNos)-1]
+	// there is no block before the genesis of the L1 to unwind to, it can't be reorganised past
+	if lowest == 0 {
+		return 0, false, errors.New("the L1 block 0 doesn't match the hash recorded, the L1 is a different chain")
+	}
+	return lowest - 1, true, nil
+}
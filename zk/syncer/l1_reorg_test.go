@@ -0,0 +1,62 @@
+package syncer
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	ethereum "github.com/ledgerwatch/erigon"
+	"github.com/ledgerwatch/erigon-lib/common"
+	ethTypes "github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/zk/syncer/mocks"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestFindL1ForkPoint(t *testing.T) {
+	// the L1 forked after block 20, block 40 is gone with the reorg
+	header := func(number int64, fork byte) *ethTypes.Header {
+		return &ethTypes.Header{Number: big.NewInt(number), Extra: []byte{fork}}
+	}
+	recorded := map[uint64]common.Hash{}
+	for _, number := range []int64{10, 20, 30, 40} {
+		recorded[uint64(number)] = header(number, 0).Hash()
+	}
+
+	mockCtrl := gomock.NewController(t)
+	etherman := mocks.NewMockIEtherman(mockCtrl)
+	etherman.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(40)).Return(nil, ethereum.NotFound).AnyTimes()
+	etherman.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(30)).Return(header(30, 1), nil).AnyTimes()
+	etherman.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(20)).Return(header(20, 0), nil).AnyTimes()
+	etherman.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(10)).Return(header(10, 0), nil).AnyTimes()
+	s := NewL1Syncer(context.Background(), []IEtherman{etherman}, nil, nil, 10, 0, "latest")
+
+	forkBlock, reorged, err := s.FindL1ForkPoint(recorded)
+	require.NoError(t, err)
+	require.True(t, reorged)
+	require.Equal(t, uint64(20), forkBlock)
+
+	// the highest block still on the chain, nothing to unwind
+	delete(recorded, 30)
+	delete(recorded, 40)
+	forkBlock, reorged, err = s.FindL1ForkPoint(recorded)
+	require.NoError(t, err)
+	require.False(t, reorged)
+	require.Equal(t, uint64(20), forkBlock)
+
+	// none of them on the chain, all are unwound
+	forkBlock, reorged, err = s.FindL1ForkPoint(map[uint64]common.Hash{30: recorded[20], 40: recorded[20]})
+	require.NoError(t, err)
+	require.True(t, reorged)
+	require.Equal(t, uint64(29), forkBlock)
+
+	// the L1 genesis no longer on the chain, there is nothing before it to unwind to
+	etherman.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(0)).Return(header(0, 1), nil).AnyTimes()
+	_, _, err = s.FindL1ForkPoint(map[uint64]common.Hash{0: header(0, 0).Hash(), 30: recorded[20]})
+	require.Error(t, err)
+
+	// nothing recorded yet
+	_, reorged, err = s.FindL1ForkPoint(nil)
+	require.NoError(t, err)
+	require.False(t, reorged)
+}
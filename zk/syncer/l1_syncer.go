@@ -33,6 +33,7 @@ const (
 	admin                           = "0xf851a440"
 	trustedSequencer                = "0xcfa8ed47"
 	sequencedBatchesMapSignature    = "0xb4d63f58"
+	forceBatchTimeout               = "0xc754c7ed"
 )
 
 //go:generate mockgen -typed=true -destination=./mocks/etherman_mock.go -package=mocks . IEtherman
@@ -68,14 +69,21 @@ type L1Syncer struct {
 	blockRange          uint64
 	queryDelay          uint64
 
-	latestL1Block uint64
+	// the reads checked by a quorum go to quorumProviders of the etherMans and need quorum of them to agree, see SetQuorum
+	quorumProviders int
+	quorum          int
+
+	latestL1Block       uint64
+	latestL1BlockHeader *ethTypes.Header
 
 	// atomic
 	isSyncStarted      atomic.Bool
 	isDownloading      atomic.Bool
 	lastCheckedL1Block atomic.Uint64
-	wgRunLoopDone      sync.WaitGroup
-	flagStop           atomic.Bool
+	// the header of the last checked block as it was when its logs were fetched
+	lastCheckedL1Header atomic.Pointer[ethTypes.Header]
+	wgRunLoopDone       sync.WaitGroup
+	flagStop            atomic.Bool
 
 	// Channels
 	logsChan         chan []ethTypes.Log
@@ -126,6 +134,12 @@ func (s *L1Syncer) GetLastCheckedL1Block() uint64 {
 	return s.lastCheckedL1Block.Load()
 }
 
+// GetLastCheckedL1Header is the header of the last checked block as it was when the logs up to it were fetched, nil
+// until the first blocks are checked
+func (s *L1Syncer) GetLastCheckedL1Header() *ethTypes.Header {
+	return s.lastCheckedL1Header.Load()
+}
+
 func (s *L1Syncer) StopQueryBlocks() {
 	s.flagStop.Store(true)
 }
@@ -168,6 +182,7 @@ func (s *L1Syncer) RunQueryBlocks(lastCheckedBlock uint64) {
 	// set it to true to catch the first cycle run case where the check can pass before the latest block is checked
 	s.isDownloading.Store(true)
 	s.lastCheckedL1Block.Store(lastCheckedBlock)
+	s.lastCheckedL1Header.Store(nil)
 
 	s.wgRunLoopDone.Add(1)
 	s.flagStop.Store(false)
@@ -195,6 +210,7 @@ func (s *L1Syncer) RunQueryBlocks(lastCheckedBlock uint64) {
 						log.Error("Error querying blocks", "err", err)
 					} else {
 						s.lastCheckedL1Block.Store(latestL1Block)
+						s.lastCheckedL1Header.Store(s.latestL1BlockHeader)
 					}
 				}
 			}
@@ -207,7 +223,7 @@ func (s *L1Syncer) RunQueryBlocks(lastCheckedBlock uint64) {
 
 func (s *L1Syncer) GetHeader(number uint64) (*ethTypes.Header, error) {
 	em := s.getNextEtherman()
-	return em.HeaderByNumber(s.ctx, new(big.Int).SetUint64(number))
+	return s.headerByNumber(s.ctx, em, number)
 }
 
 func (s *L1Syncer) GetBlock(number uint64) (*ethTypes.Block, error) {
@@ -277,7 +293,7 @@ func (s *L1Syncer) L1QueryHeaders(logs []ethTypes.Log) (map[uint64]*ethTypes.Hea
 			if !ok {
 				break
 			}
-			header, err := em.HeaderByNumber(ctx, new(big.Int).SetUint64(l.BlockNumber))
+			header, err := s.headerByNumber(ctx, em, l.BlockNumber)
 			if err != nil {
 				log.Error("Error getting block", "err", err)
 				// assume a transient error and try again
@@ -328,7 +344,15 @@ func (s *L1Syncer) getLatestL1Block() (uint64, error) {
 	}
 
 	latest := latestBlock.NumberU64()
+	header := latestBlock.Header()
+	if s.quorumEnabled() {
+		// how far to sync is up to the provider asked, but the header the reorg checks start from has to be agreed on
+		if header, err = s.headerByNumber(context.Background(), em, latest); err != nil {
+			return 0, err
+		}
+	}
 	s.latestL1Block = latest
+	s.latestL1BlockHeader = header
 
 	return latest, nil
 }
@@ -446,8 +470,7 @@ func (s *L1Syncer) getSequencedLogs(jobs <-chan fetchJob, results chan jobResult
 			var err error
 			retry := 0
 			for {
-				em := s.getNextEtherman()
-				logs, err = em.FilterLogs(context.Background(), query)
+				logs, err = s.filterLogs(context.Background(), query)
 				if err != nil {
 					log.Debug("getSequencedLogs retry error", "err", err)
 					retry++
@@ -504,11 +527,10 @@ func (s *L1Syncer) callGetRollupSequencedBatches(ctx context.Context, addr *comm
 	rollupID := fmt.Sprintf("%064x", rollupId)
 	batchNumber := fmt.Sprintf("%064x", batchNum)
 
-	em := s.getNextEtherman()
-	resp, err := em.CallContract(ctx, ethereum.CallMsg{
+	resp, err := s.callContract(ctx, "GetElderberryAccInputHash", ethereum.CallMsg{
 		To:   addr,
 		Data: common.FromHex(rollupSequencedBatchesSignature + rollupID + batchNumber),
-	}, nil)
+	})
 
 	if err != nil {
 		return common.Hash{}, 0, err
@@ -543,6 +565,25 @@ func (s *L1Syncer) CallTrustedSequencer(ctx context.Context, addr *common.Addres
 	return s.callGetAddress(ctx, addr, trustedSequencer)
 }
 
+// CallForceBatchTimeout returns the time in seconds after which anyone can sequence a forced batch
+func (s *L1Syncer) CallForceBatchTimeout(ctx context.Context, addr *common.Address) (uint64, error) {
+	em := s.getNextEtherman()
+	resp, err := em.CallContract(ctx, ethereum.CallMsg{
+		To:   addr,
+		Data: common.FromHex(forceBatchTimeout),
+	}, nil)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if len(resp) < 32 {
+		return 0, errorShortResponseLT32
+	}
+
+	return new(big.Int).SetBytes(resp[:32]).Uint64(), nil
+}
+
 func (s *L1Syncer) callGetAddress(ctx context.Context, addr *common.Address, data string) (common.Address, error) {
 	em := s.getNextEtherman()
 	resp, err := em.CallContract(ctx, ethereum.CallMsg{
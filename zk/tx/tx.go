@@ -77,6 +77,12 @@ func DecodeBatchL2Blocks(txsData []byte, forkID uint64) ([]DecodedBatchL2Data, e
 
 		// if num is 11 then we are trying to parse a `changeL2Block` transaction
 		if num == changeL2BlockTxType {
+			// the data can come from anyone forcing a batch on the L1, so a change block cut short is invalid data
+			if pos+changeL2BlockLength > txDataLength {
+				log.Debug("error parsing change l2 block: ", "pos", pos, "txDataLength", txDataLength)
+				return result, ErrInvalidData
+			}
+
 			hasStarted := pos > 0
 
 			// if we aren't at the very start of the data then we know we're at a block boundary, so we want to capture
@@ -110,8 +116,8 @@ func DecodeBatchL2Blocks(txsData []byte, forkID uint64) ([]DecodedBatchL2Data, e
 		if length > shortRlp { // If rlp is bigger than length 55
 			// n is the length of the rlp data without the header (1 byte) for example "0xf7"
 			if (pos + 1 + num - f7) > txDataLength {
-				log.Debug("error parsing length: ", err)
-				return result, err
+				log.Debug("error parsing length: ", "pos", pos, "txDataLength", txDataLength)
+				return result, ErrInvalidData
 			}
 			n, err := strconv.ParseUint(hex.EncodeToString(txsData[pos+1:pos+1+num-f7]), hex.Base, hex.BitSize64) // +1 is the header. For example 0xf7
 			if err != nil {
@@ -527,3 +527,21 @@ func Test_ComplexMixOfBlocks(t *testing.T) {
 		}
 	}
 }
+
+func Test_TruncatedChangeL2Block(t *testing.T) {
+	for _, testData := range []string{
+		"0b",
+		"0b0000",
+		"0b000001f4000000",
+		"0b000001f4000000000b00",
+		// a transaction with a length of length prefix cut short
+		"f9",
+	} {
+		t.Run(testData, func(t *testing.T) {
+			decoded, err := hex.DecodeString(testData)
+			require.NoError(t, err)
+			_, err = DecodeBatchL2Blocks(decoded, 8)
+			require.ErrorIs(t, err, ErrInvalidData)
+		})
+	}
+}
@@ -135,9 +135,12 @@ const (
 	SendTx Policy = iota
 	// Deploy is the name of the policy that governs that an address may deploy a contract
 	Deploy
+	// Priority is the name of the policy that lets the transactions of an address use the share of the batch the
+	// sequencer reserves for priority transactions
+	Priority
 )
 
-var policiesList = []Policy{SendTx, Deploy}
+var policiesList = []Policy{SendTx, Deploy, Priority}
 
 func (p Policy) ToByte() byte {
 	return byte(p)
@@ -150,7 +153,7 @@ func (p Policy) ToByteArray() []byte {
 // IsSupportedPolicy checks if the given policy is supported
 func IsSupportedPolicy(policy Policy) bool {
 	switch policy {
-	case SendTx, Deploy:
+	case SendTx, Deploy, Priority:
 		return true
 	default:
 		return false
@@ -163,6 +166,8 @@ func ResolvePolicy(policy string) (Policy, error) {
 		return SendTx, nil
 	case "deploy":
 		return Deploy, nil
+	case "priority":
+		return Priority, nil
 	default:
 		return SendTx, errUnknownPolicy
 	}
@@ -208,6 +213,8 @@ func policyName(policy Policy) string {
 		return "sendTx"
 	case Deploy:
 		return "deploy"
+	case Priority:
+		return "priority"
 	default:
 		return "unknown"
 	}
@@ -726,3 +733,21 @@ func (p *TxPool) isActionAllowed(ctx context.Context, addr common.Address, polic
 		return hasPolicy, nil
 	}
 }
+
+// HasPriority checks if the given address has the priority policy on the allowlist, whatever the ACL mode is
+func (p *TxPool) HasPriority(ctx context.Context, addr common.Address) (bool, error) {
+	var hasPriority bool
+	err := p.aclDB.View(ctx, func(tx kv.Tx) error {
+		value, err := tx.GetOne(Allowlist, addr.Bytes())
+		if err != nil {
+			return err
+		}
+		hasPriority = containsPolicy(value, Priority)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return hasPriority, nil
+}
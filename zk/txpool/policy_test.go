@@ -648,6 +648,32 @@ func TestIsActionAllowed(t *testing.T) {
 	})
 }
 
+func TestHasPriority(t *testing.T) {
+	db := newTestACLDB(t, "")
+	ctx := context.Background()
+
+	txPool := &TxPool{aclDB: db}
+	addr := common.HexToAddress("0x1234567890abcdef")
+
+	hasPriority, err := txPool.HasPriority(ctx, addr)
+	require.NoError(t, err)
+	require.False(t, hasPriority)
+
+	// read from the allowlist even with the ACL disabled
+	require.NoError(t, SetMode(ctx, db, DisabledMode))
+	require.NoError(t, AddPolicy(ctx, db, "allowlist", addr, Priority))
+	hasPriority, err = txPool.HasPriority(ctx, addr)
+	require.NoError(t, err)
+	require.True(t, hasPriority)
+
+	// the blocklist plays no part
+	other := common.HexToAddress("0xabcdef1234567890")
+	require.NoError(t, AddPolicy(ctx, db, "blocklist", other, Priority))
+	hasPriority, err = txPool.HasPriority(ctx, other)
+	require.NoError(t, err)
+	require.False(t, hasPriority)
+}
+
 func TestListContentAtACL(t *testing.T) {
 	db := newTestACLDB(t, "")
 	ctx := context.Background()
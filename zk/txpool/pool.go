@@ -151,6 +151,8 @@ const (
 	SmartContractDeploymentDisabled DiscardReason = 28 // to == null not allowed, config set to block smart contract deployment
 	GasLimitTooHigh                 DiscardReason = 29 // gas limit is too high
 	Expired                         DiscardReason = 30 // used when a transaction is purged from the pool
+	BundleIncomplete                DiscardReason = 31 // another transaction of the same bundle left the pool so the bundle can't be included as a whole
+	DeadlinePassed                  DiscardReason = 32 // the deadline the transaction was sent with passed before it was included
 
 	// For X Layer
 	ReceiverDisallowedReceiveTx DiscardReason = 127 // receiver is not allowed to receive transactions
@@ -223,6 +225,12 @@ func (r DiscardReason) String() string {
 		return "smart contract deployment disabled"
 	case GasLimitTooHigh:
 		return fmt.Sprintf("gas limit too high. Max: %d", transactionGasLimit)
+	case Expired:
+		return "expired"
+	case BundleIncomplete:
+		return "bundle incomplete"
+	case DeadlinePassed:
+		return "deadline passed"
 	default:
 		panic(fmt.Sprintf("discard reason: %d", r))
 	}
@@ -239,13 +247,14 @@ type metaTx struct {
 	worstIndex                int
 	timestamp                 uint64 // when it was added to pool
 	created                   uint64 // unix timestamp of creation
+	arrival                   uint64 // order of arrival in the pool
 	subPool                   SubPoolMarker
 	currentSubPool            SubPoolType
 	alreadyYielded            bool
 }
 
 func newMetaTx(slot *types.TxSlot, isLocal bool, timestmap uint64) *metaTx {
-	mt := &metaTx{Tx: slot, worstIndex: -1, bestIndex: -1, timestamp: timestmap, created: uint64(time.Now().Unix())}
+	mt := &metaTx{Tx: slot, worstIndex: -1, bestIndex: -1, timestamp: timestmap, created: uint64(time.Now().Unix()), arrival: arrivalCounter.Add(1)}
 	if isLocal {
 		mt.subPool = IsLocal
 	}
@@ -327,6 +336,7 @@ type TxPool struct {
 	cfg                     txpoolcfg.Config
 	chainID                 uint256.Int
 	lastSeenBlock           atomic.Uint64
+	lastSeenBlockTime       atomic.Uint64
 	started                 atomic.Bool
 	pendingBaseFee          atomic.Uint64
 	blockGasLimit           atomic.Uint64
@@ -336,6 +346,9 @@ type TxPool struct {
 	isPostShanghai          atomic.Bool
 	ethCfg                  *ethconfig.Config
 	aclDB                   kv.RwDB
+	orderingPolicy          TxOrderingPolicy
+	bundleByTx              map[common.Hash]*Bundle
+	deadlineByTx            map[common.Hash]Deadline
 
 	// For X Layer
 	xlayerCfg    XLayerConfig
@@ -375,6 +388,15 @@ func New(newTxs chan types.Announcements, coreDB kv.RoDB, cfg txpoolcfg.Config,
 		search:           &metaTx{Tx: &types.TxSlot{}},
 		senderIDTxnCount: map[uint64]int{},
 	}
+	var orderingPolicyName string
+	if ethCfg.Zk != nil {
+		orderingPolicyName = ethCfg.Zk.SequencerTxOrderingPolicy
+	}
+	orderingPolicy, err := NewTxOrderingPolicy(orderingPolicyName)
+	if err != nil {
+		return nil, err
+	}
+
 	tracedSenders := make(map[common.Address]struct{})
 	for _, sender := range cfg.TracedSenders {
 		tracedSenders[common.BytesToAddress([]byte(sender))] = struct{}{}
@@ -403,6 +425,9 @@ func New(newTxs chan types.Announcements, coreDB kv.RoDB, cfg txpoolcfg.Config,
 		ethCfg:                  ethCfg,
 		flushMtx:                &sync.Mutex{},
 		aclDB:                   aclDB,
+		orderingPolicy:          orderingPolicy,
+		bundleByTx:              map[common.Hash]*Bundle{},
+		deadlineByTx:            map[common.Hash]Deadline{},
 		limbo:                   newLimbo(),
 		// X Layer config
 		xlayerCfg: XLayerConfig{
@@ -437,6 +462,7 @@ func (p *TxPool) OnNewBlock(ctx context.Context, stateChanges *remote.StateChang
 	defer p.lock.Unlock()
 
 	p.lastSeenBlock.Store(stateChanges.ChangeBatch[len(stateChanges.ChangeBatch)-1].BlockHeight)
+	p.setLastSeenBlockTimeLocked(coreTx)
 	if !p.started.Load() {
 		if err := p.fromDB(ctx, tx, coreTx); err != nil {
 			return fmt.Errorf("loading txs from DB: %w", err)
@@ -988,6 +1014,12 @@ func fillDiscardReasons(reasons []DiscardReason, newTxs types.TxSlots, discardRe
 }
 
 func (p *TxPool) AddLocalTxs(ctx context.Context, newTransactions types.TxSlots, tx kv.Tx) ([]DiscardReason, error) {
+	return p.addLocalTxs(ctx, newTransactions, tx, nil)
+}
+
+// addLocalTxs adds the transactions with the given deadlines, which are set under the same lock the transactions enter
+// the pool with so that they are never yielded without them
+func (p *TxPool) addLocalTxs(ctx context.Context, newTransactions types.TxSlots, tx kv.Tx, deadlines map[common.Hash]Deadline) ([]DiscardReason, error) {
 	coreTx, err := p.coreDB().BeginRo(ctx)
 	if err != nil {
 		return nil, err
@@ -1020,6 +1052,9 @@ func (p *TxPool) AddLocalTxs(ctx context.Context, newTransactions types.TxSlots,
 		return nil, err
 	}
 
+	for txHash, deadline := range deadlines {
+		p.deadlineByTx[txHash] = deadline
+	}
 	announcements, addReasons, err := p.addTxs(p.lastSeenBlock.Load(), cacheView, p.senders, newTxs,
 		p.pendingBaseFee.Load(), p.blockGasLimit.Load(), p.pending, p.baseFee, p.queued, p.all, p.byHash, p.addLocked, p.discardLocked, true)
 	if err == nil {
@@ -1029,12 +1064,16 @@ func (p *TxPool) AddLocalTxs(ctx context.Context, newTransactions types.TxSlots,
 			}
 		}
 	} else {
+		for txHash := range deadlines {
+			delete(p.deadlineByTx, txHash)
+		}
 		return nil, err
 	}
 	p.promoted.Reset()
 	p.promoted.AppendOther(announcements)
 
 	reasons = fillDiscardReasons(reasons, newTxs, p.discardReasonsLRU)
+	p.dropDeadlinesLocked(newTransactions, reasons, deadlines)
 	for i, reason := range reasons {
 		if reason == Success {
 			txn := newTxs.Txs[i]
@@ -1688,6 +1727,12 @@ func (p *TxPool) flushLocked(tx kv.RwTx) (err error) {
 	if err := p.flushLockedLimbo(tx); err != nil {
 		return err
 	}
+	if err := p.flushLockedBundles(tx); err != nil {
+		return err
+	}
+	if err := p.flushLockedDeadlines(tx); err != nil {
+		return err
+	}
 
 	// clean - in-memory data structure as later as possible - because if during this Tx will happen error,
 	// DB will stay consistent but some in-memory structures may be already cleaned, and retry will not work
@@ -1787,6 +1832,12 @@ func (p *TxPool) fromDB(ctx context.Context, tx kv.Tx, coreTx kv.Tx) error {
 		return err
 	}
 	p.pendingBaseFee.Store(pendingBaseFee)
+	if err := p.fromDBDeadlines(tx); err != nil {
+		return err
+	}
+	if err := p.fromDBBundles(tx); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -223,15 +223,19 @@ func (p *TxPool) best(n uint16, txs *types.TxsRlp, tx kv.Tx, onTopOf, availableG
 	var toRemove []*metaTx
 	count := 0
 
+	// the transactions are yielded for the block on top of onTopOf
+	p.pruneDeadlinesLocked(onTopOf+1, p.parentTimeLocked(onTopOf))
+	p.pruneBundlesLocked()
 	p.pending.EnforceBestInvariants()
+	ordered := p.orderingPolicy.Order(best.ms)
 
-	for i := 0; count < int(n) && i < len(best.ms); i++ {
+	for i := 0; count < int(n) && i < len(ordered); i++ {
 		// if we wouldn't have enough gas for a standard transaction then quit out early
 		if availableGas < fixedgas.TxGas {
 			break
 		}
 
-		mt := best.ms[i]
+		mt := ordered[i]
 		//log.Trace("Processing transaction", "txID", mt.Tx.IDHash)
 
 		if toSkip.Contains(mt.Tx.IDHash) {
@@ -239,6 +243,17 @@ func (p *TxPool) best(n uint16, txs *types.TxsRlp, tx kv.Tx, onTopOf, availableG
 			continue
 		}
 
+		if bundle, ok := p.bundleByTx[mt.Tx.IDHash]; ok {
+			// the transactions of a bundle are only ever yielded together and in the order of the bundle
+			added, bundleGas, err := p.bestBundleLocked(bundle, int(n), count, txs, tx, availableGas, isShanghai, toSkip)
+			if err != nil {
+				return false, count, err
+			}
+			availableGas -= bundleGas
+			count += added
+			continue
+		}
+
 		if !isLondon && mt.Tx.Type == 0x2 {
 			// remove ldn txs when not in london
 			toRemove = append(toRemove, mt)
@@ -0,0 +1,285 @@
+package txpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/length"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/types"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/log/v3"
+)
+
+/*
+bundles are ordered sets of transactions that the sequencer must include in the same block, in order, or not at all.
+The pool holds on to the membership here and only ever yields the transactions of a bundle together.  The membership is
+flushed to the pool db along with the transactions, so that a restart never reloads them as independent transactions.
+*/
+
+const maxBundleSize = 32
+
+var (
+	ErrBundleEmpty    = errors.New("bundle has no transactions")
+	ErrBundleTooLarge = fmt.Errorf("bundle has more than %d transactions", maxBundleSize)
+)
+
+type Bundle struct {
+	Hash     common.Hash
+	TxHashes []common.Hash
+
+	// set once all transactions of the bundle have been added to the pool, until then the bundle is never yielded
+	ready bool
+}
+
+func newBundle(txHashes []common.Hash) *Bundle {
+	hashes := make([][]byte, len(txHashes))
+	for i := range txHashes {
+		hashes[i] = txHashes[i][:]
+	}
+	return &Bundle{Hash: crypto.Keccak256Hash(hashes...), TxHashes: txHashes}
+}
+
+// AddLocalBundle parses the transactions and adds them to the pool as a bundle.  Either all the transactions are
+// accepted or none of them are kept in the pool.
+func (p *TxPool) AddLocalBundle(ctx context.Context, rlpTxs [][]byte) (common.Hash, error) {
+	if len(rlpTxs) == 0 {
+		return common.Hash{}, ErrBundleEmpty
+	}
+	if len(rlpTxs) > maxBundleSize {
+		return common.Hash{}, ErrBundleTooLarge
+	}
+	if !p.Started() {
+		return common.Hash{}, errors.New("txpool not started")
+	}
+
+	parseCtx := types.NewTxParseContext(p.chainID).ChainIDRequired()
+	parseCtx.ValidateRLP(p.ValidateSerializedTxn)
+
+	var slots types.TxSlots
+	txHashes := make([]common.Hash, len(rlpTxs))
+	for i, rlpTx := range rlpTxs {
+		slot := &types.TxSlot{}
+		sender := common.Address{}
+		if _, err := parseCtx.ParseTransaction(rlpTx, 0, slot, sender[:], false /* hasEnvelope */, false, nil); err != nil {
+			return common.Hash{}, fmt.Errorf("bundle transaction %d: %w", i, err)
+		}
+		slots.Append(slot, sender[:], true)
+		txHashes[i] = slot.IDHash
+	}
+
+	bundle := newBundle(txHashes)
+	if err := p.registerBundle(bundle); err != nil {
+		return common.Hash{}, err
+	}
+
+	// the pool has already been started so there is no need for a pool db transaction
+	reasons, err := p.AddLocalTxs(ctx, slots, nil)
+	if err != nil {
+		p.dropBundle(bundle)
+		return common.Hash{}, err
+	}
+
+	for i, reason := range reasons {
+		if reason != Success {
+			p.dropBundle(bundle)
+			return common.Hash{}, fmt.Errorf("bundle transaction %d %x: %s", i, txHashes[i], reason)
+		}
+	}
+
+	p.lock.Lock()
+	bundle.ready = true
+	p.lock.Unlock()
+
+	return bundle.Hash, nil
+}
+
+// GetBundleByTx returns the bundle the transaction is part of, if any
+func (p *TxPool) GetBundleByTx(txHash common.Hash) (*Bundle, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	bundle, ok := p.bundleByTx[txHash]
+	return bundle, ok
+}
+
+func (p *TxPool) registerBundle(bundle *Bundle) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, txHash := range bundle.TxHashes {
+		if _, ok := p.bundleByTx[txHash]; ok {
+			return fmt.Errorf("transaction %x is already part of a bundle", txHash)
+		}
+	}
+	for _, txHash := range bundle.TxHashes {
+		p.bundleByTx[txHash] = bundle
+	}
+
+	return nil
+}
+
+// dropBundle removes a bundle that could not be added as a whole along with any of its transactions that did make
+// it into the pool
+func (p *TxPool) dropBundle(bundle *Bundle) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.discardBundleLocked(bundle)
+}
+
+// pruneBundlesLocked forgets about bundles whose transactions have all left the pool and discards what is left of
+// bundles that lost a transaction, they can no longer be included as a whole
+func (p *TxPool) pruneBundlesLocked() {
+	seen := make(map[common.Hash]struct{})
+	for _, bundle := range p.bundleByTx {
+		if _, ok := seen[bundle.Hash]; ok || !bundle.ready {
+			continue
+		}
+		seen[bundle.Hash] = struct{}{}
+
+		present := 0
+		for _, txHash := range bundle.TxHashes {
+			if _, ok := p.byHash[string(txHash[:])]; ok {
+				present++
+			}
+		}
+
+		if present == len(bundle.TxHashes) {
+			continue
+		}
+		if present > 0 {
+			log.Info("[txpool] Discarding incomplete bundle", "bundle", bundle.Hash, "remaining", present)
+		}
+		p.discardBundleLocked(bundle)
+	}
+}
+
+func (p *TxPool) discardBundleLocked(bundle *Bundle) {
+	for _, txHash := range bundle.TxHashes {
+		if p.bundleByTx[txHash] == bundle {
+			delete(p.bundleByTx, txHash)
+		}
+
+		mt, ok := p.byHash[string(txHash[:])]
+		if !ok {
+			continue
+		}
+		switch mt.currentSubPool {
+		case PendingSubPool:
+			p.pending.Remove(mt)
+		case BaseFeeSubPool:
+			p.baseFee.Remove(mt)
+		case QueuedSubPool:
+			p.queued.Remove(mt)
+		default:
+			//already removed
+		}
+		p.discardLocked(mt, BundleIncomplete)
+	}
+}
+
+// flushLockedBundles writes the bundles to the pool db in the same db transaction as their transactions.  Bundles that
+// are not ready yet are written too, their transactions may already be flushed.
+func (p *TxPool) flushLockedBundles(tx kv.RwTx) error {
+	if err := tx.ClearBucket(kv.PoolBundle); err != nil {
+		return err
+	}
+
+	seen := make(map[common.Hash]struct{})
+	for _, bundle := range p.bundleByTx {
+		if _, ok := seen[bundle.Hash]; ok {
+			continue
+		}
+		seen[bundle.Hash] = struct{}{}
+
+		v := make([]byte, 0, len(bundle.TxHashes)*length.Hash)
+		for _, txHash := range bundle.TxHashes {
+			v = append(v, txHash[:]...)
+		}
+		if err := tx.Put(kv.PoolBundle, bundle.Hash[:], v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fromDBBundles restores the bundles of the transactions loaded from the pool db.  A bundle is only kept if all of its
+// transactions were loaded, what is left of the others is discarded.
+func (p *TxPool) fromDBBundles(tx kv.Tx) error {
+	it, err := tx.Range(kv.PoolBundle, nil, nil)
+	if err != nil {
+		return err
+	}
+	for it.HasNext() {
+		k, v, err := it.Next()
+		if err != nil {
+			return err
+		}
+		if len(k) != length.Hash || len(v) == 0 || len(v)%length.Hash != 0 {
+			log.Warn("[txpool] fromDB: invalid bundle", "key", fmt.Sprintf("%x", k))
+			continue
+		}
+
+		txHashes := make([]common.Hash, len(v)/length.Hash)
+		for i := range txHashes {
+			txHashes[i] = common.BytesToHash(v[i*length.Hash : (i+1)*length.Hash])
+		}
+		bundle := newBundle(txHashes)
+		// all of the transactions had been accepted if they are all back in the pool
+		bundle.ready = true
+		for _, txHash := range txHashes {
+			p.bundleByTx[txHash] = bundle
+		}
+	}
+
+	p.pruneBundlesLocked()
+	return nil
+}
+
+// bestBundleLocked adds the transactions of the bundle, in order, if all of them are pending and fit into what is
+// left to yield.  It returns the number of transactions added and the gas they take.
+func (p *TxPool) bestBundleLocked(bundle *Bundle, n, count int, txs *types.TxsRlp, tx kv.Tx, availableGas uint64, isShanghai bool, toSkip mapset.Set[[32]byte]) (int, uint64, error) {
+	if !bundle.ready || count+len(bundle.TxHashes) > n {
+		return 0, 0, nil
+	}
+
+	members := make([]*metaTx, len(bundle.TxHashes))
+	var bundleGas uint64
+	for i, txHash := range bundle.TxHashes {
+		mt, ok := p.byHash[string(txHash[:])]
+		if !ok || mt.currentSubPool != PendingSubPool || toSkip.Contains(mt.Tx.IDHash) || mt.Tx.Gas > transactionGasLimit {
+			return 0, 0, nil
+		}
+		intrinsicGas, _ := CalcIntrinsicGas(uint64(mt.Tx.DataLen), uint64(mt.Tx.DataNonZeroLen), nil, mt.Tx.Creation, true, true, isShanghai)
+		bundleGas += intrinsicGas
+		members[i] = mt
+	}
+	if bundleGas > availableGas {
+		return 0, 0, nil
+	}
+
+	for i, mt := range members {
+		rlpTx, sender, isLocal, err := p.getRlpLocked(tx, mt.Tx.IDHash[:])
+		if err != nil {
+			return 0, 0, err
+		}
+		if len(rlpTx) == 0 {
+			return 0, 0, nil
+		}
+		txs.Txs[count+i] = rlpTx
+		txs.TxIds[count+i] = mt.Tx.IDHash
+		copy(txs.Senders.At(count+i), sender.Bytes())
+		txs.IsLocal[count+i] = isLocal
+	}
+
+	for _, mt := range members {
+		toSkip.Add(mt.Tx.IDHash)
+	}
+
+	return len(members), bundleGas, nil
+}
@@ -0,0 +1,132 @@
+package txpool
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon-lib/txpool/txpoolcfg"
+	"github.com/ledgerwatch/erigon-lib/types"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func addPendingTestTx(p *TxPool, id byte, sender uint64, tip uint64) *metaTx {
+	slot := &types.TxSlot{SenderID: sender, Rlp: []byte{id}, Gas: 21000}
+	slot.IDHash[0] = id
+	mt := newMetaTx(slot, false, 0)
+	mt.minTip = tip
+	mt.minFeeCap = *uint256.NewInt(tip)
+	p.byHash[string(slot.IDHash[:])] = mt
+	p.pending.Add(mt)
+	return mt
+}
+
+func yieldTestIds(t *testing.T, p *TxPool, n uint16) []byte {
+	txs := types.TxsRlp{}
+	_, count, err := p.YieldBest(n, &txs, nil, 0, math.MaxUint64, 0, mapset.NewThreadUnsafeSet[[32]byte]())
+	require.NoError(t, err)
+
+	ids := make([]byte, count)
+	for i := range ids {
+		ids[i] = txs.TxIds[i][0]
+	}
+	return ids
+}
+
+func TestBundleYieldedAsAWhole(t *testing.T) {
+	p, err := New(nil, nil, txpoolcfg.DefaultConfig, &ethconfig.Defaults, nil, *uint256.NewInt(1), nil, nil, nil)
+	require.NoError(t, err)
+
+	addPendingTestTx(p, 1, 1, 10)
+	// the second transaction of the bundle pays more than the first, the bundle order has to win
+	addPendingTestTx(p, 2, 2, 1)
+	addPendingTestTx(p, 3, 3, 100)
+
+	bundle := newBundle([]common.Hash{{2}, {3}})
+	require.NoError(t, p.registerBundle(bundle))
+
+	// not yielded until all transactions of the bundle made it into the pool
+	require.Equal(t, []byte{1}, yieldTestIds(t, p, 3))
+
+	bundle.ready = true
+	require.Equal(t, []byte{2, 3, 1}, yieldTestIds(t, p, 3))
+
+	// never split to fit the yield size
+	require.Equal(t, []byte{1}, yieldTestIds(t, p, 1))
+
+	require.Error(t, p.registerBundle(newBundle([]common.Hash{{3}, {4}})), "a transaction can only be part of one bundle")
+
+	found, ok := p.GetBundleByTx(common.Hash{3})
+	require.True(t, ok)
+	require.Equal(t, bundle.Hash, found.Hash)
+}
+
+func TestIncompleteBundleDiscarded(t *testing.T) {
+	p, err := New(nil, nil, txpoolcfg.DefaultConfig, &ethconfig.Defaults, nil, *uint256.NewInt(1), nil, nil, nil)
+	require.NoError(t, err)
+
+	addPendingTestTx(p, 1, 1, 10)
+	addPendingTestTx(p, 2, 2, 10)
+	mined := addPendingTestTx(p, 3, 3, 10)
+
+	bundle := newBundle([]common.Hash{{2}, {3}})
+	require.NoError(t, p.registerBundle(bundle))
+	bundle.ready = true
+
+	// the second transaction leaves the pool on its own, the first must not be included without it
+	p.pending.Remove(mined)
+	delete(p.byHash, string(mined.Tx.IDHash[:]))
+
+	require.Equal(t, []byte{1}, yieldTestIds(t, p, 3))
+
+	_, ok := p.byHash[string(common.Hash{2}.Bytes())]
+	require.False(t, ok)
+	reason, ok := p.discardReasonsLRU.Get(string(common.Hash{2}.Bytes()))
+	require.True(t, ok)
+	require.Equal(t, BundleIncomplete, reason)
+
+	_, ok = p.GetBundleByTx(common.Hash{2})
+	require.False(t, ok)
+}
+
+func TestBundleRestoredFromDB(t *testing.T) {
+	db := memdb.NewTestPoolDB(t)
+
+	p, err := New(nil, nil, txpoolcfg.DefaultConfig, &ethconfig.Defaults, nil, *uint256.NewInt(1), nil, nil, nil)
+	require.NoError(t, err)
+	bundle := newBundle([]common.Hash{{2}, {3}})
+	require.NoError(t, p.registerBundle(bundle))
+	require.NoError(t, db.Update(context.Background(), p.flushLockedBundles))
+
+	// all transactions of the bundle are loaded again
+	restored, err := New(nil, nil, txpoolcfg.DefaultConfig, &ethconfig.Defaults, nil, *uint256.NewInt(1), nil, nil, nil)
+	require.NoError(t, err)
+	addPendingTestTx(restored, 1, 1, 10)
+	addPendingTestTx(restored, 2, 2, 1)
+	addPendingTestTx(restored, 3, 3, 100)
+	require.NoError(t, db.View(context.Background(), restored.fromDBBundles))
+
+	found, ok := restored.GetBundleByTx(common.Hash{3})
+	require.True(t, ok)
+	require.Equal(t, bundle.Hash, found.Hash)
+	require.Equal(t, []byte{2, 3, 1}, yieldTestIds(t, restored, 3))
+
+	// one of them did not make it back, the other must not be included on its own
+	restored, err = New(nil, nil, txpoolcfg.DefaultConfig, &ethconfig.Defaults, nil, *uint256.NewInt(1), nil, nil, nil)
+	require.NoError(t, err)
+	addPendingTestTx(restored, 1, 1, 10)
+	addPendingTestTx(restored, 2, 2, 10)
+	require.NoError(t, db.View(context.Background(), restored.fromDBBundles))
+
+	require.Equal(t, []byte{1}, yieldTestIds(t, restored, 3))
+	reason, ok := restored.discardReasonsLRU.Get(string(common.Hash{2}.Bytes()))
+	require.True(t, ok)
+	require.Equal(t, BundleIncomplete, reason)
+	_, ok = restored.GetBundleByTx(common.Hash{2})
+	require.False(t, ok)
+}
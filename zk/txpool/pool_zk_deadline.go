@@ -0,0 +1,184 @@
+package txpool
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/common/length"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/types"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/log/v3"
+)
+
+/*
+a deadline is the last L2 block and/or the last timestamp a transaction may be included at.  The sequencer skips
+transactions whose deadline passed and the pool discards them, so that a stale transaction is never executed.
+*/
+
+var ErrDeadlinePassed = errors.New("transaction deadline already passed")
+
+type Deadline struct {
+	// the last block the transaction may be included in, 0 for none
+	BlockNumber uint64
+	// the last block timestamp the transaction may be included at, 0 for none
+	Timestamp uint64
+}
+
+// Passed is true if a transaction with this deadline may not be included in a block with the given number and timestamp
+func (d Deadline) Passed(blockNumber, timestamp uint64) bool {
+	return (d.BlockNumber != 0 && blockNumber > d.BlockNumber) || (d.Timestamp != 0 && timestamp > d.Timestamp)
+}
+
+// AddLocalTxWithDeadline parses the transaction and adds it to the pool with the given deadline
+func (p *TxPool) AddLocalTxWithDeadline(ctx context.Context, rlpTx []byte, deadline Deadline) (common.Hash, error) {
+	if !p.Started() {
+		return common.Hash{}, errors.New("txpool not started")
+	}
+	if deadline.Passed(p.lastSeenBlock.Load()+1, uint64(time.Now().Unix())) {
+		return common.Hash{}, ErrDeadlinePassed
+	}
+
+	parseCtx := types.NewTxParseContext(p.chainID).ChainIDRequired()
+	parseCtx.ValidateRLP(p.ValidateSerializedTxn)
+
+	var slots types.TxSlots
+	slot := &types.TxSlot{}
+	sender := common.Address{}
+	if _, err := parseCtx.ParseTransaction(rlpTx, 0, slot, sender[:], false /* hasEnvelope */, false, nil); err != nil {
+		return common.Hash{}, err
+	}
+	slots.Append(slot, sender[:], true)
+	txHash := common.Hash(slot.IDHash)
+
+	// the pool has already been started so there is no need for a pool db transaction
+	reasons, err := p.addLocalTxs(ctx, slots, nil, map[common.Hash]Deadline{txHash: deadline})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if reasons[0] != Success {
+		return common.Hash{}, fmt.Errorf("transaction %x: %s", txHash, reasons[0])
+	}
+
+	return txHash, nil
+}
+
+// dropDeadlinesLocked forgets about the deadlines of the transactions that did not make it into the pool
+func (p *TxPool) dropDeadlinesLocked(txs types.TxSlots, reasons []DiscardReason, deadlines map[common.Hash]Deadline) {
+	if len(deadlines) == 0 {
+		return
+	}
+	for i, reason := range reasons {
+		if reason != Success {
+			delete(p.deadlineByTx, common.Hash(txs.Txs[i].IDHash))
+		}
+	}
+}
+
+// GetDeadline returns the deadline of the transaction, if it has one
+func (p *TxPool) GetDeadline(txHash common.Hash) (Deadline, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	deadline, ok := p.deadlineByTx[txHash]
+	return deadline, ok
+}
+
+// setLastSeenBlockTimeLocked keeps the timestamp of the last seen block, deadlines are checked against block time
+func (p *TxPool) setLastSeenBlockTimeLocked(coreTx kv.Tx) {
+	header := rawdb.ReadHeaderByNumber(coreTx, p.lastSeenBlock.Load())
+	if header == nil {
+		return
+	}
+	p.lastSeenBlockTime.Store(header.Time)
+}
+
+// parentTimeLocked returns the timestamp of the block the next one is built on top of, 0 if it is not known.  The next
+// block is at least as late, so a timestamp deadline before it has passed for sure.
+func (p *TxPool) parentTimeLocked(onTopOf uint64) uint64 {
+	if p.lastSeenBlock.Load() != onTopOf {
+		return 0
+	}
+	return p.lastSeenBlockTime.Load()
+}
+
+// flushLockedDeadlines writes the deadlines of the transactions in the pool to the pool db, next to the transactions
+func (p *TxPool) flushLockedDeadlines(tx kv.RwTx) error {
+	if err := tx.ClearBucket(kv.PoolDeadline); err != nil {
+		return err
+	}
+
+	v := make([]byte, 16)
+	for txHash, deadline := range p.deadlineByTx {
+		if _, ok := p.byHash[string(txHash[:])]; !ok {
+			continue
+		}
+		binary.BigEndian.PutUint64(v[:8], deadline.BlockNumber)
+		binary.BigEndian.PutUint64(v[8:], deadline.Timestamp)
+		if err := tx.Put(kv.PoolDeadline, txHash[:], v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fromDBDeadlines restores the deadlines of the transactions loaded from the pool db
+func (p *TxPool) fromDBDeadlines(tx kv.Tx) error {
+	it, err := tx.Range(kv.PoolDeadline, nil, nil)
+	if err != nil {
+		return err
+	}
+	for it.HasNext() {
+		k, v, err := it.Next()
+		if err != nil {
+			return err
+		}
+		if len(k) != length.Hash || len(v) != 16 {
+			log.Warn("[txpool] fromDB: invalid deadline", "key", fmt.Sprintf("%x", k))
+			continue
+		}
+		if _, ok := p.byHash[string(k)]; !ok {
+			continue
+		}
+		p.deadlineByTx[common.BytesToHash(k)] = Deadline{
+			BlockNumber: binary.BigEndian.Uint64(v[:8]),
+			Timestamp:   binary.BigEndian.Uint64(v[8:]),
+		}
+	}
+
+	return nil
+}
+
+// pruneDeadlinesLocked forgets about the deadlines of transactions that left the pool and discards the transactions
+// that can no longer be included in the given block
+func (p *TxPool) pruneDeadlinesLocked(blockNumber, timestamp uint64) {
+	for txHash, deadline := range p.deadlineByTx {
+		mt, ok := p.byHash[string(txHash[:])]
+		if !ok {
+			delete(p.deadlineByTx, txHash)
+			continue
+		}
+		if !deadline.Passed(blockNumber, timestamp) {
+			continue
+		}
+
+		switch mt.currentSubPool {
+		case PendingSubPool:
+			p.pending.Remove(mt)
+		case BaseFeeSubPool:
+			p.baseFee.Remove(mt)
+		case QueuedSubPool:
+			p.queued.Remove(mt)
+		default:
+			//already removed
+		}
+		p.discardLocked(mt, DeadlinePassed)
+		delete(p.deadlineByTx, txHash)
+		log.Debug("[txpool] Discarding transaction past its deadline", "hash", txHash, "block", blockNumber, "deadline", deadline.BlockNumber, "deadlineTimestamp", deadline.Timestamp)
+	}
+}
@@ -0,0 +1,91 @@
+package txpool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon-lib/txpool/txpoolcfg"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadlinePassed(t *testing.T) {
+	require.False(t, Deadline{}.Passed(100, 1000))
+
+	byBlock := Deadline{BlockNumber: 10}
+	require.False(t, byBlock.Passed(10, 1000))
+	require.True(t, byBlock.Passed(11, 0))
+
+	byTime := Deadline{Timestamp: 1000}
+	require.False(t, byTime.Passed(100, 1000))
+	require.True(t, byTime.Passed(0, 1001))
+
+	both := Deadline{BlockNumber: 10, Timestamp: 1000}
+	require.True(t, both.Passed(11, 999))
+	require.True(t, both.Passed(9, 1001))
+}
+
+func TestTransactionsPastTheirDeadlineAreNotYielded(t *testing.T) {
+	p, err := New(nil, nil, txpoolcfg.DefaultConfig, &ethconfig.Defaults, nil, *uint256.NewInt(1), nil, nil, nil)
+	require.NoError(t, err)
+
+	addPendingTestTx(p, 1, 1, 10)
+	addPendingTestTx(p, 2, 2, 20)
+	addPendingTestTx(p, 3, 3, 30)
+	addPendingTestTx(p, 4, 4, 40)
+
+	// the test pool yields on top of block 0, so for block 1.  Timestamps are checked against the time of block 0, not
+	// the wall clock
+	p.lastSeenBlockTime.Store(1000)
+	p.deadlineByTx[common.Hash{2}] = Deadline{BlockNumber: 1}
+	p.deadlineByTx[common.Hash{3}] = Deadline{Timestamp: 999}
+	p.deadlineByTx[common.Hash{4}] = Deadline{Timestamp: 1000}
+	p.deadlineByTx[common.Hash{5}] = Deadline{BlockNumber: 1}
+
+	require.ElementsMatch(t, []byte{1, 2, 4}, yieldTestIds(t, p, 4))
+
+	reason, ok := p.discardReasonsLRU.Get(string(common.Hash{3}.Bytes()))
+	require.True(t, ok)
+	require.Equal(t, DeadlinePassed, reason)
+	require.Equal(t, "deadline passed", reason.String())
+
+	// the deadlines of the transactions that left the pool are gone with them
+	_, ok = p.GetDeadline(common.Hash{3})
+	require.False(t, ok)
+	_, ok = p.GetDeadline(common.Hash{5})
+	require.False(t, ok)
+	deadline, ok := p.GetDeadline(common.Hash{2})
+	require.True(t, ok)
+	require.Equal(t, uint64(1), deadline.BlockNumber)
+}
+
+func TestDeadlineRestoredFromDB(t *testing.T) {
+	db := memdb.NewTestPoolDB(t)
+
+	p, err := New(nil, nil, txpoolcfg.DefaultConfig, &ethconfig.Defaults, nil, *uint256.NewInt(1), nil, nil, nil)
+	require.NoError(t, err)
+	addPendingTestTx(p, 1, 1, 10)
+	addPendingTestTx(p, 2, 2, 20)
+	p.deadlineByTx[common.Hash{1}] = Deadline{BlockNumber: 1, Timestamp: 1000}
+	p.deadlineByTx[common.Hash{2}] = Deadline{Timestamp: 2000}
+	// not in the pool, so not written
+	p.deadlineByTx[common.Hash{3}] = Deadline{BlockNumber: 1}
+	require.NoError(t, db.Update(context.Background(), p.flushLockedDeadlines))
+
+	// only the deadlines of the transactions that are loaded again are restored
+	restored, err := New(nil, nil, txpoolcfg.DefaultConfig, &ethconfig.Defaults, nil, *uint256.NewInt(1), nil, nil, nil)
+	require.NoError(t, err)
+	addPendingTestTx(restored, 1, 1, 10)
+	require.NoError(t, db.View(context.Background(), restored.fromDBDeadlines))
+
+	deadline, ok := restored.GetDeadline(common.Hash{1})
+	require.True(t, ok)
+	require.Equal(t, Deadline{BlockNumber: 1, Timestamp: 1000}, deadline)
+	_, ok = restored.GetDeadline(common.Hash{2})
+	require.False(t, ok)
+	_, ok = restored.GetDeadline(common.Hash{3})
+	require.False(t, ok)
+}
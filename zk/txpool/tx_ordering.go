@@ -0,0 +1,117 @@
+package txpool
+
+import (
+	"fmt"
+	"sort"
+	"sync/atomic"
+)
+
+const (
+	TxOrderingFeePriority = "fee-priority"
+	TxOrderingFIFO        = "fifo"
+	TxOrderingRoundRobin  = "round-robin"
+)
+
+// arrivalCounter gives every transaction entering the pool a strictly increasing arrival number, the unix
+// timestamp in metaTx.created is too coarse to order transactions arriving in the same second
+var arrivalCounter atomic.Uint64
+
+// TxOrderingPolicy decides the order in which pending transactions are offered to the sequencer
+type TxOrderingPolicy interface {
+	// Order receives the pending transactions sorted by fee priority and returns them in the order they should be
+	// yielded.  Implementations must not modify the input slice.
+	Order(best []*metaTx) []*metaTx
+}
+
+func NewTxOrderingPolicy(name string) (TxOrderingPolicy, error) {
+	switch name {
+	case "", TxOrderingFeePriority:
+		return feePriorityOrdering{}, nil
+	case TxOrderingFIFO:
+		return fifoOrdering{}, nil
+	case TxOrderingRoundRobin:
+		return roundRobinOrdering{}, nil
+	default:
+		return nil, fmt.Errorf("unknown tx ordering policy %q, expected one of %s, %s, %s", name, TxOrderingFeePriority, TxOrderingFIFO, TxOrderingRoundRobin)
+	}
+}
+
+// feePriorityOrdering keeps the order of the pending pool: highest effective tip first
+type feePriorityOrdering struct{}
+
+func (feePriorityOrdering) Order(best []*metaTx) []*metaTx {
+	return best
+}
+
+// fifoOrdering yields transactions in the order they arrived in the pool
+type fifoOrdering struct{}
+
+func (fifoOrdering) Order(best []*metaTx) []*metaTx {
+	ordered := make([]*metaTx, len(best))
+	copy(ordered, best)
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].arrival < ordered[j].arrival
+	})
+
+	// a replacement transaction arrives after the higher nonces of the same sender, those can't be executed before it
+	keepSenderNonceOrder(ordered)
+
+	return ordered
+}
+
+// roundRobinOrdering takes one transaction from each sender in turn so that a single sender with many high fee
+// transactions cannot starve everyone else.  Senders are visited in the order of their best transaction by fee.
+type roundRobinOrdering struct{}
+
+func (roundRobinOrdering) Order(best []*metaTx) []*metaTx {
+	var senders []uint64
+	bySender := make(map[uint64][]*metaTx)
+	for _, mt := range best {
+		if _, ok := bySender[mt.Tx.SenderID]; !ok {
+			senders = append(senders, mt.Tx.SenderID)
+		}
+		bySender[mt.Tx.SenderID] = append(bySender[mt.Tx.SenderID], mt)
+	}
+
+	for _, txs := range bySender {
+		sortByNonce(txs)
+	}
+
+	ordered := make([]*metaTx, 0, len(best))
+	for round := 0; len(ordered) < len(best); round++ {
+		for _, sender := range senders {
+			if txs := bySender[sender]; round < len(txs) {
+				ordered = append(ordered, txs[round])
+			}
+		}
+	}
+
+	return ordered
+}
+
+// keepSenderNonceOrder reorders the transactions of every sender by nonce whilst keeping the positions the sender
+// occupies in the slice, so the order between senders is left as it is
+func keepSenderNonceOrder(ordered []*metaTx) {
+	bySender := make(map[uint64][]*metaTx)
+	for _, mt := range ordered {
+		bySender[mt.Tx.SenderID] = append(bySender[mt.Tx.SenderID], mt)
+	}
+
+	for _, txs := range bySender {
+		sortByNonce(txs)
+	}
+
+	next := make(map[uint64]int, len(bySender))
+	for i, mt := range ordered {
+		sender := mt.Tx.SenderID
+		ordered[i] = bySender[sender][next[sender]]
+		next[sender]++
+	}
+}
+
+func sortByNonce(txs []*metaTx) {
+	sort.SliceStable(txs, func(i, j int) bool {
+		return txs[i].Tx.Nonce < txs[j].Tx.Nonce
+	})
+}
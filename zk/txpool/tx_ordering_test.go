@@ -0,0 +1,77 @@
+package txpool
+
+import (
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newOrderingTestTx(id byte, sender, nonce, arrival uint64) *metaTx {
+	slot := &types.TxSlot{SenderID: sender, Nonce: nonce}
+	slot.IDHash[0] = id
+	return &metaTx{Tx: slot, arrival: arrival}
+}
+
+func orderedIds(txs []*metaTx) []byte {
+	ids := make([]byte, len(txs))
+	for i, mt := range txs {
+		ids[i] = mt.Tx.IDHash[0]
+	}
+	return ids
+}
+
+func TestTxOrderingPolicies(t *testing.T) {
+	// fee ordered as the pending pool would yield them: sender 1 is a high fee bot that sent last
+	best := []*metaTx{
+		newOrderingTestTx(1, 1, 0, 5),
+		newOrderingTestTx(2, 1, 1, 6),
+		newOrderingTestTx(3, 1, 2, 7),
+		newOrderingTestTx(4, 2, 0, 2),
+		newOrderingTestTx(5, 3, 2, 1),
+		newOrderingTestTx(6, 2, 1, 3),
+		newOrderingTestTx(7, 3, 1, 4),
+		// replacement for nonce 0 of sender 3 arriving last, it must still be yielded before the higher nonces
+		newOrderingTestTx(8, 3, 0, 8),
+	}
+
+	scenarios := map[string]struct {
+		policy   string
+		expected []byte
+	}{
+		"fee priority": {
+			policy:   TxOrderingFeePriority,
+			expected: []byte{1, 2, 3, 4, 5, 6, 7, 8},
+		},
+		"default": {
+			policy:   "",
+			expected: []byte{1, 2, 3, 4, 5, 6, 7, 8},
+		},
+		"fifo": {
+			policy:   TxOrderingFIFO,
+			expected: []byte{8, 4, 6, 7, 1, 2, 3, 5},
+		},
+		"round robin": {
+			policy:   TxOrderingRoundRobin,
+			expected: []byte{1, 4, 8, 2, 6, 7, 3, 5},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			input := make([]*metaTx, len(best))
+			copy(input, best)
+
+			policy, err := NewTxOrderingPolicy(scenario.policy)
+			require.NoError(t, err)
+
+			require.Equal(t, scenario.expected, orderedIds(policy.Order(input)))
+			require.Equal(t, best, input, "input must not be modified")
+		})
+	}
+}
+
+func TestNewTxOrderingPolicyUnknown(t *testing.T) {
+	_, err := NewTxOrderingPolicy("lifo")
+	require.Error(t, err)
+}
@@ -162,6 +162,78 @@ func (ib *L1InjectedBatch) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// L1ForcedBatch is a batch of transactions forced onto the L2 by a user through the ForceBatch function of the
+// rollup contract on the L1
+type L1ForcedBatch struct {
+	ForcedBatchNumber  uint64
+	L1BlockNumber      uint64
+	L1TxHash           common.Hash
+	ForcedAt           uint64
+	LastGlobalExitRoot common.Hash
+	ForcedBlockHashL1  common.Hash // parent hash of the L1 block the batch was forced in
+	Sequencer          common.Address
+	Transactions       []byte
+}
+
+func (fb *L1ForcedBatch) Marshall() []byte {
+	result := make([]byte, 0, 8+8+32+8+32+32+20+len(fb.Transactions))
+	result = append(result, utils.Uint64ToLE(fb.ForcedBatchNumber)...)
+	result = append(result, utils.Uint64ToLE(fb.L1BlockNumber)...)
+	result = append(result, fb.L1TxHash[:]...)
+	result = append(result, utils.Uint64ToLE(fb.ForcedAt)...)
+	result = append(result, fb.LastGlobalExitRoot[:]...)
+	result = append(result, fb.ForcedBlockHashL1[:]...)
+	result = append(result, fb.Sequencer[:]...)
+	result = append(result, fb.Transactions...)
+	return result
+}
+
+func (fb *L1ForcedBatch) Unmarshall(input []byte) error {
+	if len(input) < 140 {
+		return fmt.Errorf("unmarshall error, input is too short")
+	}
+	fb.ForcedBatchNumber = binary.LittleEndian.Uint64(input[:8])
+	fb.L1BlockNumber = binary.LittleEndian.Uint64(input[8:16])
+	copy(fb.L1TxHash[:], input[16:48])
+	fb.ForcedAt = binary.LittleEndian.Uint64(input[48:56])
+	copy(fb.LastGlobalExitRoot[:], input[56:88])
+	copy(fb.ForcedBlockHashL1[:], input[88:120])
+	copy(fb.Sequencer[:], input[120:140])
+	fb.Transactions = append([]byte{}, input[140:]...)
+	return nil
+}
+
+// BatchCloseInfo records why and how full the sequencer closed a batch
+type BatchCloseInfo struct {
+	Reason string
+	// names of the counters that overflowed, comma separated, when the batch was closed on an overflow
+	OverflowCounters string
+	BlockCount       uint64
+	TransactionCount uint64
+}
+
+func (ci *BatchCloseInfo) Marshall() []byte {
+	result := make([]byte, 0, 8+8+1+len(ci.Reason)+len(ci.OverflowCounters))
+	result = append(result, utils.Uint64ToLE(ci.BlockCount)...)
+	result = append(result, utils.Uint64ToLE(ci.TransactionCount)...)
+	result = append(result, byte(len(ci.Reason)))
+	result = append(result, ci.Reason...)
+	result = append(result, ci.OverflowCounters...)
+	return result
+}
+
+func (ci *BatchCloseInfo) Unmarshall(input []byte) error {
+	if len(input) < 17 || len(input) < 17+int(input[16]) {
+		return fmt.Errorf("unmarshall error, input is too short")
+	}
+	reasonEnd := 17 + int(input[16])
+	ci.BlockCount = binary.LittleEndian.Uint64(input[:8])
+	ci.TransactionCount = binary.LittleEndian.Uint64(input[8:16])
+	ci.Reason = string(input[17:reasonEnd])
+	ci.OverflowCounters = string(input[reasonEnd:])
+	return nil
+}
+
 type ForkInterval struct {
 	ForkID          uint64
 	FromBatchNumber uint64
@@ -55,6 +55,48 @@ func Test_L1InjectedBatchMarshallUnmarshall(t *testing.T) {
 	require.Equal(t, input, result)
 }
 
+func Test_L1ForcedBatchMarshallUnmarshall(t *testing.T) {
+	input := &L1ForcedBatch{
+		ForcedBatchNumber:  5,
+		L1BlockNumber:      100,
+		L1TxHash:           libcommon.HexToHash("0x1"),
+		ForcedAt:           1000,
+		LastGlobalExitRoot: libcommon.HexToHash("0x2"),
+		ForcedBlockHashL1:  libcommon.HexToHash("0x4"),
+		Sequencer:          libcommon.HexToAddress("0x3"),
+		Transactions:       []byte{1, 2, 3},
+	}
+
+	marshalled := input.Marshall()
+
+	result := &L1ForcedBatch{}
+	err := result.Unmarshall(marshalled)
+	require.NoError(t, err)
+	require.Equal(t, input, result)
+
+	err = result.Unmarshall(marshalled[:130])
+	require.Error(t, err)
+}
+
+func Test_BatchCloseInfoMarshallUnmarshall(t *testing.T) {
+	input := &BatchCloseInfo{
+		Reason:           "BatchCounterOverflow",
+		OverflowCounters: "S,K",
+		BlockCount:       3,
+		TransactionCount: 42,
+	}
+
+	marshalled := input.Marshall()
+
+	result := &BatchCloseInfo{}
+	err := result.Unmarshall(marshalled)
+	require.NoError(t, err)
+	require.Equal(t, input, result)
+
+	err = result.Unmarshall(marshalled[:20])
+	require.Error(t, err)
+}
+
 func Test_L1InjectedBatch_UnmarshalJSON(t *testing.T) {
 	cases := []struct {
 		name                  string